@@ -0,0 +1,569 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+	"time"
+)
+
+const (
+	ACCORDION_TITLE_HEIGHT      = 28
+	ACCORDION_TITLE_PADDING     = 8
+	ACCORDION_ARROW_SIZE        = 4
+	ACCORDION_DURATION          = 200 * time.Millisecond
+	ACCORDION_TITLE_COLOR       = "#E8E8E8"
+	ACCORDION_TITLE_HOVER_COLOR = "#DCDCDC"
+)
+
+// accordionTitle is the title bar of an AccordionItem. Clicking it, or
+// pressing Space or Enter while it has the focus, toggles the section.
+type accordionTitle struct {
+	*Widget
+	item *AccordionItem
+}
+
+func (title *accordionTitle) onPointerDown(point *structs.Point) {
+	if window := title.GetWindow(); window != nil && title.enable {
+		window.SetFocus(title.Widget)
+	}
+
+	return
+}
+
+func (title *accordionTitle) onPointerUp(point *structs.Point) {
+	if title.enable && title.isClicked() {
+		title.item.Toggle()
+	}
+
+	return
+}
+
+// onKeyDown toggles the section, or moves the focus to the title above or
+// below.
+func (title *accordionTitle) onKeyDown(code int) {
+	item := title.item
+	accordion := item.accordion
+
+	if title.enable {
+		switch code {
+		case keyevent.DOM_VK_SPACE, keyevent.DOM_VK_RETURN:
+			item.Toggle()
+		case keyevent.DOM_VK_LEFT:
+			item.SetExpanded(false)
+		case keyevent.DOM_VK_RIGHT:
+			item.SetExpanded(true)
+		case keyevent.DOM_VK_UP:
+			accordion.focusItem(accordion.indexOf(item) - 1)
+		case keyevent.DOM_VK_DOWN:
+			accordion.focusItem(accordion.indexOf(item) + 1)
+		case keyevent.DOM_VK_HOME:
+			accordion.focusItem(0)
+		case keyevent.DOM_VK_END:
+			accordion.focusItem(len(accordion.items) - 1)
+		}
+	}
+	title.Widget.onKeyDown(code)
+
+	return
+}
+
+func (title *accordionTitle) paintBackground(context canvas.Canvas2D) {
+	style := title.getStyle("")
+	w := float64(title.rect.W)
+	h := float64(title.rect.H)
+
+	if title.state == STATE_OVER || title.state == STATE_ACTIVE {
+		context.SetFillStyle(ACCORDION_TITLE_HOVER_COLOR)
+	} else {
+		context.SetFillStyle(colorOr(style.FillColor, ACCORDION_TITLE_COLOR))
+	}
+	context.FillRect(0, 0, w, h)
+
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.MoveTo(0, h-0.5)
+	context.LineTo(w, h-0.5)
+	context.Stroke()
+
+	if title.editing {
+		context.SetStrokeStyle(SLIDER_FILL_COLOR)
+		context.BeginPath()
+		context.Rect(1.5, 1.5, w-3, h-4)
+		context.Stroke()
+	}
+
+	return
+}
+
+// paintSelf draws the arrow, turning from right to down as the section
+// opens, and the title text.
+func (title *accordionTitle) paintSelf(context canvas.Canvas2D) {
+	style := title.getStyle("")
+	w := float64(title.rect.W)
+	h := float64(title.rect.H)
+
+	angle := title.item.getOpenFraction() * math.Pi / 2
+	cos := math.Cos(angle)
+	sin := math.Sin(angle)
+	cx := float64(ACCORDION_TITLE_PADDING + ACCORDION_ARROW_SIZE)
+	cy := h / 2
+
+	context.SetFillStyle(style.TextColor)
+	context.BeginPath()
+	for i, p := range [][2]float64{{-2, -4}, {3, 0}, {-2, 4}} {
+		x := cx + (p[0]*cos-p[1]*sin)*ACCORDION_ARROW_SIZE/4
+		y := cy + (p[0]*sin+p[1]*cos)*ACCORDION_ARROW_SIZE/4
+		if i == 0 {
+			context.MoveTo(x, y)
+		} else {
+			context.LineTo(x, y)
+		}
+	}
+	context.ClosePath()
+	context.Fill()
+
+	left := float64(2*ACCORDION_TITLE_PADDING + 2*ACCORDION_ARROW_SIZE)
+	context.SetFont(style.Font)
+	context.SetTextAlign("left")
+	context.SetTextBaseline("middle")
+	context.FillText(title.GetText(), left, h/2, w-left-ACCORDION_TITLE_PADDING)
+
+	return
+}
+
+// AccordionItem is a section of an Accordion: a title bar and, below it, a
+// content pane of a fixed height that slides open and closed.
+type AccordionItem struct {
+	*Widget
+	accordion     *Accordion
+	title         *accordionTitle
+	content       *Widget
+	contentHeight int
+	expanded      bool
+	height        float64
+	fromHeight    float64
+	startTime     time.Time
+}
+
+// GetContent returns the widget the content of the section should be added
+// to.
+func (item *AccordionItem) GetContent() *Widget {
+	return item.content
+}
+
+func (item *AccordionItem) GetAccordion() *Accordion {
+	return item.accordion
+}
+
+// GetIndex returns the position of the section, or -1 once removed.
+func (item *AccordionItem) GetIndex() int {
+	return item.accordion.indexOf(item)
+}
+
+func (item *AccordionItem) GetTitle() string {
+	return item.title.GetText()
+}
+
+func (item *AccordionItem) SetTitle(title string) *AccordionItem {
+	item.title.SetText(title, false)
+	item.PostRedraw()
+
+	return item
+}
+
+// SetContentHeight sets the height of the content pane when the section is
+// open.
+func (item *AccordionItem) SetContentHeight(h int) *AccordionItem {
+	item.contentHeight = int(math.Max(0, float64(h)))
+	if item.expanded {
+		item.height = float64(item.contentHeight)
+	}
+	item.accordion.layout()
+
+	return item
+}
+
+func (item *AccordionItem) GetContentHeight() int {
+	return item.contentHeight
+}
+
+func (item *AccordionItem) IsExpanded() bool {
+	return item.expanded
+}
+
+// SetExpanded opens or closes the section. In single-open mode opening it
+// closes the others.
+func (item *AccordionItem) SetExpanded(expanded bool) *AccordionItem {
+	item.accordion.setExpanded(item, expanded, item.accordion.animated, true)
+
+	return item
+}
+
+func (item *AccordionItem) Toggle() *AccordionItem {
+	return item.SetExpanded(!item.expanded)
+}
+
+// getStateKey returns the key the section is saved under: its name, or its
+// title when it has none.
+func (item *AccordionItem) getStateKey() string {
+	key := item.GetName()
+	if len(key) == 0 {
+		key = item.GetTitle()
+	}
+
+	return item.accordion.stateKey + "/" + key
+}
+
+// getOpenFraction returns how far the section is open, from 0 to 1.
+func (item *AccordionItem) getOpenFraction() float64 {
+	if item.contentHeight <= 0 {
+		if item.expanded {
+			return 1
+		}
+		return 0
+	}
+
+	return item.height / float64(item.contentHeight)
+}
+
+// step moves the shown height of the content towards its target and reports
+// whether it is still moving.
+func (item *AccordionItem) step() bool {
+	target := 0.0
+	if item.expanded {
+		target = float64(item.contentHeight)
+	}
+	if item.height == target {
+		return false
+	}
+
+	t := float64(time.Since(item.startTime)) / float64(ACCORDION_DURATION)
+	if t >= 1 {
+		item.height = target
+		return false
+	}
+
+	t = t * (2 - t)
+	item.height = item.fromHeight + (target-item.fromHeight)*t
+
+	return true
+}
+
+// paintChildren clips the content to the part of the section shown.
+func (item *AccordionItem) paintChildren(context canvas.Canvas2D) {
+	context.Save()
+	context.BeginPath()
+	context.Rect(0, 0, float64(item.rect.W), float64(item.rect.H))
+	context.Clip()
+	item.Widget.paintChildren(context)
+	context.Restore()
+
+	return
+}
+
+func (item *AccordionItem) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+// Accordion stacks collapsible sections. In single-open mode, the default,
+// opening a section closes the one open before; in multi-open mode any
+// number may be open. Sections slide open and closed unless animation is
+// turned off, and with a state key they are reopened as they were left the
+// next time the accordion is built. The changed handler receives the
+// *AccordionItem opened or closed.
+type Accordion struct {
+	*Widget
+	items     []*AccordionItem
+	multiOpen bool
+	animated  bool
+	stateKey  string
+}
+
+func NewAccordion(parent *Widget, x, y, w, h float32) *Accordion {
+	accordion := &Accordion{
+		Widget:   NewWidget(TYPE_ACCORDION, parent, x, y, w, h),
+		animated: true,
+	}
+	accordion.I = accordion
+
+	return accordion
+}
+
+// AddItem appends a closed section, unless the state saved for it says it
+// was left open, and returns it.
+func (accordion *Accordion) AddItem(title string, contentHeight int) *AccordionItem {
+	item := accordion.newItem(title, contentHeight)
+	accordion.restoreItem(item)
+	accordion.layout()
+
+	return item
+}
+
+// newItem appends a closed section, leaving it to the caller to restore its
+// state and lay the sections out.
+func (accordion *Accordion) newItem(title string, contentHeight int) *AccordionItem {
+	w := float32(accordion.rect.W)
+	item := &AccordionItem{
+		Widget:        NewWidget(TYPE_ACCORDION_ITEM, accordion.Widget, 0, 0, w, ACCORDION_TITLE_HEIGHT),
+		accordion:     accordion,
+		contentHeight: int(math.Max(0, float64(contentHeight))),
+	}
+	item.I = item
+
+	item.title = &accordionTitle{
+		Widget: NewWidget(TYPE_ACCORDION_TITLE, item.Widget, 0, 0, w, ACCORDION_TITLE_HEIGHT),
+		item:   item,
+	}
+	item.title.SetText(title, false)
+	item.title.I = item.title
+
+	item.content = NewWidget(TYPE_VIEW_BASE, item.Widget, 0, ACCORDION_TITLE_HEIGHT, w, float32(item.contentHeight))
+	item.content.SetVisible(false)
+
+	accordion.items = append(accordion.items, item)
+
+	return item
+}
+
+// RemoveItem removes item and its content.
+func (accordion *Accordion) RemoveItem(item *AccordionItem) *Accordion {
+	index := accordion.indexOf(item)
+	if index < 0 {
+		return accordion
+	}
+
+	accordion.items = append(accordion.items[:index], accordion.items[index+1:]...)
+	if window := accordion.GetWindow(); window != nil {
+		for w := window.GetFocus(); w != nil; w = w.parent {
+			if w == item.Widget {
+				window.SetFocus(nil)
+				break
+			}
+		}
+	}
+	item.Remove()
+	accordion.layout()
+	accordion.PostRedraw()
+
+	return accordion
+}
+
+func (accordion *Accordion) GetItems() []*AccordionItem {
+	return accordion.items
+}
+
+func (accordion *Accordion) GetItemCount() int {
+	return len(accordion.items)
+}
+
+func (accordion *Accordion) GetItem(index int) *AccordionItem {
+	if index < 0 || index >= len(accordion.items) {
+		return nil
+	}
+
+	return accordion.items[index]
+}
+
+// SetMultiOpen lets several sections be open at once. Turning it off keeps
+// only the first open section open.
+func (accordion *Accordion) SetMultiOpen(multiOpen bool) *Accordion {
+	accordion.multiOpen = multiOpen
+	if !multiOpen {
+		for _, item := range accordion.items {
+			if item.expanded {
+				accordion.setExpanded(item, true, false, true)
+				break
+			}
+		}
+	}
+
+	return accordion
+}
+
+func (accordion *Accordion) IsMultiOpen() bool {
+	return accordion.multiOpen
+}
+
+// SetAnimated makes sections slide open and closed, it is on by default.
+func (accordion *Accordion) SetAnimated(animated bool) *Accordion {
+	accordion.animated = animated
+
+	return accordion
+}
+
+func (accordion *Accordion) IsAnimated() bool {
+	return accordion.animated
+}
+
+// SetStateKey saves which sections are open under key, through
+// WindowManager.SaveState, and reopens the sections as they were saved.
+// Sections are told apart by their name, or their title when they have no
+// name, so the key should be unique to the accordion.
+func (accordion *Accordion) SetStateKey(key string) *Accordion {
+	accordion.stateKey = key
+	for _, item := range accordion.items {
+		accordion.restoreItem(item)
+	}
+	accordion.layout()
+
+	return accordion
+}
+
+func (accordion *Accordion) GetStateKey() string {
+	return accordion.stateKey
+}
+
+// Resize resizes the accordion and keeps the sections fitted to its width.
+func (accordion *Accordion) Resize(w, h int) *Accordion {
+	accordion.Widget.Resize(w, h)
+	accordion.layout()
+
+	return accordion
+}
+
+func (accordion *Accordion) indexOf(item *AccordionItem) int {
+	for i, iter := range accordion.items {
+		if iter == item {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (accordion *Accordion) focusItem(index int) {
+	item := accordion.GetItem(index)
+	if window := accordion.GetWindow(); item != nil && window != nil {
+		window.SetFocus(item.title.Widget)
+	}
+
+	return
+}
+
+// setExpanded opens or closes item, sliding it when animate is set, and in
+// single-open mode closes the other sections when item opens.
+func (accordion *Accordion) setExpanded(item *AccordionItem, expanded, animate, notify bool) {
+	if expanded && !accordion.multiOpen {
+		for _, iter := range accordion.items {
+			if iter != item && iter.expanded {
+				accordion.setExpanded(iter, false, animate, notify)
+			}
+		}
+	}
+
+	if item.expanded == expanded {
+		return
+	}
+
+	item.expanded = expanded
+	item.fromHeight = item.height
+	item.startTime = time.Now()
+	if !animate {
+		item.height = float64(item.contentHeight)
+		if !expanded {
+			item.height = 0
+		}
+	}
+
+	if !expanded {
+		if window := accordion.GetWindow(); window != nil {
+			for w := window.GetFocus(); w != nil; w = w.parent {
+				if w == item.content {
+					window.SetFocus(item.title.Widget)
+					break
+				}
+			}
+		}
+	}
+
+	accordion.saveItem(item)
+	accordion.layout()
+	if notify && accordion.onChanged != nil {
+		accordion.onChanged(item)
+	}
+	accordion.PostRedraw()
+
+	return
+}
+
+// restoreItem opens or closes item as saved under the state key, without
+// sliding it, or saves it as it is when nothing was saved for it yet. It
+// reports whether a saved state was found.
+func (accordion *Accordion) restoreItem(item *AccordionItem) bool {
+	if len(accordion.stateKey) == 0 {
+		return false
+	}
+
+	value, ok := GetWindowManagerInstance().LoadState(item.getStateKey())
+	if ok {
+		accordion.setExpanded(item, value == "1", false, false)
+	} else {
+		accordion.saveItem(item)
+	}
+
+	return ok
+}
+
+func (accordion *Accordion) saveItem(item *AccordionItem) {
+	if len(accordion.stateKey) == 0 {
+		return
+	}
+
+	value := "0"
+	if item.expanded {
+		value = "1"
+	}
+	GetWindowManagerInstance().SaveState(item.getStateKey(), value)
+
+	return
+}
+
+// layout stacks the sections at the heights they are shown at and reports
+// whether one of them is still sliding.
+func (accordion *Accordion) layout() bool {
+	animating := false
+	w := accordion.rect.W
+	y := 0
+
+	for _, item := range accordion.items {
+		if item.step() {
+			animating = true
+		}
+
+		h := int(math.Round(item.height))
+		item.Move(0, y)
+		item.Resize(w, ACCORDION_TITLE_HEIGHT+h)
+		item.title.Move(0, 0)
+		item.title.Resize(w, ACCORDION_TITLE_HEIGHT)
+		item.content.Move(0, ACCORDION_TITLE_HEIGHT)
+		item.content.Resize(w, item.contentHeight)
+		item.content.SetVisible(h > 0)
+		y += ACCORDION_TITLE_HEIGHT + h
+	}
+
+	return animating
+}
+
+func (accordion *Accordion) relayout(context canvas.Canvas2D, force bool) {
+	if accordion.layout() {
+		GetWindowManagerInstance().keepAnimating()
+	}
+	accordion.Widget.relayout(context, force)
+
+	return
+}
+
+// paintChildren clips the sections to the accordion.
+func (accordion *Accordion) paintChildren(context canvas.Canvas2D) {
+	context.Save()
+	context.BeginPath()
+	context.Rect(0, 0, float64(accordion.rect.W), float64(accordion.rect.H))
+	context.Clip()
+	accordion.Widget.paintChildren(context)
+	context.Restore()
+
+	return
+}
@@ -0,0 +1,165 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"reflect"
+	"testing"
+)
+
+// newAccordion returns an Accordion 200x300 without animation, holding the
+// sections "one", "two" and "three" 50 high, saved under key unless empty.
+func newAccordion(parent *Widget, key string) *Accordion {
+	accordion := NewAccordion(parent, 0, 0, 200, 300).SetAnimated(false).SetStateKey(key)
+	for _, title := range []string{"one", "two", "three"} {
+		accordion.AddItem(title, 50)
+	}
+
+	return accordion
+}
+
+// getExpandedTitles returns the titles of the open sections.
+func getExpandedTitles(accordion *Accordion) []string {
+	titles := []string{}
+	for _, item := range accordion.GetItems() {
+		if item.IsExpanded() {
+			titles = append(titles, item.GetTitle())
+		}
+	}
+
+	return titles
+}
+
+// clickTitle clicks the title of section index.
+func clickTitle(k typist, accordion *Accordion, index int) {
+	k.click(50, accordion.GetItem(index).rect.Y+ACCORDION_TITLE_HEIGHT/2)
+
+	return
+}
+
+func TestAccordionSingleOpen(t *testing.T) {
+	var accordion *Accordion
+	k := newScene(300, 400, func(win *Window) {
+		accordion = newAccordion(win.Widget, "")
+	})
+	changed := []string{}
+	accordion.SetChangedHandler(func(value interface{}) {
+		item := value.(*AccordionItem)
+		if item.IsExpanded() {
+			changed = append(changed, "+"+item.GetTitle())
+		} else {
+			changed = append(changed, "-"+item.GetTitle())
+		}
+	})
+
+	steps := []struct {
+		index    int
+		expanded []string
+		changed  []string
+	}{
+		{0, []string{"one"}, []string{"+one"}},
+		{1, []string{"two"}, []string{"-one", "+two"}},
+		{1, []string{}, []string{"-two"}},
+		{2, []string{"three"}, []string{"+three"}},
+	}
+	for i, step := range steps {
+		changed = changed[:0]
+		clickTitle(k, accordion, step.index)
+		if titles := getExpandedTitles(accordion); !reflect.DeepEqual(titles, step.expanded) {
+			t.Fatalf("click %d: open sections %v, want %v", i, titles, step.expanded)
+		}
+		if !reflect.DeepEqual(changed, step.changed) {
+			t.Fatalf("click %d: changes %v, want %v", i, changed, step.changed)
+		}
+	}
+
+	// Sections below an open one move down by its content.
+	if y := accordion.GetItem(2).rect.Y; y != 2*ACCORDION_TITLE_HEIGHT {
+		t.Fatalf("third section at %d, want %d", y, 2*ACCORDION_TITLE_HEIGHT)
+	}
+	clickTitle(k, accordion, 0)
+	if y := accordion.GetItem(2).rect.Y; y != 2*ACCORDION_TITLE_HEIGHT+50 {
+		t.Fatalf("third section at %d below the open first one, want %d", y, 2*ACCORDION_TITLE_HEIGHT+50)
+	}
+
+	// The focused title toggles with Space and Enter and the arrows move the
+	// focus.
+	k.key(keyevent.DOM_VK_SPACE)
+	if titles := getExpandedTitles(accordion); len(titles) != 0 {
+		t.Fatalf("Space left %v open", titles)
+	}
+	k.key(keyevent.DOM_VK_DOWN, keyevent.DOM_VK_RETURN)
+	if titles := getExpandedTitles(accordion); !reflect.DeepEqual(titles, []string{"two"}) {
+		t.Fatalf("Down and Enter opened %v, want [two]", titles)
+	}
+}
+
+func TestAccordionMultiOpen(t *testing.T) {
+	var accordion *Accordion
+	k := newScene(300, 400, func(win *Window) {
+		accordion = newAccordion(win.Widget, "").SetMultiOpen(true)
+	})
+
+	clickTitle(k, accordion, 0)
+	clickTitle(k, accordion, 2)
+	clickTitle(k, accordion, 1)
+	if titles := getExpandedTitles(accordion); !reflect.DeepEqual(titles, []string{"one", "two", "three"}) {
+		t.Fatalf("open sections %v, want all of them", titles)
+	}
+
+	clickTitle(k, accordion, 0)
+	if titles := getExpandedTitles(accordion); !reflect.DeepEqual(titles, []string{"two", "three"}) {
+		t.Fatalf("open sections %v after closing one", titles)
+	}
+
+	// Back in single-open mode only the first open section stays open.
+	accordion.SetMultiOpen(false)
+	if titles := getExpandedTitles(accordion); !reflect.DeepEqual(titles, []string{"two"}) {
+		t.Fatalf("open sections %v after leaving multi-open mode, want [two]", titles)
+	}
+	clickTitle(k, accordion, 0)
+	if titles := getExpandedTitles(accordion); !reflect.DeepEqual(titles, []string{"one"}) {
+		t.Fatalf("open sections %v, want [one]", titles)
+	}
+}
+
+func TestAccordionStateKey(t *testing.T) {
+	var accordion *Accordion
+	k := newScene(300, 400, func(win *Window) {
+		accordion = newAccordion(win.Widget, "panel").SetMultiOpen(true)
+	})
+	accordion.GetItem(2).SetName("last")
+
+	clickTitle(k, accordion, 1)
+	clickTitle(k, accordion, 2)
+	for key, want := range map[string]string{"panel/one": "0", "panel/two": "1", "panel/last": "1"} {
+		if value, ok := k.m.LoadState(key); !ok || value != want {
+			t.Fatalf("state %q is %q, want %q", key, value, want)
+		}
+	}
+
+	// An accordion built again with the key reopens the sections saved open,
+	// whether the key is set before or after adding them. Here the third
+	// section is looked up by its title, as it has no name yet.
+	again := newAccordion(NewWindow(k.m, 0, 0, 300, 400).Widget, "panel")
+	if titles := getExpandedTitles(again); !reflect.DeepEqual(titles, []string{"two"}) {
+		t.Fatalf("open sections %v restored, want [two]", titles)
+	}
+
+	again = NewAccordion(NewWindow(k.m, 0, 0, 300, 400).Widget, 0, 0, 200, 300).SetMultiOpen(true)
+	for _, title := range []string{"one", "two", "three"} {
+		again.AddItem(title, 50)
+	}
+	again.GetItem(2).SetName("last")
+	again.SetStateKey("panel")
+	if titles := getExpandedTitles(again); !reflect.DeepEqual(titles, []string{"two", "three"}) {
+		t.Fatalf("open sections %v restored, want [two three]", titles)
+	}
+	if again.GetItem(1).rect.H != ACCORDION_TITLE_HEIGHT+50 {
+		t.Fatalf("restored section %d high, want it open at once", again.GetItem(1).rect.H)
+	}
+
+	// Without a key nothing is restored.
+	if titles := getExpandedTitles(newAccordion(NewWindow(k.m, 0, 0, 300, 400).Widget, "")); len(titles) != 0 {
+		t.Fatalf("open sections %v without a state key", titles)
+	}
+}
@@ -0,0 +1,114 @@
+package gwk
+
+import (
+	"fmt"
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/rt"
+	"github.com/gopherjs/gopherjs/js"
+	"honnef.co/go/js/dom"
+	"time"
+)
+
+// Backend supplies the drawing surface and the host services a
+// WindowManager needs, so the toolkit can run inside or outside a browser.
+type Backend interface {
+	GetCanvas2D() canvas.Canvas2D
+	GetSize() (w, h int)
+	GetViewPort() (w, h int)
+	RequestAnimationFrame(onFrame func())
+	SetCursor(cursor string)
+}
+
+// StateStore is implemented by the backends that can keep small named
+// strings, like the sections an Accordion left open, across sessions.
+type StateStore interface {
+	LoadState(key string) (string, bool)
+	SaveState(key, value string)
+}
+
+// URLOpener is implemented by the backends that can show a URL to the user,
+// like a browser opening it in a new tab.
+type URLOpener interface {
+	OpenURL(url string)
+}
+
+type domBackend struct {
+	canvas dom.HTMLCanvasElement
+	ctx    canvas.Canvas2D
+}
+
+func newDOMBackend(element dom.HTMLCanvasElement) *domBackend {
+	return &domBackend{canvas: element}
+}
+
+func (backend *domBackend) GetCanvas2D() canvas.Canvas2D {
+	if backend.ctx == nil {
+		backend.ctx = canvas.NewDOMCanvas(backend.canvas.GetContext2d())
+	}
+
+	return backend.ctx
+}
+
+func (backend *domBackend) GetSize() (w, h int) {
+	return backend.canvas.Width, backend.canvas.Height
+}
+
+func (backend *domBackend) GetViewPort() (w, h int) {
+	return rt.GetRTInstance().GetViewPort()
+}
+
+func (backend *domBackend) RequestAnimationFrame(onFrame func()) {
+	dom.GetWindow().RequestAnimationFrame(func(d time.Duration) {
+		fmt.Printf("RequestAnimationFrame %v\n", d)
+		onFrame()
+	})
+}
+
+func (backend *domBackend) SetCursor(cursor string) {
+	style := backend.canvas.Style()
+	if style.GetPropertyValue("cursor") != cursor {
+		style.SetProperty("cursor", cursor, "")
+	}
+}
+
+// LoadState reads key from the localStorage of the page. Pages without one,
+// or refusing access to it, have no state.
+func (backend *domBackend) LoadState(key string) (value string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			value, ok = "", false
+		}
+	}()
+
+	storage := js.Global.Get("localStorage")
+	if storage == nil || storage == js.Undefined {
+		return "", false
+	}
+
+	item := storage.Call("getItem", key)
+	if item == nil || item == js.Undefined {
+		return "", false
+	}
+
+	return item.String(), true
+}
+
+func (backend *domBackend) SaveState(key, value string) {
+	defer func() {
+		recover()
+	}()
+
+	storage := js.Global.Get("localStorage")
+	if storage != nil && storage != js.Undefined {
+		storage.Call("setItem", key, value)
+	}
+
+	return
+}
+
+// OpenURL opens url in a new tab of the browser.
+func (backend *domBackend) OpenURL(url string) {
+	dom.GetWindow().Open(url, "_blank", "")
+
+	return
+}
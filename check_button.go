@@ -0,0 +1,183 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/image"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"github.com/Luncher/gwk/pkg/theme"
+	"math"
+)
+
+type CheckState int
+
+const (
+	CHECK_STATE_UNCHECKED CheckState = iota
+	CHECK_STATE_CHECKED
+	CHECK_STATE_INDETERMINATE
+)
+
+// CheckButton is an ImageText with a check box in front of the text. The
+// box uses the CheckedImage/UncheckedImage of the theme and falls back to a
+// drawn box. The changed handler receives the new CheckState.
+type CheckButton struct {
+	*ImageText
+	checkState CheckState
+	triState   bool
+}
+
+func NewCheckButton(parent *Widget, x, y, w, h float32) *CheckButton {
+	button := &CheckButton{
+		ImageText: NewImageText(parent, x, y, w, h),
+	}
+	button.t = TYPE_CHECK_BUTTON
+	button.checkable = true
+	button.setChecked = func(checked, notify bool) *Widget {
+		return button.SetChecked(checked, notify).Widget
+	}
+	button.I = button
+
+	return button
+}
+
+// SetTriState lets clicks cycle through the indeterminate state as well.
+func (button *CheckButton) SetTriState(triState bool) *CheckButton {
+	button.triState = triState
+
+	return button
+}
+
+func (button *CheckButton) IsTriState() bool {
+	return button.triState
+}
+
+func (button *CheckButton) SetChecked(checked, notify bool) *CheckButton {
+	if checked {
+		return button.SetCheckState(CHECK_STATE_CHECKED, notify)
+	}
+
+	return button.SetCheckState(CHECK_STATE_UNCHECKED, notify)
+}
+
+func (button *CheckButton) IsChecked() bool {
+	return button.checkState == CHECK_STATE_CHECKED
+}
+
+func (button *CheckButton) SetCheckState(checkState CheckState, notify bool) *CheckButton {
+	if button.checkState == checkState {
+		return button
+	}
+
+	button.checkState = checkState
+	if notify && button.onChanged != nil {
+		button.onChanged(checkState)
+	}
+	button.PostRedraw()
+
+	return button
+}
+
+func (button *CheckButton) GetCheckState() CheckState {
+	return button.checkState
+}
+
+// Toggle moves to the next state: unchecked, checked and, for tri-state
+// buttons, indeterminate.
+func (button *CheckButton) Toggle(notify bool) *CheckButton {
+	switch button.checkState {
+	case CHECK_STATE_UNCHECKED:
+		return button.SetCheckState(CHECK_STATE_CHECKED, notify)
+	case CHECK_STATE_CHECKED:
+		if button.triState {
+			return button.SetCheckState(CHECK_STATE_INDETERMINATE, notify)
+		}
+	}
+
+	return button.SetCheckState(CHECK_STATE_UNCHECKED, notify)
+}
+
+func (button *CheckButton) onPointerDown(point *structs.Point) {
+	if window := button.GetWindow(); window != nil && button.enable {
+		window.SetFocus(button.Widget)
+	}
+	button.Widget.onPointerDown(point)
+
+	return
+}
+
+func (button *CheckButton) onPointerUp(point *structs.Point) {
+	if button.enable && button.isClicked() {
+		button.Toggle(true)
+	}
+	button.Widget.onPointerUp(point)
+
+	return
+}
+
+func (button *CheckButton) onKeyDown(code int) {
+	if button.enable && code == keyevent.DOM_VK_SPACE {
+		button.Toggle(true)
+	}
+	button.Widget.onKeyDown(code)
+
+	return
+}
+
+func (button *CheckButton) paintSelf(context canvas.Canvas2D) {
+	style := button.getStyle("")
+	size := button.paintIndicatorText(context, style)
+	x := float64(button.border)
+	y := float64(button.rect.H-size) / 2
+	s := float64(size)
+
+	var img *image.Image
+	if button.checkState == CHECK_STATE_CHECKED {
+		img = style.CheckedImage
+	} else {
+		img = style.UncheckedImage
+	}
+
+	if img != nil {
+		img.Draw(context, image.DISPLAY_AUTO_SIZE_DOWN, int(x), int(y), size, size)
+	} else {
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(style.TextColor)
+		context.BeginPath()
+		context.Rect(x+0.5, y+0.5, s-1, s-1)
+		context.Stroke()
+	}
+
+	context.SetFillStyle(style.TextColor)
+	context.SetStrokeStyle(style.TextColor)
+	switch {
+	case button.checkState == CHECK_STATE_INDETERMINATE:
+		context.FillRect(x+s/4, y+s/2-1, s/2, 2)
+	case button.checkState == CHECK_STATE_CHECKED && img == nil:
+		context.SetLineWidth(2)
+		context.BeginPath()
+		context.MoveTo(x+s*0.2, y+s*0.5)
+		context.LineTo(x+s*0.42, y+s*0.72)
+		context.LineTo(x+s*0.8, y+s*0.28)
+		context.Stroke()
+	}
+
+	return
+}
+
+// paintIndicatorText draws the text of a check or radio button after the
+// indicator and returns the indicator size.
+func (imageText *ImageText) paintIndicatorText(context canvas.Canvas2D, style *theme.ThemeStyle) int {
+	rect := imageText.rect
+	size := int(math.Min(16, float64(rect.H-2*imageText.border)))
+
+	if text := imageText.GetText(); len(text) > 0 {
+		x := imageText.border + size + (imageText.spacer >> 1)
+		context.SetFont(style.Font)
+		context.SetFillStyle(style.TextColor)
+		context.SetTextAlign("left")
+		context.SetTextBaseline("middle")
+		context.FillText(text, float64(x), float64(rect.H>>1), float64(rect.W-x))
+	}
+
+	return size
+}
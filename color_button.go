@@ -0,0 +1,267 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"strings"
+)
+
+const (
+	COLOR_BUTTON_PADDING = 4
+	COLOR_BUTTON_COLOR   = "#F0F0F0"
+	COLOR_EDIT_SPACING   = 4
+)
+
+// ColorButton shows a color swatch and opens a ColorPicker when clicked.
+// Colors are CSS strings; the changed handler receives the new one each time
+// the user changes it in the picker.
+type ColorButton struct {
+	*Widget
+	color  string
+	picker *ColorPicker
+}
+
+func NewColorButton(parent *Widget, x, y, w, h float32) *ColorButton {
+	button := &ColorButton{
+		Widget: NewWidget(TYPE_COLOR_BUTTON, parent, x, y, w, h),
+		color:  "#000000",
+	}
+	button.I = button
+
+	return button
+}
+
+// SetColor sets the color, ignoring strings that are not CSS colors.
+func (button *ColorButton) SetColor(color string, notify bool) *ColorButton {
+	if _, ok := parseHSLA(color); ok {
+		button.setColor(color, notify)
+	}
+
+	return button
+}
+
+func (button *ColorButton) GetColor() string {
+	return button.color
+}
+
+// GetPicker returns the picker of the button, or nil before it was opened.
+func (button *ColorButton) GetPicker() *ColorPicker {
+	return button.picker
+}
+
+func (button *ColorButton) setColor(color string, notify bool) {
+	if button.color == color {
+		return
+	}
+
+	button.color = color
+	if notify && button.onChanged != nil {
+		button.onChanged(color)
+	}
+	button.PostRedraw()
+
+	return
+}
+
+// OpenPicker pops the picker up under the button.
+func (button *ColorButton) OpenPicker() *ColorButton {
+	if !button.enable {
+		return button
+	}
+
+	if button.picker == nil {
+		button.picker = newColorPicker(button)
+	}
+	button.picker.show()
+
+	return button
+}
+
+func (button *ColorButton) onPointerDown(point *structs.Point) {
+	if !button.enable {
+		return
+	}
+
+	if window := button.GetWindow(); window != nil {
+		window.SetFocus(button.Widget)
+	}
+	button.OpenPicker()
+
+	return
+}
+
+func (button *ColorButton) onKeyDown(code int) {
+	switch code {
+	case keyevent.DOM_VK_SPACE, keyevent.DOM_VK_RETURN, keyevent.DOM_VK_F4:
+		button.OpenPicker()
+	}
+	button.Widget.onKeyDown(code)
+
+	return
+}
+
+func (button *ColorButton) paintBackground(context canvas.Canvas2D) {
+	style := button.getStyle("")
+	w := float64(button.rect.W)
+	h := float64(button.rect.H)
+
+	context.SetFillStyle(colorOr(style.FillColor, COLOR_BUTTON_COLOR))
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	if button.editing {
+		context.SetStrokeStyle(SLIDER_FILL_COLOR)
+	} else {
+		context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	}
+	context.BeginPath()
+	context.Rect(0.5, 0.5, w-1, h-1)
+	context.Stroke()
+
+	return
+}
+
+func (button *ColorButton) paintSelf(context canvas.Canvas2D) {
+	style := button.getStyle("")
+	w := float64(button.rect.W)
+	h := float64(button.rect.H)
+	lineColor := colorOr(style.LineColor, MENU_LINE_COLOR)
+
+	paintSwatch(context, COLOR_BUTTON_PADDING, COLOR_BUTTON_PADDING, w-2*COLOR_BUTTON_PADDING, h-2*COLOR_BUTTON_PADDING, button.color, lineColor)
+
+	return
+}
+
+// colorEditEdit is the Edit of a ColorEdit. Enter rewrites the text as the
+// color it set.
+type colorEditEdit struct {
+	*Edit
+	colorEdit *ColorEdit
+}
+
+func (edit *colorEditEdit) onKeyDown(code int) {
+	if code == keyevent.DOM_VK_RETURN {
+		edit.colorEdit.updateText()
+	} else {
+		edit.Edit.onKeyDown(code)
+	}
+
+	return
+}
+
+// ColorEdit pairs a text field, taking any CSS color, with a ColorButton
+// showing it. Typing a valid color updates the swatch and picking one
+// rewrites the text. The changed handler receives the new color string.
+type ColorEdit struct {
+	*Widget
+	edit   *colorEditEdit
+	button *ColorButton
+	typing bool
+}
+
+func NewColorEdit(parent *Widget, x, y, w, h float32) *ColorEdit {
+	colorEdit := &ColorEdit{
+		Widget: NewWidget(TYPE_COLOR_EDIT, parent, x, y, w, h),
+	}
+	colorEdit.I = colorEdit
+
+	colorEdit.edit = &colorEditEdit{
+		Edit:      NewEdit(colorEdit.Widget, 0, 0, 0, 0),
+		colorEdit: colorEdit,
+	}
+	colorEdit.edit.I = colorEdit.edit
+	colorEdit.edit.SetChangedHandler(func(interface{}) {
+		colorEdit.onTextChanged()
+	})
+
+	colorEdit.button = NewColorButton(colorEdit.Widget, 0, 0, 0, 0)
+	colorEdit.button.SetChangedHandler(func(interface{}) {
+		colorEdit.onColorChanged()
+	})
+
+	colorEdit.layout()
+	colorEdit.updateText()
+
+	return colorEdit
+}
+
+func (colorEdit *ColorEdit) GetEdit() *Edit {
+	return colorEdit.edit.Edit
+}
+
+func (colorEdit *ColorEdit) GetColorButton() *ColorButton {
+	return colorEdit.button
+}
+
+func (colorEdit *ColorEdit) SetColor(color string, notify bool) *ColorEdit {
+	colorEdit.button.SetColor(color, notify)
+	colorEdit.updateText()
+
+	return colorEdit
+}
+
+func (colorEdit *ColorEdit) GetColor() string {
+	return colorEdit.button.GetColor()
+}
+
+func (colorEdit *ColorEdit) updateText() {
+	if color := colorEdit.button.color; colorEdit.edit.GetText() != color {
+		colorEdit.edit.SetText(color, false)
+	}
+
+	return
+}
+
+func (colorEdit *ColorEdit) onColorChanged() {
+	if !colorEdit.typing {
+		colorEdit.updateText()
+	}
+
+	if colorEdit.onChanged != nil {
+		colorEdit.onChanged(colorEdit.button.color)
+	}
+
+	return
+}
+
+// onTextChanged shows the color typed, leaving the swatch alone while the
+// text is not a color yet.
+func (colorEdit *ColorEdit) onTextChanged() {
+	text := strings.TrimSpace(colorEdit.edit.GetText())
+	if _, ok := parseHSLA(text); !ok {
+		return
+	}
+
+	colorEdit.typing = true
+	colorEdit.button.setColor(text, true)
+	colorEdit.typing = false
+
+	return
+}
+
+func (colorEdit *ColorEdit) layout() {
+	w := colorEdit.rect.W
+	h := colorEdit.rect.H
+	size := h
+	if size > w {
+		size = w
+	}
+
+	colorEdit.edit.Move(0, 0)
+	colorEdit.edit.Resize(w-size-COLOR_EDIT_SPACING, h)
+	colorEdit.button.Move(w-size, 0)
+	colorEdit.button.Resize(size, h)
+
+	return
+}
+
+func (colorEdit *ColorEdit) relayout(context canvas.Canvas2D, force bool) {
+	colorEdit.layout()
+	colorEdit.Widget.relayout(context, force)
+
+	return
+}
+
+func (colorEdit *ColorEdit) paintBackground(context canvas.Canvas2D) {
+	return
+}
@@ -0,0 +1,621 @@
+package gwk
+
+import (
+	"fmt"
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/raster"
+	"github.com/Luncher/gwk/pkg/structs"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	COLOR_PICKER_PADDING      = 8
+	COLOR_PICKER_WIDTH        = 240
+	COLOR_PICKER_AREA_HEIGHT  = 120
+	COLOR_PICKER_AREA_CELL    = 4
+	COLOR_PICKER_SLIDER_WIDTH = 20
+	COLOR_PICKER_ROW_HEIGHT   = 24
+	COLOR_PICKER_LABEL_WIDTH  = 14
+	COLOR_PICKER_SWATCH_SIZE  = 18
+	COLOR_PICKER_MAX_RECENT   = 10
+	COLOR_SWATCH_CHECKER      = 4
+	COLOR_SWATCH_LIGHT        = "#FFFFFF"
+	COLOR_SWATCH_DARK         = "#C8C8C8"
+)
+
+// recentColors are the colors last picked, most recent first, shared by all
+// the pickers.
+var recentColors []string
+
+func addRecentColor(str string) {
+	colors := []string{str}
+	for _, recent := range recentColors {
+		if recent != str && len(colors) < COLOR_PICKER_MAX_RECENT {
+			colors = append(colors, recent)
+		}
+	}
+	recentColors = colors
+
+	return
+}
+
+// hsla is a color as its hue in degrees and its saturation, lightness and
+// alpha between 0 and 1. Pickers keep colors in this form so that the hue
+// survives going through grays.
+type hsla struct {
+	h, s, l, a float64
+}
+
+// parseHSLA understands the CSS colors the canvas does: names, #rgb,
+// #rrggbb, #rrggbbaa, rgb() and rgba().
+func parseHSLA(str string) (hsla, bool) {
+	c, ok := raster.ParseColor(str)
+	if !ok {
+		return hsla{}, false
+	}
+
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	return rgbToHSLA(n.R, n.G, n.B, float64(n.A)/255), true
+}
+
+func rgbToHSLA(r, g, b uint8, a float64) hsla {
+	rf := float64(r) / 255
+	gf := float64(g) / 255
+	bf := float64(b) / 255
+	max := math.Max(rf, math.Max(gf, bf))
+	min := math.Min(rf, math.Min(gf, bf))
+	c := hsla{l: (max + min) / 2, a: a}
+
+	d := max - min
+	if d == 0 {
+		return c
+	}
+
+	c.s = d / (1 - math.Abs(2*c.l-1))
+	switch max {
+	case rf:
+		c.h = math.Mod((gf-bf)/d+6, 6)
+	case gf:
+		c.h = (bf-rf)/d + 2
+	default:
+		c.h = (rf-gf)/d + 4
+	}
+	c.h *= 60
+
+	return c
+}
+
+func (c hsla) toRGB() (r, g, b uint8) {
+	chroma := (1 - math.Abs(2*c.l-1)) * c.s
+	h := math.Mod(c.h, 360) / 60
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	var rf, gf, bf float64
+	switch {
+	case h < 1:
+		rf, gf = chroma, x
+	case h < 2:
+		rf, gf = x, chroma
+	case h < 3:
+		gf, bf = chroma, x
+	case h < 4:
+		gf, bf = x, chroma
+	case h < 5:
+		rf, bf = x, chroma
+	default:
+		rf, bf = chroma, x
+	}
+
+	m := c.l - chroma/2
+	scale := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v+m)) * 255))
+	}
+
+	return scale(rf), scale(gf), scale(bf)
+}
+
+// String returns c as #rrggbb when it is opaque, and as rgba() otherwise.
+func (c hsla) String() string {
+	r, g, b := c.toRGB()
+	if c.a >= 1 {
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	}
+
+	alpha := strconv.FormatFloat(math.Round(c.a*100)/100, 'f', -1, 64)
+
+	return fmt.Sprintf("rgba(%d,%d,%d,%s)", r, g, b, alpha)
+}
+
+// hex returns c as #rrggbb, or #rrggbbaa when it is translucent.
+func (c hsla) hex() string {
+	r, g, b := c.toRGB()
+	if c.a >= 1 {
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x%02x", r, g, b, uint8(math.Round(c.a*255)))
+}
+
+// paintSwatch fills a rectangle with color over a checkerboard, so that
+// translucent colors show as such, and frames it.
+func paintSwatch(context canvas.Canvas2D, x, y, w, h float64, color, lineColor string) {
+	context.Save()
+	context.BeginPath()
+	context.Rect(x, y, w, h)
+	context.Clip()
+
+	context.SetFillStyle(COLOR_SWATCH_LIGHT)
+	context.FillRect(x, y, w, h)
+	context.SetFillStyle(COLOR_SWATCH_DARK)
+	for j := 0.0; j*COLOR_SWATCH_CHECKER < h; j++ {
+		for i := math.Mod(j, 2); i*COLOR_SWATCH_CHECKER < w; i += 2 {
+			context.FillRect(x+i*COLOR_SWATCH_CHECKER, y+j*COLOR_SWATCH_CHECKER, COLOR_SWATCH_CHECKER, COLOR_SWATCH_CHECKER)
+		}
+	}
+
+	context.SetFillStyle(color)
+	context.FillRect(x, y, w, h)
+	context.Restore()
+
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(lineColor)
+	context.BeginPath()
+	context.Rect(x+0.5, y+0.5, w-1, h-1)
+	context.Stroke()
+
+	return
+}
+
+// colorPickerArea picks the hue, from left to right, and the saturation,
+// from top to bottom, of a ColorPicker. It is shown at half lightness.
+type colorPickerArea struct {
+	*Widget
+	picker   *ColorPicker
+	dragging bool
+}
+
+func (area *colorPickerArea) pick(point *structs.Point) {
+	p := area.translatePoint(point)
+	c := area.picker.color
+	c.h = math.Max(0, math.Min(359, float64(p.X)/float64(area.rect.W)*360))
+	c.s = math.Max(0, math.Min(1, 1-float64(p.Y)/float64(area.rect.H)))
+	area.picker.setColor(c, nil)
+
+	return
+}
+
+func (area *colorPickerArea) onPointerDown(point *structs.Point) {
+	area.dragging = true
+	area.GetWindow().Grab(area.Widget)
+	area.pick(point)
+
+	return
+}
+
+func (area *colorPickerArea) onPointerMove(point *structs.Point) {
+	if area.dragging {
+		area.pick(point)
+	}
+
+	return
+}
+
+func (area *colorPickerArea) onPointerUp(point *structs.Point) {
+	if area.dragging {
+		area.dragging = false
+		area.GetWindow().Ungrab()
+	}
+
+	return
+}
+
+func (area *colorPickerArea) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (area *colorPickerArea) paintSelf(context canvas.Canvas2D) {
+	w := float64(area.rect.W)
+	h := float64(area.rect.H)
+
+	for y := 0.0; y < h; y += COLOR_PICKER_AREA_CELL {
+		for x := 0.0; x < w; x += COLOR_PICKER_AREA_CELL {
+			c := hsla{h: x / w * 360, s: 1 - y/h, l: 0.5, a: 1}
+			context.SetFillStyle(c.String())
+			context.FillRect(x, y, COLOR_PICKER_AREA_CELL, COLOR_PICKER_AREA_CELL)
+		}
+	}
+
+	c := area.picker.color
+	x := c.h / 360 * w
+	y := (1 - c.s) * h
+	context.SetLineWidth(1)
+	context.SetStrokeStyle("#000000")
+	context.BeginPath()
+	context.Arc(x, y, 5, 0, 2*math.Pi, false)
+	context.Stroke()
+	context.SetStrokeStyle("#FFFFFF")
+	context.BeginPath()
+	context.Arc(x, y, 4, 0, 2*math.Pi, false)
+	context.Stroke()
+
+	return
+}
+
+// colorPickerPreview shows the original and the current color of a
+// ColorPicker side by side.
+type colorPickerPreview struct {
+	*Widget
+	picker *ColorPicker
+}
+
+func (preview *colorPickerPreview) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (preview *colorPickerPreview) paintSelf(context canvas.Canvas2D) {
+	style := preview.getStyle("")
+	w := math.Floor(float64(preview.rect.W) / 2)
+	h := float64(preview.rect.H)
+	lineColor := colorOr(style.LineColor, MENU_LINE_COLOR)
+
+	paintSwatch(context, 0, 0, w, h, preview.picker.original.String(), lineColor)
+	paintSwatch(context, w, 0, float64(preview.rect.W)-w, h, preview.picker.color.String(), lineColor)
+
+	return
+}
+
+// colorPickerRecent shows the recently used colors, picking one on click.
+type colorPickerRecent struct {
+	*Widget
+	picker *ColorPicker
+}
+
+func (recent *colorPickerRecent) onPointerDown(point *structs.Point) {
+	p := recent.translatePoint(point)
+	index := p.X / (COLOR_PICKER_SWATCH_SIZE + 4)
+	if p.X%(COLOR_PICKER_SWATCH_SIZE+4) < COLOR_PICKER_SWATCH_SIZE && index < len(recentColors) {
+		if c, ok := parseHSLA(recentColors[index]); ok {
+			recent.picker.setColor(c, nil)
+		}
+	}
+
+	return
+}
+
+func (recent *colorPickerRecent) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (recent *colorPickerRecent) paintSelf(context canvas.Canvas2D) {
+	style := recent.getStyle("")
+	lineColor := colorOr(style.LineColor, MENU_LINE_COLOR)
+
+	for i, str := range recentColors {
+		x := float64(i * (COLOR_PICKER_SWATCH_SIZE + 4))
+		paintSwatch(context, x, 0, COLOR_PICKER_SWATCH_SIZE, COLOR_PICKER_SWATCH_SIZE, str, lineColor)
+	}
+
+	return
+}
+
+// ColorPicker is the popup of a ColorButton. The color changes live as the
+// user drags in the hue and saturation area, moves the lightness or alpha
+// slider, or types in the hex, RGB and HSL fields. Clicking outside of the
+// picker or Enter keeps the color and Escape restores the original one.
+type ColorPicker struct {
+	*Window
+	button    *ColorButton
+	area      *colorPickerArea
+	lightness *Slider
+	alpha     *Slider
+	hex       *Edit
+	rgb       [3]*Edit
+	hsl       [3]*Edit
+	preview   *colorPickerPreview
+	recent    *colorPickerRecent
+	color     hsla
+	original  hsla
+	restore   string
+	shown     bool
+}
+
+func newColorPicker(button *ColorButton) *ColorPicker {
+	picker := &ColorPicker{
+		Window: newPopupWindow(nil, 0, 0, COLOR_PICKER_WIDTH, 0),
+		button: button,
+	}
+	picker.t = TYPE_COLOR_PICKER
+	picker.I = picker
+
+	pad := COLOR_PICKER_PADDING
+	row := COLOR_PICKER_ROW_HEIGHT
+	width := COLOR_PICKER_WIDTH - 2*pad
+	areaWidth := width - COLOR_PICKER_SLIDER_WIDTH - pad
+
+	picker.area = &colorPickerArea{
+		Widget: NewWidget(TYPE_VIEW_BASE, picker.Widget, float32(pad), float32(pad), float32(areaWidth), COLOR_PICKER_AREA_HEIGHT),
+		picker: picker,
+	}
+	picker.area.I = picker.area
+
+	picker.lightness = NewSlider(picker.Widget, float32(pad+areaWidth+pad), float32(pad), COLOR_PICKER_SLIDER_WIDTH, COLOR_PICKER_AREA_HEIGHT)
+	picker.lightness.SetChangedHandler(func(value interface{}) {
+		c := picker.color
+		c.l = value.(float64) / 100
+		picker.setColor(c, nil)
+	})
+
+	y := pad + COLOR_PICKER_AREA_HEIGHT + pad
+	picker.addLabel("A", pad, y)
+	picker.alpha = NewSlider(picker.Widget, float32(pad+COLOR_PICKER_LABEL_WIDTH+4), float32(y), float32(width-COLOR_PICKER_LABEL_WIDTH-4), float32(row))
+	picker.alpha.SetChangedHandler(func(value interface{}) {
+		c := picker.color
+		c.a = value.(float64) / 100
+		picker.setColor(c, nil)
+	})
+
+	y += row + pad
+	picker.addLabel("#", pad, y)
+	picker.hex = picker.addField(pad+COLOR_PICKER_LABEL_WIDTH+4, y, 100)
+	x := pad + COLOR_PICKER_LABEL_WIDTH + 4 + 100 + pad
+	picker.preview = &colorPickerPreview{
+		Widget: NewWidget(TYPE_VIEW_BASE, picker.Widget, float32(x), float32(y), float32(COLOR_PICKER_WIDTH-pad-x), float32(row)),
+		picker: picker,
+	}
+	picker.preview.I = picker.preview
+
+	fieldWidth := (width - 3*(COLOR_PICKER_LABEL_WIDTH+4) - 2*pad) / 3
+	for k, names := range []string{"RGB", "HSL"} {
+		y += row + pad
+		for i, name := range names {
+			x := pad + i*(COLOR_PICKER_LABEL_WIDTH+4+fieldWidth+pad)
+			picker.addLabel(string(name), x, y)
+			field := picker.addField(x+COLOR_PICKER_LABEL_WIDTH+4, y, fieldWidth)
+			if k == 0 {
+				picker.rgb[i] = field
+			} else {
+				picker.hsl[i] = field
+			}
+		}
+	}
+
+	y += row + pad
+	picker.recent = &colorPickerRecent{
+		Widget: NewWidget(TYPE_VIEW_BASE, picker.Widget, float32(pad), float32(y), float32(width), COLOR_PICKER_SWATCH_SIZE),
+		picker: picker,
+	}
+	picker.recent.I = picker.recent
+	picker.Resize(COLOR_PICKER_WIDTH, y+COLOR_PICKER_SWATCH_SIZE+pad)
+
+	return picker
+}
+
+func (picker *ColorPicker) addLabel(text string, x, y int) {
+	label := NewLabel(picker.Widget, float32(x), float32(y), COLOR_PICKER_LABEL_WIDTH, COLOR_PICKER_ROW_HEIGHT)
+	label.SetText(text, false)
+
+	return
+}
+
+func (picker *ColorPicker) addField(x, y, w int) *Edit {
+	field := NewEdit(picker.Widget, float32(x), float32(y), float32(w), COLOR_PICKER_ROW_HEIGHT)
+	field.SetChangedHandler(func(interface{}) {
+		picker.onFieldChanged(field)
+	})
+
+	return field
+}
+
+// GetColor returns the color being picked, as ColorButton.GetColor does.
+func (picker *ColorPicker) GetColor() string {
+	return picker.color.String()
+}
+
+func (picker *ColorPicker) IsShown() bool {
+	return picker.shown
+}
+
+// setColor makes c the picked color, refreshing the controls except source,
+// the field being typed in, and reporting it to the button.
+func (picker *ColorPicker) setColor(c hsla, source *Edit) {
+	picker.color = c
+	picker.updateFields(source)
+	picker.button.setColor(c.String(), true)
+	picker.PostRedraw()
+
+	return
+}
+
+func (picker *ColorPicker) updateFields(source *Edit) {
+	c := picker.color
+	r, g, b := c.toRGB()
+	texts := map[*Edit]string{
+		picker.hex:    c.hex(),
+		picker.rgb[0]: strconv.Itoa(int(r)),
+		picker.rgb[1]: strconv.Itoa(int(g)),
+		picker.rgb[2]: strconv.Itoa(int(b)),
+		picker.hsl[0]: strconv.Itoa(int(math.Round(c.h))),
+		picker.hsl[1]: strconv.Itoa(int(math.Round(c.s * 100))),
+		picker.hsl[2]: strconv.Itoa(int(math.Round(c.l * 100))),
+	}
+
+	for field, text := range texts {
+		if field != source && field.GetText() != text {
+			field.SetText(text, false)
+		}
+	}
+	picker.lightness.SetValue(c.l*100, false)
+	picker.alpha.SetValue(c.a*100, false)
+
+	return
+}
+
+// parseFields reads three integers from fields, each between 0 and the
+// matching max.
+func parseFields(fields [3]*Edit, max [3]int) ([3]float64, bool) {
+	var values [3]float64
+	for i, field := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(field.GetText()))
+		if err != nil || n < 0 || n > max[i] {
+			return values, false
+		}
+		values[i] = float64(n)
+	}
+
+	return values, true
+}
+
+// onFieldChanged applies the text typed in field once it is valid.
+func (picker *ColorPicker) onFieldChanged(field *Edit) {
+	c := picker.color
+
+	switch field {
+	case picker.hex:
+		parsed, ok := parseHSLA(field.GetText())
+		if !ok {
+			return
+		}
+		if parsed.s == 0 {
+			parsed.h = c.h
+		}
+		c = parsed
+	case picker.rgb[0], picker.rgb[1], picker.rgb[2]:
+		v, ok := parseFields(picker.rgb, [3]int{255, 255, 255})
+		if !ok {
+			return
+		}
+		parsed := rgbToHSLA(uint8(v[0]), uint8(v[1]), uint8(v[2]), c.a)
+		if parsed.s == 0 {
+			parsed.h = c.h
+		}
+		c = parsed
+	default:
+		v, ok := parseFields(picker.hsl, [3]int{360, 100, 100})
+		if !ok {
+			return
+		}
+		c = hsla{h: math.Mod(v[0], 360), s: v[1] / 100, l: v[2] / 100, a: c.a}
+	}
+	picker.setColor(c, field)
+
+	return
+}
+
+// show pops the picker up under the button, or above it when there is not
+// enough room below.
+func (picker *ColorPicker) show() {
+	button := picker.button
+	manager := picker.manager
+
+	c, ok := parseHSLA(button.color)
+	if !ok {
+		c = hsla{a: 1}
+	}
+	picker.color = c
+	picker.original = c
+	picker.restore = button.color
+	picker.updateFields(nil)
+
+	p := button.GetAbsPosition()
+	w := picker.rect.W
+	h := picker.rect.H
+	y := p.Y + button.rect.H
+	if y+h > manager.h && p.Y-h >= 0 {
+		y = p.Y - h
+	}
+	x := int(math.Max(0, math.Min(float64(p.X), float64(manager.w-w))))
+	picker.Move(x, int(math.Max(0, float64(y))))
+
+	if !picker.shown {
+		picker.shown = true
+		manager.addWindow(picker.Window)
+		manager.Grab(picker.Window)
+	}
+	picker.PostRedraw()
+
+	return
+}
+
+// hide closes the picker, remembering the color among the recent ones when
+// keep is true and restoring the original one otherwise.
+func (picker *ColorPicker) hide(keep bool) {
+	if !picker.shown {
+		return
+	}
+
+	manager := picker.manager
+	picker.shown = false
+	manager.Ungrab(picker.Window)
+	manager.removeWindow(picker.Window)
+	if window := picker.button.GetWindow(); window != nil {
+		manager.target = window
+	}
+
+	if !keep {
+		picker.button.setColor(picker.restore, true)
+	} else if picker.color != picker.original {
+		addRecentColor(picker.color.String())
+	}
+
+	return
+}
+
+func (picker *ColorPicker) onPointerDown(point *structs.Point) {
+	if isPointInRect(point, picker.rect) {
+		picker.Window.onPointerDown(point)
+		return
+	}
+	picker.hide(true)
+
+	return
+}
+
+func (picker *ColorPicker) onPointerMove(point *structs.Point) {
+	if isPointInRect(point, picker.rect) || picker.grabWidget != nil {
+		picker.Window.onPointerMove(point)
+	}
+
+	return
+}
+
+func (picker *ColorPicker) onPointerUp(point *structs.Point) {
+	if isPointInRect(point, picker.rect) || picker.grabWidget != nil {
+		picker.Window.onPointerUp(point)
+	}
+
+	return
+}
+
+func (picker *ColorPicker) onKeyDown(code int) {
+	switch code {
+	case keyevent.DOM_VK_RETURN:
+		picker.hide(true)
+	case keyevent.DOM_VK_ESCAPE:
+		picker.hide(false)
+	default:
+		picker.Window.onKeyDown(code)
+	}
+
+	return
+}
+
+func (picker *ColorPicker) paintBackground(context canvas.Canvas2D) {
+	style := picker.getStyle("")
+	w := float64(picker.rect.W)
+	h := float64(picker.rect.H)
+
+	context.SetFillStyle(colorOr(style.FillColor, MENU_FILL_COLOR))
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.Rect(0.5, 0.5, w-1, h-1)
+	context.Stroke()
+
+	return
+}
@@ -0,0 +1,735 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	COMBOBOX_BUTTON_WIDTH     = 20
+	COMBOBOX_PADDING          = 6
+	COMBOBOX_POPUP_MAX_ITEMS  = 8
+	COMBOBOX_TYPE_AHEAD_DELAY = time.Second
+	COMBOBOX_FILL_COLOR       = "#FFFFFF"
+	COMBOBOX_BUTTON_COLOR     = "#F0F0F0"
+)
+
+// comboBoxEdit is the Edit of an editable ComboBox. It opens the popup with
+// the Down key and filters it whenever the text changes.
+type comboBoxEdit struct {
+	*Edit
+	combo *ComboBox
+}
+
+func (edit *comboBoxEdit) onKeyDown(code int) {
+	combo := edit.combo
+
+	switch code {
+	case keyevent.DOM_VK_UP, keyevent.DOM_VK_DOWN:
+		combo.openPopup()
+	case keyevent.DOM_VK_RETURN:
+		combo.commitText()
+	default:
+		text := edit.GetText()
+		edit.Edit.onKeyDown(code)
+		if edit.GetText() != text {
+			combo.onTextChanged()
+		}
+	}
+
+	return
+}
+
+func (edit *comboBoxEdit) onKeyPress(char rune) {
+	text := edit.GetText()
+	edit.Edit.onKeyPress(char)
+	if edit.GetText() != text {
+		edit.combo.onTextChanged()
+	}
+
+	return
+}
+
+// comboBoxList is the ListView of a ComboBoxPopup. The row under the pointer
+// becomes the current one and releasing the pointer over it chooses it.
+type comboBoxList struct {
+	*ListView
+	popup *ComboBoxPopup
+}
+
+func (list *comboBoxList) getItemType() string {
+	return TYPE_COMBOBOX_POPUP_ITEM
+}
+
+func (list *comboBoxList) onContentPointerMove(point *structs.Point) {
+	if index := list.getIndexAtPoint(point); index >= 0 && index != list.current {
+		list.current = index
+		list.anchor = index
+		list.selectOnly(index)
+		list.PostRedraw()
+	}
+
+	return
+}
+
+func (list *comboBoxList) onContentPointerUp(point *structs.Point) {
+	if index := list.getIndexAtPoint(point); index >= 0 {
+		list.popup.combo.choose(list.popup.filtered[index])
+	}
+
+	return
+}
+
+// ComboBoxPopup is the window listing the options of a ComboBox. Like a Menu
+// it stays out of the window stack until it is shown and grabs all input
+// while it is: clicking outside of it closes it.
+type ComboBoxPopup struct {
+	*Window
+	combo    *ComboBox
+	list     *comboBoxList
+	filtered []int
+	shown    bool
+}
+
+func newComboBoxPopup(combo *ComboBox) *ComboBoxPopup {
+	popup := &ComboBoxPopup{
+		Window: newPopupWindow(nil, 0, 0, 0, 0),
+		combo:  combo,
+	}
+	popup.t = TYPE_COMBOBOX_POPUP
+	popup.I = popup
+
+	popup.list = &comboBoxList{
+		ListView: NewListView(popup.Widget, 1, 1, 0, 0),
+		popup:    popup,
+	}
+	popup.list.owner = popup.list
+	popup.list.I = popup.list
+
+	return popup
+}
+
+// GetCount and RenderItem make the popup the data source of its list,
+// showing the filtered options.
+func (popup *ComboBoxPopup) GetCount() int {
+	return len(popup.filtered)
+}
+
+func (popup *ComboBoxPopup) RenderItem(item *ListItem, index int) {
+	item.SetText(popup.combo.options[popup.filtered[index]], false)
+
+	return
+}
+
+func (popup *ComboBoxPopup) IsShown() bool {
+	return popup.shown
+}
+
+// show lays the popup out under the combo box, or above it when there is
+// not enough room below, with the option current highlighted.
+func (popup *ComboBoxPopup) show(filtered []int, current int) {
+	combo := popup.combo
+	manager := popup.manager
+	popup.filtered = filtered
+
+	list := popup.list
+	rows := int(math.Min(float64(len(filtered)), COMBOBOX_POPUP_MAX_ITEMS))
+	w := combo.rect.W
+	h := rows*list.GetItemHeight() + 2
+	list.Resize(w-2, h-2)
+	list.SetDataSource(popup)
+	list.ClearSelection(false)
+	for i, option := range filtered {
+		if option == current {
+			list.SetCurrent(i)
+			list.SetSelected(i, true, false)
+			break
+		}
+	}
+
+	p := combo.GetAbsPosition()
+	y := p.Y + combo.rect.H
+	if y+h > manager.h && p.Y-h >= 0 {
+		y = p.Y - h
+	}
+	x := int(math.Max(0, math.Min(float64(p.X), float64(manager.w-w))))
+	popup.Resize(w, h)
+	popup.Move(x, y)
+
+	if !popup.shown {
+		popup.shown = true
+		manager.addWindow(popup.Window)
+		manager.Grab(popup.Window)
+	}
+	popup.SetFocus(list.Widget)
+	popup.PostRedraw()
+
+	return
+}
+
+// refresh lists the options again after they changed, keeping the row the
+// user moved to current.
+func (popup *ComboBoxPopup) refresh() {
+	combo := popup.combo
+	current := combo.selected
+	if list := popup.list; list.current >= 0 {
+		current = popup.filtered[list.current]
+	}
+	popup.show(combo.filter(), current)
+
+	return
+}
+
+// hide closes the popup and gives the keyboard back to the window of the
+// combo box.
+func (popup *ComboBoxPopup) hide() {
+	if !popup.shown {
+		return
+	}
+
+	manager := popup.manager
+	popup.shown = false
+	manager.Ungrab(popup.Window)
+	manager.removeWindow(popup.Window)
+	if window := popup.combo.GetWindow(); window != nil {
+		manager.target = window
+	}
+
+	return
+}
+
+func (popup *ComboBoxPopup) onPointerDown(point *structs.Point) {
+	if isPointInRect(point, popup.rect) {
+		popup.Window.onPointerDown(point)
+		return
+	}
+
+	combo := popup.combo
+	popup.hide()
+	if p := combo.translatePoint(point); isPointInRect(p, structs.NewRect(0, 0, combo.rect.W, combo.rect.H)) {
+		combo.takeFocus()
+	}
+
+	return
+}
+
+func (popup *ComboBoxPopup) onPointerMove(point *structs.Point) {
+	if isPointInRect(point, popup.rect) {
+		popup.Window.onPointerMove(point)
+	}
+
+	return
+}
+
+func (popup *ComboBoxPopup) onPointerUp(point *structs.Point) {
+	if isPointInRect(point, popup.rect) {
+		popup.Window.onPointerUp(point)
+	}
+
+	return
+}
+
+func (popup *ComboBoxPopup) onContextMenu(point *structs.Point) {
+	popup.hide()
+
+	return
+}
+
+// onKeyDown moves through the list with the navigation keys. The other keys
+// go to the edit of an editable combo box so that typing keeps filtering.
+func (popup *ComboBoxPopup) onKeyDown(code int) {
+	combo := popup.combo
+	list := popup.list
+
+	switch code {
+	case keyevent.DOM_VK_UP, keyevent.DOM_VK_DOWN, keyevent.DOM_VK_PAGE_UP, keyevent.DOM_VK_PAGE_DOWN:
+		if list.current < 0 && code == keyevent.DOM_VK_UP {
+			list.moveCurrent(list.getCount()-1, false, false)
+		} else {
+			list.ListView.onKeyDown(code)
+		}
+	case keyevent.DOM_VK_RETURN:
+		if list.current >= 0 {
+			combo.choose(popup.filtered[list.current])
+		} else {
+			combo.commitText()
+		}
+	case keyevent.DOM_VK_ESCAPE:
+		popup.hide()
+	case keyevent.DOM_VK_TAB:
+		popup.hide()
+	default:
+		if combo.edit != nil {
+			combo.edit.onKeyDown(code)
+		} else if code == keyevent.DOM_VK_HOME || code == keyevent.DOM_VK_END {
+			list.ListView.onKeyDown(code)
+		}
+	}
+
+	return
+}
+
+func (popup *ComboBoxPopup) onKeyPress(char rune) {
+	combo := popup.combo
+	if combo.edit != nil {
+		combo.edit.onKeyPress(char)
+	} else if index := combo.findTypeAhead(char); index >= 0 {
+		popup.list.moveCurrent(index, false, false)
+	}
+
+	return
+}
+
+func (popup *ComboBoxPopup) paintBackground(context canvas.Canvas2D) {
+	style := popup.getStyle("")
+	w := float64(popup.rect.W)
+	h := float64(popup.rect.H)
+
+	context.SetFillStyle(colorOr(style.FillColor, COMBOBOX_FILL_COLOR))
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.Rect(0.5, 0.5, w-1, h-1)
+	context.Stroke()
+
+	return
+}
+
+// ComboBox lets the user pick one of a list of options from a popup. In
+// editable mode any text can be entered and the popup only lists the options
+// containing it. Without an edit, typing the first letters of an option
+// selects it. The changed handler receives the new value as a string.
+type ComboBox struct {
+	*Widget
+	options     []string
+	selected    int
+	value       string
+	editable    bool
+	edit        *comboBoxEdit
+	popup       *ComboBoxPopup
+	typeAhead   string
+	typeAheadAt time.Time
+}
+
+func NewComboBox(parent *Widget, x, y, w, h float32) *ComboBox {
+	combo := &ComboBox{
+		Widget:   NewWidget(TYPE_COMBOBOX, parent, x, y, w, h),
+		selected: -1,
+	}
+	combo.I = combo
+
+	return combo
+}
+
+func (combo *ComboBox) SetOptions(options []string) *ComboBox {
+	combo.options = options
+	combo.selected = -1
+	if combo.edit == nil {
+		combo.value = ""
+	}
+	combo.closePopup()
+	combo.PostRedraw()
+
+	return combo
+}
+
+// AddOption appends option, listing it at once when the popup is open.
+func (combo *ComboBox) AddOption(option string) *ComboBox {
+	combo.options = append(combo.options, option)
+	if combo.IsPopupShown() {
+		combo.popup.refresh()
+	}
+	combo.PostRedraw()
+
+	return combo
+}
+
+func (combo *ComboBox) GetOptions() []string {
+	return combo.options
+}
+
+// SetEditable shows an edit in place of the selected option, letting the
+// user enter a value that is not one of the options.
+func (combo *ComboBox) SetEditable(editable bool) *ComboBox {
+	if combo.editable == editable {
+		return combo
+	}
+
+	combo.editable = editable
+	combo.closePopup()
+	if editable {
+		combo.edit = &comboBoxEdit{
+			Edit:  NewEdit(combo.Widget, 0, 0, float32(combo.rect.W-COMBOBOX_BUTTON_WIDTH), float32(combo.rect.H)),
+			combo: combo,
+		}
+		combo.edit.I = combo.edit
+		if combo.selected >= 0 {
+			combo.edit.SetText(combo.options[combo.selected], false)
+		}
+	} else {
+		combo.RemoveChild(combo.edit.Widget)
+		combo.edit = nil
+	}
+	combo.value = combo.GetValue()
+	combo.PostRedraw()
+
+	return combo
+}
+
+func (combo *ComboBox) IsEditable() bool {
+	return combo.editable
+}
+
+func (combo *ComboBox) GetSelectedIndex() int {
+	return combo.selected
+}
+
+// SetSelectedIndex selects option index, or nothing with -1.
+func (combo *ComboBox) SetSelectedIndex(index int, notify bool) *ComboBox {
+	if index < -1 || index >= len(combo.options) {
+		return combo
+	}
+
+	value := ""
+	if index >= 0 {
+		value = combo.options[index]
+	}
+
+	changed := combo.selected != index || combo.value != value
+	combo.selected = index
+	combo.value = value
+	if combo.edit != nil {
+		combo.edit.SetText(value, false)
+	}
+
+	if changed {
+		combo.onValueChanged(notify)
+	}
+
+	return combo
+}
+
+// GetValue returns the text of an editable combo box, including what is
+// being typed, or the selected option.
+func (combo *ComboBox) GetValue() string {
+	if combo.edit != nil {
+		return combo.edit.GetText()
+	}
+
+	if combo.selected < 0 {
+		return ""
+	}
+
+	return combo.options[combo.selected]
+}
+
+// SetValue selects the option equal to value. An editable combo box also
+// takes a value that is not one of the options.
+func (combo *ComboBox) SetValue(value string, notify bool) *ComboBox {
+	index := combo.indexOf(value)
+	if index >= 0 || combo.edit == nil {
+		return combo.SetSelectedIndex(index, notify)
+	}
+
+	changed := combo.selected >= 0 || combo.value != value
+	combo.selected = -1
+	combo.value = value
+	combo.edit.SetText(value, false)
+	if changed {
+		combo.onValueChanged(notify)
+	}
+
+	return combo
+}
+
+func (combo *ComboBox) IsPopupShown() bool {
+	return combo.popup != nil && combo.popup.shown
+}
+
+func (combo *ComboBox) indexOf(value string) int {
+	for i, option := range combo.options {
+		if option == value {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (combo *ComboBox) onValueChanged(notify bool) {
+	if notify && combo.onChanged != nil {
+		combo.onChanged(combo.GetValue())
+	}
+	combo.PostRedraw()
+
+	return
+}
+
+// filter returns the indexes of the options to list in the popup: those
+// containing the text of an editable combo box, or all of them.
+func (combo *ComboBox) filter() []int {
+	text := ""
+	if combo.edit != nil {
+		text = strings.ToLower(combo.edit.GetText())
+	}
+
+	filtered := make([]int, 0, len(combo.options))
+	for i, option := range combo.options {
+		if strings.Contains(strings.ToLower(option), text) {
+			filtered = append(filtered, i)
+		}
+	}
+
+	return filtered
+}
+
+func (combo *ComboBox) openPopup() {
+	combo.showPopup(combo.filter())
+
+	return
+}
+
+func (combo *ComboBox) showPopup(filtered []int) {
+	if !combo.enable || len(filtered) == 0 {
+		combo.closePopup()
+		return
+	}
+
+	if combo.popup == nil {
+		combo.popup = newComboBoxPopup(combo)
+	}
+	combo.popup.show(filtered, combo.selected)
+
+	return
+}
+
+func (combo *ComboBox) closePopup() {
+	if combo.popup != nil {
+		combo.popup.hide()
+	}
+
+	return
+}
+
+// choose selects option index from the popup and closes it.
+func (combo *ComboBox) choose(index int) {
+	combo.closePopup()
+	combo.SetSelectedIndex(index, true)
+	if combo.edit != nil {
+		combo.edit.SelectAll()
+	}
+
+	return
+}
+
+// commitText closes the popup and reports the text typed in an editable
+// combo box, selecting the option it matches.
+func (combo *ComboBox) commitText() {
+	combo.closePopup()
+	combo.SetValue(combo.edit.GetText(), true)
+
+	return
+}
+
+// onTextChanged refilters the popup after the text of an editable combo box
+// changed, closing it when no option matches.
+func (combo *ComboBox) onTextChanged() {
+	if len(combo.edit.GetText()) == 0 {
+		combo.closePopup()
+	} else {
+		combo.showPopup(combo.filter())
+	}
+
+	return
+}
+
+// findTypeAhead adds char to the typed prefix, started over after a pause,
+// and returns the option it selects, or -1. Repeating a single letter cycles
+// through the options starting with it.
+func (combo *ComboBox) findTypeAhead(char rune) int {
+	n := len(combo.options)
+	if !unicode.IsPrint(char) || n == 0 {
+		return -1
+	}
+
+	now := time.Now()
+	if now.Sub(combo.typeAheadAt) > COMBOBOX_TYPE_AHEAD_DELAY {
+		combo.typeAhead = ""
+	}
+	combo.typeAheadAt = now
+
+	current := combo.selected
+	if combo.IsPopupShown() && combo.popup.list.current >= 0 {
+		current = combo.popup.list.current
+	}
+
+	combo.typeAhead += string(unicode.ToLower(char))
+	prefix := combo.typeAhead
+	start := current
+	if runes := []rune(prefix); strings.Count(prefix, string(runes[0])) == len(runes) {
+		prefix = string(runes[0])
+		start++
+	}
+
+	for k := 0; k < n; k++ {
+		i := ((start+k)%n + n) % n
+		if strings.HasPrefix(strings.ToLower(combo.options[i]), prefix) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// takeFocus focuses the edit of an editable combo box, or the combo box.
+func (combo *ComboBox) takeFocus() {
+	window := combo.GetWindow()
+	if window == nil {
+		return
+	}
+
+	if combo.edit != nil {
+		window.SetFocus(combo.edit.Widget)
+	} else {
+		window.SetFocus(combo.Widget)
+	}
+
+	return
+}
+
+func (combo *ComboBox) isInButton(point *structs.Point) bool {
+	p := combo.translatePoint(point)
+
+	return p.X >= combo.rect.W-COMBOBOX_BUTTON_WIDTH
+}
+
+func (combo *ComboBox) onPointerDown(point *structs.Point) {
+	if !combo.enable {
+		return
+	}
+
+	if combo.edit == nil || combo.isInButton(point) {
+		combo.takeFocus()
+		if combo.IsPopupShown() {
+			combo.closePopup()
+		} else {
+			combo.openPopup()
+		}
+		return
+	}
+	combo.Widget.onPointerDown(point)
+
+	return
+}
+
+func (combo *ComboBox) onKeyDown(code int) {
+	if combo.enable {
+		switch code {
+		case keyevent.DOM_VK_UP:
+			combo.SetSelectedIndex(int(math.Max(0, float64(combo.selected-1))), true)
+		case keyevent.DOM_VK_DOWN:
+			if combo.isAltDown() {
+				combo.openPopup()
+			} else {
+				combo.SetSelectedIndex(int(math.Min(float64(len(combo.options)-1), float64(combo.selected+1))), true)
+			}
+		case keyevent.DOM_VK_HOME:
+			combo.SetSelectedIndex(int(math.Min(0, float64(len(combo.options)-1))), true)
+		case keyevent.DOM_VK_END:
+			combo.SetSelectedIndex(len(combo.options)-1, true)
+		case keyevent.DOM_VK_F4, keyevent.DOM_VK_SPACE, keyevent.DOM_VK_RETURN:
+			combo.openPopup()
+		}
+	}
+	combo.Widget.onKeyDown(code)
+
+	return
+}
+
+func (combo *ComboBox) onKeyPress(char rune) {
+	if combo.enable && char != ' ' {
+		if index := combo.findTypeAhead(char); index >= 0 {
+			combo.SetSelectedIndex(index, true)
+		}
+	}
+
+	return
+}
+
+func (combo *ComboBox) relayout(context canvas.Canvas2D, force bool) {
+	if combo.edit != nil {
+		combo.edit.Move(0, 0)
+		combo.edit.Resize(combo.rect.W-COMBOBOX_BUTTON_WIDTH, combo.rect.H)
+	}
+	combo.Widget.relayout(context, force)
+
+	return
+}
+
+func (combo *ComboBox) paintBackground(context canvas.Canvas2D) {
+	style := combo.getStyle("")
+	w := float64(combo.rect.W)
+	h := float64(combo.rect.H)
+	x := w - COMBOBOX_BUTTON_WIDTH
+
+	context.SetFillStyle(colorOr(style.FillColor, COMBOBOX_FILL_COLOR))
+	context.FillRect(0, 0, w, h)
+	context.SetFillStyle(COMBOBOX_BUTTON_COLOR)
+	context.FillRect(x, 0, COMBOBOX_BUTTON_WIDTH, h)
+
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.Rect(0.5, 0.5, w-1, h-1)
+	context.MoveTo(x+0.5, 0)
+	context.LineTo(x+0.5, h)
+	context.Stroke()
+
+	return
+}
+
+func (combo *ComboBox) paintSelf(context canvas.Canvas2D) {
+	style := combo.getStyle("")
+	w := float64(combo.rect.W)
+	h := float64(combo.rect.H)
+
+	context.SetFillStyle(style.TextColor)
+
+	cx := w - COMBOBOX_BUTTON_WIDTH/2
+	cy := h / 2
+	context.BeginPath()
+	if combo.IsPopupShown() && combo.popup.rect.Y < combo.GetAbsPosition().Y {
+		context.MoveTo(cx-4, cy+2)
+		context.LineTo(cx+4, cy+2)
+		context.LineTo(cx, cy-3)
+	} else {
+		context.MoveTo(cx-4, cy-2)
+		context.LineTo(cx+4, cy-2)
+		context.LineTo(cx, cy+3)
+	}
+	context.ClosePath()
+	context.Fill()
+
+	if combo.edit == nil && combo.selected >= 0 {
+		context.SetFont(style.Font)
+		context.SetTextAlign("left")
+		context.SetTextBaseline("middle")
+		context.FillText(combo.options[combo.selected], COMBOBOX_PADDING, h/2, w-COMBOBOX_BUTTON_WIDTH-2*COMBOBOX_PADDING)
+	}
+
+	if window := combo.GetWindow(); combo.edit == nil && window != nil && window.GetFocus() == combo.Widget {
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+		context.BeginPath()
+		context.Rect(2.5, 2.5, w-COMBOBOX_BUTTON_WIDTH-4, h-5)
+		context.Stroke()
+	}
+
+	return
+}
@@ -0,0 +1,199 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var comboBoxOptions = []string{"apple", "banana", "blueberry", "cherry", "cranberry"}
+
+// newComboBoxScene returns a ComboBox 120x24 at 10,10 of comboBoxOptions
+// and one at 10,270, near the bottom of the 300x300 scene.
+func newComboBoxScene() (typist, *ComboBox, *ComboBox) {
+	var top, bottom *ComboBox
+	k := newScene(300, 300, func(win *Window) {
+		top = NewComboBox(win.Widget, 10, 10, 120, 24).SetOptions(append([]string(nil), comboBoxOptions...))
+		bottom = NewComboBox(win.Widget, 10, 270, 120, 24).SetOptions(append([]string(nil), comboBoxOptions...))
+	})
+
+	return k, top, bottom
+}
+
+// getPopupOptions returns the options listed in the popup of combo.
+func getPopupOptions(combo *ComboBox) []string {
+	options := []string{}
+	for _, index := range combo.popup.filtered {
+		options = append(options, combo.options[index])
+	}
+
+	return options
+}
+
+func TestComboBoxPopupPlacement(t *testing.T) {
+	k, top, bottom := newComboBoxScene()
+
+	k.click(20, 20)
+	if !top.IsPopupShown() {
+		t.Fatalf("clicking the combo box did not open its popup")
+	}
+	if rect := top.popup.rect; rect.X != 10 || rect.Y != 34 || rect.W != 120 {
+		t.Fatalf("popup at %v, want under the combo box", rect)
+	}
+
+	// Clicking the combo box again closes the popup.
+	k.click(20, 20)
+	if top.IsPopupShown() {
+		t.Fatalf("clicking the combo box again did not close its popup")
+	}
+
+	k.click(20, 280)
+	if !bottom.IsPopupShown() {
+		t.Fatalf("clicking the combo box did not open its popup")
+	}
+	if rect := bottom.popup.rect; rect.Y+rect.H != 270 {
+		t.Fatalf("popup at %v, want above the combo box", rect)
+	}
+
+	// Clicking outside closes the popup.
+	k.click(250, 150)
+	if bottom.IsPopupShown() {
+		t.Fatalf("clicking outside did not close the popup")
+	}
+}
+
+func TestComboBoxPopupKeys(t *testing.T) {
+	k, combo, _ := newComboBoxScene()
+	changed := []string{}
+	combo.SetChangedHandler(func(value interface{}) {
+		changed = append(changed, value.(string))
+
+		return
+	})
+
+	k.click(20, 20)
+	k.key(keyevent.DOM_VK_DOWN, keyevent.DOM_VK_DOWN, keyevent.DOM_VK_RETURN)
+	if combo.IsPopupShown() || combo.GetSelectedIndex() != 1 || !reflect.DeepEqual(changed, []string{"banana"}) {
+		t.Fatalf("Enter: popup %v, selected %d, changes %v", combo.IsPopupShown(), combo.GetSelectedIndex(), changed)
+	}
+
+	// The popup opens on the selected option.
+	k.key(keyevent.DOM_VK_F4)
+	if !combo.IsPopupShown() || combo.popup.list.current != 1 {
+		t.Fatalf("F4 did not open the popup on the selected option")
+	}
+	k.key(keyevent.DOM_VK_DOWN, keyevent.DOM_VK_ESCAPE)
+	if combo.IsPopupShown() || combo.GetSelectedIndex() != 1 || len(changed) != 1 {
+		t.Fatalf("Escape: popup %v, selected %d, changes %v", combo.IsPopupShown(), combo.GetSelectedIndex(), changed)
+	}
+
+	// Keys go back to the combo box once the popup is closed.
+	k.key(keyevent.DOM_VK_DOWN)
+	if combo.GetSelectedIndex() != 2 {
+		t.Fatalf("Down after closing the popup selected %d, want 2", combo.GetSelectedIndex())
+	}
+}
+
+func TestComboBoxTypeAhead(t *testing.T) {
+	k, combo, _ := newComboBoxScene()
+	k.click(20, 20)
+	k.click(20, 20)
+
+	steps := []struct {
+		text     string
+		pause    bool
+		selected int
+	}{
+		{"b", false, 1},
+		// Repeating a letter cycles through the options starting with it.
+		{"b", false, 2},
+		{"b", false, 1},
+		{"cr", true, 4},
+		// Without a pause the letters add up to a prefix matching nothing.
+		{"a", false, 4},
+		{"a", true, 0},
+	}
+	for i, step := range steps {
+		if step.pause {
+			combo.typeAheadAt = time.Time{}
+		}
+		k.typeText(step.text)
+		if combo.GetSelectedIndex() != step.selected {
+			t.Fatalf("step %d: typing %q selected %d, want %d", i, step.text, combo.GetSelectedIndex(), step.selected)
+		}
+	}
+
+	// In the popup typing moves the current row without choosing it.
+	k.key(keyevent.DOM_VK_F4)
+	combo.typeAheadAt = time.Time{}
+	k.typeText("c")
+	if combo.popup.list.current != 3 || combo.GetSelectedIndex() != 0 {
+		t.Fatalf("typing in the popup: current row %d, selected %d", combo.popup.list.current, combo.GetSelectedIndex())
+	}
+	k.key(keyevent.DOM_VK_RETURN)
+	if combo.GetValue() != "cherry" {
+		t.Fatalf("value %q after choosing from the popup, want cherry", combo.GetValue())
+	}
+}
+
+func TestComboBoxEditableFilter(t *testing.T) {
+	k, combo, _ := newComboBoxScene()
+	combo.SetEditable(true)
+	k.m.Snapshot()
+
+	k.click(20, 20)
+	k.typeText("AN")
+	if !combo.IsPopupShown() || !reflect.DeepEqual(getPopupOptions(combo), []string{"banana", "cranberry"}) {
+		t.Fatalf("typing AN: popup %v lists %v", combo.IsPopupShown(), getPopupOptions(combo))
+	}
+
+	k.typeText("x")
+	if combo.IsPopupShown() {
+		t.Fatalf("the popup stayed open with no option matching")
+	}
+
+	k.key(keyevent.DOM_VK_BACK_SPACE)
+	if !combo.IsPopupShown() || len(combo.popup.filtered) != 2 {
+		t.Fatalf("deleting a letter did not list the matching options again")
+	}
+
+	k.key(keyevent.DOM_VK_DOWN, keyevent.DOM_VK_DOWN, keyevent.DOM_VK_RETURN)
+	if combo.IsPopupShown() || combo.GetValue() != "cranberry" || combo.GetSelectedIndex() != 4 {
+		t.Fatalf("Enter: popup %v, value %q, selected %d", combo.IsPopupShown(), combo.GetValue(), combo.GetSelectedIndex())
+	}
+
+	// Any text is taken, selecting nothing.
+	k.typeText("kiwi")
+	k.key(keyevent.DOM_VK_ESCAPE)
+	if combo.IsPopupShown() || combo.GetValue() != "kiwi" {
+		t.Fatalf("Escape: popup %v, value %q", combo.IsPopupShown(), combo.GetValue())
+	}
+	k.key(keyevent.DOM_VK_RETURN)
+	if combo.GetValue() != "kiwi" || combo.GetSelectedIndex() != -1 {
+		t.Fatalf("Enter: value %q, selected %d", combo.GetValue(), combo.GetSelectedIndex())
+	}
+}
+
+func TestComboBoxAddOptionToOpenPopup(t *testing.T) {
+	k, combo, _ := newComboBoxScene()
+	k.click(20, 20)
+	k.key(keyevent.DOM_VK_DOWN, keyevent.DOM_VK_DOWN)
+	h := combo.popup.rect.H
+
+	combo.AddOption("date")
+	if !reflect.DeepEqual(getPopupOptions(combo), append(append([]string(nil), comboBoxOptions...), "date")) {
+		t.Fatalf("the popup lists %v after adding date", getPopupOptions(combo))
+	}
+	if combo.popup.rect.H != h+combo.popup.list.GetItemHeight() {
+		t.Fatalf("popup height %d after adding an option, want %d", combo.popup.rect.H, h+combo.popup.list.GetItemHeight())
+	}
+	if combo.popup.list.current != 1 {
+		t.Fatalf("current row %d after adding an option, want 1", combo.popup.list.current)
+	}
+
+	k.key(keyevent.DOM_VK_END, keyevent.DOM_VK_RETURN)
+	if combo.GetValue() != "date" {
+		t.Fatalf("value %q, want the added option", combo.GetValue())
+	}
+}
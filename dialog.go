@@ -0,0 +1,242 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+)
+
+const (
+	DIALOG_TITLE_HEIGHT  = 30
+	DIALOG_RESULT_OK     = "ok"
+	DIALOG_RESULT_CANCEL = "cancel"
+)
+
+// Dialog is a Window with a title bar and a client area. The result passed
+// to Close, directly or through Widget.CloseWindow, is delivered to the close
+// handler set with SetCloseHandler.
+//
+// Enter activates the default button and Escape the cancel button. Without
+// such a button, or when it has no clicked handler, they close the dialog
+// with DIALOG_RESULT_OK and DIALOG_RESULT_CANCEL.
+type Dialog struct {
+	*Window
+	titleBar      *Label
+	closeButton   *Button
+	client        *Widget
+	defaultButton *Widget
+	cancelButton  *Widget
+	modal         bool
+	draggable     bool
+	dragging      bool
+	dragStart     structs.Point
+	dragOrigin    structs.Point
+}
+
+func NewDialog(manager *WindowManager, x, y, w, h float32, title string) *Dialog {
+	dialog := &Dialog{
+		Window:    NewWindow(manager, x, y, w, h),
+		draggable: true,
+	}
+	dialog.t = TYPE_DRAGGALE_DIALOG
+	dialog.I = dialog
+
+	width := dialog.rect.W
+	dialog.titleBar = NewLabel(dialog.Widget, 0, 0, float32(width), DIALOG_TITLE_HEIGHT)
+	dialog.titleBar.t = TYPE_TITLEBAR
+	dialog.titleBar.SetTextAlignH("left").SetBorder(10)
+	dialog.titleBar.SetText(title, false)
+
+	size := DIALOG_TITLE_HEIGHT - 6
+	dialog.closeButton = NewButton(dialog.titleBar.Widget, float32(width-size-3), 3, float32(size), float32(size))
+	dialog.closeButton.SetText("×", false)
+	dialog.closeButton.SetClickedHandler(func(*Widget, *structs.Point) {
+		dialog.cancel()
+	})
+
+	dialog.client = NewWidget(TYPE_VIEW_BASE, dialog.Widget, 0, DIALOG_TITLE_HEIGHT, float32(width), float32(dialog.rect.H-DIALOG_TITLE_HEIGHT))
+
+	return dialog
+}
+
+// Resize resizes the dialog and keeps the title bar, close button and client
+// area fitted to it.
+func (dialog *Dialog) Resize(w, h int) *Dialog {
+	dialog.Widget.Resize(w, h)
+	dialog.titleBar.Resize(w, DIALOG_TITLE_HEIGHT)
+	dialog.closeButton.Move(w-dialog.closeButton.rect.W-3, dialog.closeButton.rect.Y)
+	dialog.client.Resize(w, h-DIALOG_TITLE_HEIGHT)
+
+	return dialog
+}
+
+// GetClient returns the widget below the title bar that the content of the
+// dialog should be added to.
+func (dialog *Dialog) GetClient() *Widget {
+	return dialog.client
+}
+
+func (dialog *Dialog) GetTitleBar() *Label {
+	return dialog.titleBar
+}
+
+func (dialog *Dialog) SetTitle(title string) *Dialog {
+	dialog.titleBar.SetText(title, false)
+
+	return dialog
+}
+
+func (dialog *Dialog) GetTitle() string {
+	return dialog.titleBar.GetText()
+}
+
+func (dialog *Dialog) ShowCloseButton(visible bool) *Dialog {
+	dialog.closeButton.SetVisible(visible)
+
+	return dialog
+}
+
+// SetModal grabs the window manager so that no other window receives input
+// until the dialog is closed.
+func (dialog *Dialog) SetModal(modal bool) *Dialog {
+	if modal == dialog.modal {
+		return dialog
+	}
+
+	dialog.modal = modal
+	if modal {
+		dialog.manager.Grab(dialog.Window)
+	} else {
+		dialog.manager.Ungrab(dialog.Window)
+	}
+
+	return dialog
+}
+
+func (dialog *Dialog) IsModal() bool {
+	return dialog.modal
+}
+
+// SetDraggable controls whether the dialog can be moved by its title bar.
+func (dialog *Dialog) SetDraggable(draggable bool) *Dialog {
+	dialog.draggable = draggable
+	if draggable {
+		dialog.t = TYPE_DRAGGALE_DIALOG
+	} else {
+		dialog.t = TYPE_DIALOG
+	}
+
+	return dialog
+}
+
+func (dialog *Dialog) SetDefaultButton(button *Widget) *Dialog {
+	dialog.defaultButton = button
+
+	return dialog
+}
+
+func (dialog *Dialog) SetCancelButton(button *Widget) *Dialog {
+	dialog.cancelButton = button
+
+	return dialog
+}
+
+func (dialog *Dialog) activate(button *Widget, retInfo interface{}) {
+	if button != nil && button.enable && button.clickedHandler != nil {
+		button.onClicked(button.GetAbsPosition())
+	} else {
+		dialog.Close(retInfo)
+	}
+
+	return
+}
+
+func (dialog *Dialog) accept() {
+	dialog.activate(dialog.defaultButton, DIALOG_RESULT_OK)
+
+	return
+}
+
+func (dialog *Dialog) cancel() {
+	dialog.activate(dialog.cancelButton, DIALOG_RESULT_CANCEL)
+
+	return
+}
+
+func (dialog *Dialog) isInTitleBar(point *structs.Point) bool {
+	p := dialog.translatePoint(point)
+	if dialog.closeButton.visible && isPointInRect(dialog.titleBar.translatePoint(point), dialog.closeButton.rect) {
+		return false
+	}
+
+	return p.Y >= 0 && p.Y < DIALOG_TITLE_HEIGHT && p.X >= 0 && p.X < dialog.rect.W
+}
+
+func (dialog *Dialog) onPointerDown(point *structs.Point) {
+	if dialog.draggable && dialog.isInTitleBar(point) {
+		dialog.dragging = true
+		dialog.dragStart = structs.Point{X: point.X, Y: point.Y}
+		dialog.dragOrigin = structs.Point{X: dialog.rect.X, Y: dialog.rect.Y}
+	}
+	dialog.Window.onPointerDown(point)
+
+	return
+}
+
+func (dialog *Dialog) onPointerMove(point *structs.Point) {
+	if dialog.dragging && !dialog.isPointerDown() {
+		// The button was released over another window or outside the canvas.
+		dialog.dragging = false
+	}
+
+	if !dialog.dragging {
+		dialog.Window.onPointerMove(point)
+		return
+	}
+
+	x := dialog.dragOrigin.X + point.X - dialog.dragStart.X
+	y := dialog.dragOrigin.Y + point.Y - dialog.dragStart.Y
+	maxX := dialog.manager.GetWidth() - dialog.rect.W
+	maxY := dialog.manager.GetHeight() - DIALOG_TITLE_HEIGHT
+
+	if x > maxX {
+		x = maxX
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y > maxY {
+		y = maxY
+	}
+	if y < 0 {
+		y = 0
+	}
+
+	dialog.Move(x, y)
+	dialog.PostRedraw()
+
+	return
+}
+
+func (dialog *Dialog) onPointerUp(point *structs.Point) {
+	dialog.dragging = false
+	dialog.Window.onPointerUp(point)
+
+	return
+}
+
+func (dialog *Dialog) onKeyDown(code int) {
+	focus := dialog.focusWidget
+	if focus == nil || focus.t != TYPE_TEXT_AREA {
+		switch code {
+		case keyevent.DOM_VK_RETURN:
+			dialog.accept()
+			return
+		case keyevent.DOM_VK_ESCAPE:
+			dialog.cancel()
+			return
+		}
+	}
+	dialog.Window.onKeyDown(code)
+
+	return
+}
@@ -0,0 +1,42 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/event"
+	"testing"
+)
+
+func TestDialogDragReleasedOutside(t *testing.T) {
+	cases := []struct {
+		name string
+		x, y int
+	}{
+		{"over another window", 390, 40},
+		{"outside the canvas", -50, 150},
+	}
+	for _, c := range cases {
+		var dialog *Dialog
+		k := newScene(400, 300, func(win *Window) {
+			dialog = NewDialog(win.manager, 50, 50, 150, 100, "Drag")
+			NewWindow(win.manager, 350, 0, 50, 80)
+		})
+
+		k.pointer(event.EVENT_POINTER_DOWN, 60, 60)
+		k.pointer(event.EVENT_POINTER_MOVE, 200, 60)
+		k.pointer(event.EVENT_POINTER_MOVE, c.x, c.y)
+		k.pointer(event.EVENT_POINTER_UP, c.x, c.y)
+		x, y := dialog.rect.X, dialog.rect.Y
+
+		k.pointer(event.EVENT_POINTER_MOVE, x+70, y+60)
+		k.pointer(event.EVENT_POINTER_MOVE, x+20, y+80)
+		if dialog.rect.X != x || dialog.rect.Y != y {
+			t.Errorf("%s: hovering after the release moved the dialog from %d,%d to %d,%d",
+				c.name, x, y, dialog.rect.X, dialog.rect.Y)
+		}
+
+		k.drag(x+10, y+10, x+30, y+20)
+		if dialog.rect.X != x+20 || dialog.rect.Y != y+10 {
+			t.Errorf("%s: dragging again moved the dialog to %d,%d, want %d,%d",
+				c.name, dialog.rect.X, dialog.rect.Y, x+20, y+10)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+// Package gwk is a canvas based widget toolkit for GopherJS.
+//
+// An application creates a WindowManager for a canvas (NewApplication does
+// this for a canvas element by id, NewHeadlessWindowManager for tests), opens
+// one or more windows with NewWindow and builds a widget tree inside them with
+// the NewXxx constructors, each taking the parent widget and a rectangle.
+// Coordinates in (0, 1) are treated as fractions of the parent's size.
+//
+// Widgets are configured through exported chained setters (SetText,
+// SetTextColor, SetEnable, Move, Resize, ...) and observed through handlers
+// (SetClickedHandler, SetChangedHandler, SetKeyDownHandler, ...). Windows are
+// closed with Window.Close and made modal with Window.Grab and
+// WindowManager.Grab.
+//
+// Event and paint hooks (onPointerDown, paintSelf, relayout, ...) stay
+// unexported; widgets in this package override them through the I field of
+// Widget, which always points at the outermost widget type.
+package gwk
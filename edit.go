@@ -0,0 +1,479 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	EDIT_SELECTION_COLOR = "#B4D5FE"
+	EDIT_PASSWORD_CHAR   = '*'
+)
+
+// Edit is a single line text editor. Positions (caret, selection) are
+// counted in runes. The text is kept in the embedded Label, so SetText,
+// GetText and the changed handler behave as they do for labels.
+type Edit struct {
+	*Label
+	caret     int
+	anchor    int
+	maxLength int
+	password  bool
+	selecting bool
+	scrollX   int
+}
+
+func NewEdit(parent *Widget, x, y, w, h float32) *Edit {
+	edit := &Edit{
+		Label: NewLabel(parent, x, y, w, h),
+	}
+	edit.t = TYPE_EDIT
+	edit.cursor = "text"
+	edit.textAlignH = "left"
+	edit.leftBorder = 4
+	edit.rightBorder = 4
+	edit.leftMargin = 4
+	edit.I = edit
+
+	return edit
+}
+
+func (edit *Edit) SetText(str string, notify bool) *Edit {
+	edit.setText(str, notify)
+	edit.caret = len(edit.getRunes())
+	edit.anchor = edit.caret
+	edit.scrollX = 0
+
+	return edit
+}
+
+func (edit *Edit) setText(str string, notify bool) {
+	if runes := []rune(str); edit.maxLength > 0 && len(runes) > edit.maxLength {
+		str = string(runes[:edit.maxLength])
+	}
+
+	edit.Label.SetText(str, notify)
+	edit.PostRedraw()
+
+	return
+}
+
+// SetMaxLength limits the text to maxLength runes, 0 means unlimited.
+func (edit *Edit) SetMaxLength(maxLength int) *Edit {
+	edit.maxLength = maxLength
+	if maxLength > 0 && len(edit.getRunes()) > maxLength {
+		edit.SetText(edit.text, false)
+	}
+
+	return edit
+}
+
+func (edit *Edit) GetMaxLength() int {
+	return edit.maxLength
+}
+
+// SetPassword masks the text with EDIT_PASSWORD_CHAR and disables copy and
+// cut.
+func (edit *Edit) SetPassword(password bool) *Edit {
+	edit.password = password
+
+	return edit
+}
+
+func (edit *Edit) IsPassword() bool {
+	return edit.password
+}
+
+func (edit *Edit) GetCaret() int {
+	return edit.caret
+}
+
+func (edit *Edit) SetCaret(caret int) *Edit {
+	edit.moveCaret(caret, false)
+
+	return edit
+}
+
+// Select selects the runes in [start, end) and puts the caret at end.
+func (edit *Edit) Select(start, end int) *Edit {
+	edit.moveCaret(start, false)
+	edit.moveCaret(end, true)
+
+	return edit
+}
+
+func (edit *Edit) SelectAll() *Edit {
+	return edit.Select(0, len(edit.getRunes()))
+}
+
+// GetSelection returns the selected range, start == end when nothing is
+// selected.
+func (edit *Edit) GetSelection() (start, end int) {
+	edit.clampCaret()
+	if edit.anchor < edit.caret {
+		return edit.anchor, edit.caret
+	}
+
+	return edit.caret, edit.anchor
+}
+
+func (edit *Edit) GetSelectedText() string {
+	start, end := edit.GetSelection()
+
+	return string(edit.getRunes()[start:end])
+}
+
+func (edit *Edit) Copy() *Edit {
+	if text := edit.GetSelectedText(); len(text) > 0 && !edit.password {
+		GetWindowManagerInstance().SetClipboardText(text)
+	}
+
+	return edit
+}
+
+func (edit *Edit) Cut() *Edit {
+	if start, end := edit.GetSelection(); start != end && !edit.password {
+		edit.Copy()
+		edit.insertText("")
+	}
+
+	return edit
+}
+
+func (edit *Edit) Paste() *Edit {
+	text := GetWindowManagerInstance().GetClipboardText()
+	text = strings.NewReplacer("\r", "", "\n", " ").Replace(text)
+	if len(text) > 0 {
+		edit.insertText(text)
+	}
+
+	return edit
+}
+
+func (edit *Edit) getRunes() []rune {
+	return []rune(edit.text)
+}
+
+func (edit *Edit) getDisplayRunes() []rune {
+	runes := edit.getRunes()
+	if edit.password {
+		for i := range runes {
+			runes[i] = EDIT_PASSWORD_CHAR
+		}
+	}
+
+	return runes
+}
+
+func (edit *Edit) clampCaret() {
+	n := len(edit.getRunes())
+	if edit.caret > n {
+		edit.caret = n
+	}
+
+	if edit.anchor > n {
+		edit.anchor = n
+	}
+
+	return
+}
+
+func (edit *Edit) moveCaret(caret int, extend bool) {
+	n := len(edit.getRunes())
+	if caret < 0 {
+		caret = 0
+	} else if caret > n {
+		caret = n
+	}
+
+	edit.caret = caret
+	if !extend {
+		edit.anchor = caret
+	}
+	edit.PostRedraw()
+
+	return
+}
+
+// insertText replaces the selection with str, truncating str to what
+// maxLength still allows, and notifies the changed handler.
+func (edit *Edit) insertText(str string) {
+	runes := edit.getRunes()
+	start, end := edit.GetSelection()
+	insert := []rune(str)
+
+	if edit.maxLength > 0 {
+		room := edit.maxLength - len(runes) + end - start
+		if room < 0 {
+			room = 0
+		}
+		if len(insert) > room {
+			insert = insert[:room]
+		}
+	}
+
+	if len(insert) == 0 && start == end {
+		return
+	}
+
+	text := string(runes[:start]) + string(insert) + string(runes[end:])
+	edit.caret = start + len(insert)
+	edit.anchor = edit.caret
+	edit.setText(text, true)
+
+	return
+}
+
+func (edit *Edit) findWordStart(pos int) int {
+	runes := edit.getRunes()
+	for pos > 0 && unicode.IsSpace(runes[pos-1]) {
+		pos--
+	}
+
+	for pos > 0 && !unicode.IsSpace(runes[pos-1]) {
+		pos--
+	}
+
+	return pos
+}
+
+func (edit *Edit) findWordEnd(pos int) int {
+	runes := edit.getRunes()
+	for pos < len(runes) && unicode.IsSpace(runes[pos]) {
+		pos++
+	}
+
+	for pos < len(runes) && !unicode.IsSpace(runes[pos]) {
+		pos++
+	}
+
+	return pos
+}
+
+func (edit *Edit) measure(context canvas.Canvas2D, runes []rune) float64 {
+	if len(runes) == 0 {
+		return 0
+	}
+
+	return context.MeasureText(string(runes)).Width
+}
+
+// getCaretAt returns the rune index closest to point.
+func (edit *Edit) getCaretAt(point *structs.Point) int {
+	p := edit.translatePoint(point)
+	x := float64(p.X - edit.leftBorder + edit.scrollX)
+	runes := edit.getDisplayRunes()
+
+	context := edit.getCanvas2D()
+	context.Save()
+	context.SetFont(edit.getFont())
+	defer context.Restore()
+
+	last := 0.0
+	for i := range runes {
+		width := edit.measure(context, runes[:i+1])
+		if x < (last+width)/2 {
+			return i
+		}
+		last = width
+	}
+
+	return len(runes)
+}
+
+func (edit *Edit) ensureCaretVisible(context canvas.Canvas2D, runes []rune, width int) {
+	caretX := int(edit.measure(context, runes[:edit.caret]))
+	textWidth := int(edit.measure(context, runes))
+
+	if caretX-edit.scrollX > width {
+		edit.scrollX = caretX - width
+	}
+
+	if caretX < edit.scrollX {
+		edit.scrollX = caretX
+	}
+
+	if textWidth-edit.scrollX < width {
+		edit.scrollX = int(math.Max(0, float64(textWidth-width)))
+	}
+
+	return
+}
+
+func (edit *Edit) relayout(context canvas.Canvas2D, force bool) {
+	edit.needRelayout = false
+
+	return
+}
+
+func (edit *Edit) paintSelf(context canvas.Canvas2D) {
+	edit.clampCaret()
+	runes := edit.getDisplayRunes()
+	width := edit.rect.W - edit.leftBorder - edit.rightBorder
+	height := edit.rect.H
+	y := height >> 1
+
+	context.Save()
+	context.SetFont(edit.getFont())
+	edit.ensureCaretVisible(context, runes, width)
+
+	context.BeginPath()
+	context.Rect(float64(edit.leftBorder), 0, float64(width), float64(height))
+	context.Clip()
+
+	x := float64(edit.leftBorder - edit.scrollX)
+	if start, end := edit.GetSelection(); start != end {
+		x0 := edit.measure(context, runes[:start])
+		x1 := edit.measure(context, runes[:end])
+		context.SetFillStyle(EDIT_SELECTION_COLOR)
+		context.FillRect(x+x0, float64(edit.topBorder), x1-x0, float64(height-edit.topBorder-edit.bottomBorder))
+	}
+
+	context.SetFillStyle(edit.GetTextColor())
+	if len(runes) > 0 {
+		context.SetTextAlign("left")
+		context.SetTextBaseline("middle")
+		context.FillText(string(runes), x, float64(y), -1)
+	}
+
+	if edit.editing {
+		caretX := math.Floor(x + edit.measure(context, runes[:edit.caret]))
+		caretH := edit.fontSize + 4
+		context.FillRect(caretX, float64(y-caretH/2), 1, float64(caretH))
+	}
+	context.Restore()
+
+	return
+}
+
+func (edit *Edit) onPointerDown(point *structs.Point) {
+	if !edit.enable {
+		return
+	}
+
+	if window := edit.GetWindow(); window != nil {
+		window.SetFocus(edit.Widget)
+	}
+
+	edit.moveCaret(edit.getCaretAt(point), edit.isShiftDown())
+	edit.selecting = true
+	edit.Widget.onPointerDown(point)
+
+	return
+}
+
+func (edit *Edit) onPointerMove(point *structs.Point) {
+	if edit.selecting && edit.isPointerDown() {
+		edit.moveCaret(edit.getCaretAt(point), true)
+	}
+
+	return
+}
+
+func (edit *Edit) onPointerUp(point *structs.Point) {
+	edit.selecting = false
+	edit.Widget.onPointerUp(point)
+
+	return
+}
+
+func (edit *Edit) onDoubleClick(point *structs.Point) {
+	if !edit.enable {
+		return
+	}
+
+	caret := edit.getCaretAt(point)
+	runes := edit.getRunes()
+	start, end := caret, caret
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start--
+	}
+
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+	edit.Select(start, end)
+	edit.Widget.onDoubleClick(point)
+
+	return
+}
+
+func (edit *Edit) onKeyDown(code int) {
+	if !edit.enable {
+		return
+	}
+
+	n := len(edit.getRunes())
+	shift := edit.isShiftDown()
+	ctrl := edit.isCtrlDown()
+	start, end := edit.GetSelection()
+
+	switch code {
+	case keyevent.DOM_VK_LEFT:
+		if start != end && !shift {
+			edit.moveCaret(start, false)
+		} else if ctrl {
+			edit.moveCaret(edit.findWordStart(edit.caret), shift)
+		} else {
+			edit.moveCaret(edit.caret-1, shift)
+		}
+	case keyevent.DOM_VK_RIGHT:
+		if start != end && !shift {
+			edit.moveCaret(end, false)
+		} else if ctrl {
+			edit.moveCaret(edit.findWordEnd(edit.caret), shift)
+		} else {
+			edit.moveCaret(edit.caret+1, shift)
+		}
+	case keyevent.DOM_VK_HOME:
+		edit.moveCaret(0, shift)
+	case keyevent.DOM_VK_END:
+		edit.moveCaret(n, shift)
+	case keyevent.DOM_VK_BACK_SPACE:
+		if start == end && edit.caret > 0 {
+			edit.anchor = edit.caret - 1
+		}
+		edit.insertText("")
+	case keyevent.DOM_VK_DELETE:
+		if start == end && edit.caret < n {
+			edit.anchor = edit.caret + 1
+		}
+		edit.insertText("")
+	case keyevent.DOM_VK_A:
+		if ctrl {
+			edit.SelectAll()
+		}
+	case keyevent.DOM_VK_C:
+		if ctrl {
+			edit.Copy()
+		}
+	case keyevent.DOM_VK_X:
+		if ctrl {
+			edit.Cut()
+		}
+	case keyevent.DOM_VK_V:
+		if ctrl {
+			edit.Paste()
+		}
+	}
+
+	if edit.keyDownHandler != nil {
+		edit.keyDownHandler(code)
+	}
+
+	return
+}
+
+func (edit *Edit) onKeyPress(char rune) {
+	if !edit.enable || edit.isCtrlDown() || !unicode.IsPrint(char) {
+		return
+	}
+
+	edit.insertText(string(char))
+
+	return
+}
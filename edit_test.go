@@ -0,0 +1,192 @@
+package gwk
+
+import (
+	"bytes"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"testing"
+)
+
+// newEditScene returns a focused, empty Edit at 10,10 in a 300x60 window.
+func newEditScene() (typist, *Edit) {
+	var edit *Edit
+	k := newScene(300, 60, func(win *Window) {
+		edit = NewEdit(win.Widget, 10, 10, 200, 30)
+	})
+	k.click(200, 25)
+
+	return k, edit
+}
+
+func TestEditCaretMovement(t *testing.T) {
+	k, edit := newEditScene()
+	k.typeText("hello big world")
+
+	steps := []struct {
+		name  string
+		press func()
+		caret int
+	}{
+		{"typed", func() {}, 15},
+		{"left", func() { k.key(keyevent.DOM_VK_LEFT) }, 14},
+		{"ctrl+left", func() { k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_LEFT) }, 10},
+		{"ctrl+left again", func() { k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_LEFT) }, 6},
+		{"home", func() { k.key(keyevent.DOM_VK_HOME) }, 0},
+		{"left at start", func() { k.key(keyevent.DOM_VK_LEFT) }, 0},
+		{"right", func() { k.key(keyevent.DOM_VK_RIGHT, keyevent.DOM_VK_RIGHT) }, 2},
+		{"end", func() { k.key(keyevent.DOM_VK_END) }, 15},
+		{"right at end", func() { k.key(keyevent.DOM_VK_RIGHT) }, 15},
+		{"click start", func() { k.click(12, 25) }, 0},
+	}
+	for _, step := range steps {
+		step.press()
+		if caret := edit.GetCaret(); caret != step.caret {
+			t.Errorf("%s: caret %d, want %d", step.name, caret, step.caret)
+		}
+		if start, end := edit.GetSelection(); start != end {
+			t.Errorf("%s: selection [%d, %d), want none", step.name, start, end)
+		}
+	}
+}
+
+func TestEditShiftSelection(t *testing.T) {
+	k, edit := newEditScene()
+	k.typeText("hello world")
+
+	k.key(keyevent.DOM_VK_HOME)
+	k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_RIGHT, keyevent.DOM_VK_RIGHT, keyevent.DOM_VK_RIGHT)
+	if text := edit.GetSelectedText(); text != "hel" {
+		t.Errorf("shift+right: selected %q, want %q", text, "hel")
+	}
+
+	k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_LEFT)
+	if text := edit.GetSelectedText(); text != "he" {
+		t.Errorf("shift+left: selected %q, want %q", text, "he")
+	}
+
+	k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_END)
+	if text := edit.GetSelectedText(); text != "hello world" {
+		t.Errorf("shift+end: selected %q, want %q", text, "hello world")
+	}
+
+	k.key(keyevent.DOM_VK_LEFT)
+	if start, end := edit.GetSelection(); start != 0 || end != 0 {
+		t.Errorf("left collapses to [%d, %d), want [0, 0)", start, end)
+	}
+
+	k.typeText("Oh, ")
+	if text := edit.GetText(); text != "Oh, hello world" {
+		t.Errorf("text %q after typing at the start", text)
+	}
+
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_A)
+	k.typeText("x")
+	if text := edit.GetText(); text != "x" {
+		t.Errorf("typing over ctrl+a left %q", text)
+	}
+}
+
+func TestEditClipboard(t *testing.T) {
+	k, edit := newEditScene()
+	var changes []string
+	edit.SetChangedHandler(func(value interface{}) {
+		changes = append(changes, value.(string))
+	})
+	k.typeText("hello world")
+	typed := len(changes)
+
+	k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_LEFT, keyevent.DOM_VK_LEFT, keyevent.DOM_VK_LEFT,
+		keyevent.DOM_VK_LEFT, keyevent.DOM_VK_LEFT)
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_C)
+	if clip := k.m.GetClipboardText(); clip != "world" {
+		t.Errorf("ctrl+c copied %q, want %q", clip, "world")
+	}
+	if text := edit.GetText(); text != "hello world" || len(changes) != typed {
+		t.Errorf("ctrl+c changed the text to %q", text)
+	}
+
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_X)
+	if text := edit.GetText(); text != "hello " {
+		t.Errorf("ctrl+x left %q, want %q", text, "hello ")
+	}
+	if len(changes) != typed+1 || changes[len(changes)-1] != "hello " {
+		t.Errorf("ctrl+x notified %q", changes[typed:])
+	}
+
+	k.key(keyevent.DOM_VK_HOME)
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_V)
+	if text, caret := edit.GetText(), edit.GetCaret(); text != "worldhello " || caret != 5 {
+		t.Errorf("ctrl+v: %q caret %d, want %q caret 5", text, caret, "worldhello ")
+	}
+
+	k.m.SetClipboardText("one\r\ntwo\n")
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_V)
+	if text := edit.GetText(); text != "worldone two hello " {
+		t.Errorf("pasting lines gave %q", text)
+	}
+}
+
+func TestEditMaxLength(t *testing.T) {
+	k, edit := newEditScene()
+	edit.SetMaxLength(5)
+
+	k.typeText("abcdefg")
+	if text := edit.GetText(); text != "abcde" {
+		t.Errorf("typing past the limit gave %q", text)
+	}
+
+	edit.Select(1, 3)
+	k.m.SetClipboardText("XYZ")
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_V)
+	if text, caret := edit.GetText(), edit.GetCaret(); text != "aXYde" || caret != 3 {
+		t.Errorf("pasting over a selection gave %q caret %d, want %q caret 3", text, caret, "aXYde")
+	}
+
+	k.key(keyevent.DOM_VK_BACK_SPACE)
+	k.typeText("12")
+	if text := edit.GetText(); text != "aX1de" {
+		t.Errorf("typing after backspace gave %q", text)
+	}
+
+	edit.SetMaxLength(3)
+	if text := edit.GetText(); text != "aX1" {
+		t.Errorf("lowering the limit left %q", text)
+	}
+
+	edit.SetMaxLength(0).SetText("no limit any more", false)
+	if text := edit.GetText(); text != "no limit any more" {
+		t.Errorf("SetText without a limit gave %q", text)
+	}
+}
+
+// renderEdit returns the frame of an unfocused Edit showing text.
+func renderEdit(text string, password bool) []byte {
+	k := newScene(220, 50, func(win *Window) {
+		NewEdit(win.Widget, 10, 10, 200, 30).SetPassword(password).SetText(text, false)
+	})
+
+	return append([]byte(nil), k.m.Snapshot().Pix...)
+}
+
+func TestEditPassword(t *testing.T) {
+	k, edit := newEditScene()
+	edit.SetPassword(true)
+	k.typeText("secret")
+	if text := edit.GetText(); text != "secret" {
+		t.Errorf("GetText of a password edit gave %q", text)
+	}
+
+	k.m.SetClipboardText("kept")
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_A, keyevent.DOM_VK_C)
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_X)
+	if clip, text := k.m.GetClipboardText(), edit.GetText(); clip != "kept" || text != "secret" {
+		t.Errorf("copy and cut of a password: clipboard %q text %q", clip, text)
+	}
+
+	masked := renderEdit("abc", true)
+	if !bytes.Equal(masked, renderEdit("***", false)) {
+		t.Errorf("password edit does not show one mask character per rune")
+	}
+	if bytes.Equal(masked, renderEdit("abc", false)) {
+		t.Errorf("password edit shows its text")
+	}
+}
@@ -0,0 +1,114 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+)
+
+const (
+	FILENAME_EDIT_BUTTON_WIDTH = 28
+	FILENAME_EDIT_SPACING      = 4
+)
+
+// FileBrowseHandler is called when the browse button of a FilenameEdit is
+// clicked, with the name typed so far. It calls done with the name the user
+// chose, or not at all when the user cancels.
+type FileBrowseHandler func(current string, done func(name string))
+
+// FilenameEdit is an Edit for a file name with a browse button after it. The
+// toolkit has no file dialog, so the button calls the browse handler and is
+// disabled while there is none. The changed handler receives the new name.
+type FilenameEdit struct {
+	*Widget
+	edit     *Edit
+	button   *Button
+	onBrowse FileBrowseHandler
+}
+
+func NewFilenameEdit(parent *Widget, x, y, w, h float32) *FilenameEdit {
+	filenameEdit := &FilenameEdit{
+		Widget: NewWidget(TYPE_FILENAME_EDIT, parent, x, y, w, h),
+	}
+	filenameEdit.I = filenameEdit
+
+	filenameEdit.edit = NewEdit(filenameEdit.Widget, 0, 0, 0, 0)
+	filenameEdit.edit.SetChangedHandler(func(interface{}) {
+		if filenameEdit.onChanged != nil {
+			filenameEdit.onChanged(filenameEdit.edit.GetText())
+		}
+	})
+
+	filenameEdit.button = NewButton(filenameEdit.Widget, 0, 0, 0, 0)
+	filenameEdit.button.SetText("...", false)
+	filenameEdit.button.SetEnable(false)
+	filenameEdit.button.SetClickedHandler(func(*Widget, *structs.Point) {
+		filenameEdit.Browse()
+	})
+
+	filenameEdit.layout()
+
+	return filenameEdit
+}
+
+func (filenameEdit *FilenameEdit) GetEdit() *Edit {
+	return filenameEdit.edit
+}
+
+func (filenameEdit *FilenameEdit) GetFilename() string {
+	return filenameEdit.edit.GetText()
+}
+
+func (filenameEdit *FilenameEdit) SetFilename(name string, notify bool) *FilenameEdit {
+	filenameEdit.edit.SetText(name, notify)
+
+	return filenameEdit
+}
+
+// SetBrowseHandler sets the handler asking the user for a file, enabling the
+// browse button.
+func (filenameEdit *FilenameEdit) SetBrowseHandler(onBrowse FileBrowseHandler) *FilenameEdit {
+	filenameEdit.onBrowse = onBrowse
+	filenameEdit.button.SetEnable(onBrowse != nil)
+
+	return filenameEdit
+}
+
+// Browse asks the browse handler for a file and puts the name chosen in the
+// edit.
+func (filenameEdit *FilenameEdit) Browse() *FilenameEdit {
+	if filenameEdit.onBrowse == nil || !filenameEdit.enable {
+		return filenameEdit
+	}
+
+	filenameEdit.onBrowse(filenameEdit.edit.GetText(), func(name string) {
+		filenameEdit.edit.SetText(name, true)
+		filenameEdit.PostRedraw()
+	})
+
+	return filenameEdit
+}
+
+func (filenameEdit *FilenameEdit) layout() {
+	w := filenameEdit.rect.W
+	h := filenameEdit.rect.H
+	buttonWidth := int(math.Min(FILENAME_EDIT_BUTTON_WIDTH, float64(w)))
+
+	filenameEdit.edit.Move(0, 0)
+	filenameEdit.edit.Resize(int(math.Max(0, float64(w-buttonWidth-FILENAME_EDIT_SPACING))), h)
+	filenameEdit.button.Move(w-buttonWidth, 0)
+	filenameEdit.button.Resize(buttonWidth, h)
+
+	return
+}
+
+func (filenameEdit *FilenameEdit) relayout(context canvas.Canvas2D, force bool) {
+	filenameEdit.layout()
+	filenameEdit.Widget.relayout(context, force)
+
+	return
+}
+
+func (filenameEdit *FilenameEdit) paintBackground(context canvas.Canvas2D) {
+	return
+}
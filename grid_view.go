@@ -0,0 +1,606 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/image"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+)
+
+const (
+	GRID_VIEW_CELL_WIDTH  = 96
+	GRID_VIEW_CELL_HEIGHT = 96
+	GRID_VIEW_SPACING     = 8
+	GRID_VIEW_BAND_DRAG   = 5
+	GRID_VIEW_BAND_FILL   = "rgba(51,153,255,0.2)"
+	GRID_VIEW_BAND_LINE   = "#3399FF"
+)
+
+// GridDataSource supplies the cells of a GridView. RenderItem is called when
+// a recycled item is bound to cell index, and for every visible cell after
+// Reload. It typically sets the image and text of the item.
+type GridDataSource interface {
+	GetCount() int
+	RenderItem(item *GridItem, index int)
+}
+
+// GridItem is the ImageText showing a cell of a GridView, with the image
+// above the text. Items are recycled as the view scrolls, so they should not
+// keep per-cell state of their own.
+type GridItem struct {
+	*ImageText
+	view  *GridView
+	index int
+}
+
+func (item *GridItem) GetIndex() int {
+	return item.index
+}
+
+func (item *GridItem) GetGridView() *GridView {
+	return item.view
+}
+
+// paintBackground fills a selected item and frames the current one while the
+// view has the focus.
+func (item *GridItem) paintBackground(context canvas.Canvas2D) {
+	view := item.view
+	style := item.getStyle("")
+	w := float64(item.rect.W)
+	h := float64(item.rect.H)
+
+	if item.selected {
+		context.SetFillStyle(colorOr(style.FillColor, LIST_ITEM_SELECTED_COLOR))
+		context.FillRect(0, 0, w, h)
+	} else if len(style.FillColor) > 0 {
+		context.SetFillStyle(style.FillColor)
+		context.FillRect(0, 0, w, h)
+	}
+
+	if view.editing && view.current == item.index && view.selectionMode != LIST_SELECTION_NONE {
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+		context.BeginPath()
+		context.Rect(0.5, 0.5, w-1, h-1)
+		context.Stroke()
+	}
+
+	return
+}
+
+// gridViewContent is the scrolled child of a GridView. It spans all rows of
+// cells but only holds the items of the visible ones, and paints the rubber
+// band over them.
+type gridViewContent struct {
+	*Widget
+	view *GridView
+}
+
+// GridView shows the cells of a GridDataSource in rows of fixed size cells,
+// as many per row as fit the width, scrolled vertically. Like ListView only
+// the visible cells get an item widget. Besides Ctrl and Shift clicks, in
+// LIST_SELECTION_MULTIPLE mode dragging from the space between the cells, or
+// from a cell once the pointer moved GRID_VIEW_BAND_DRAG pixels, selects the
+// cells touched by the rubber band. The changed handler receives the sorted
+// []int of selected cells.
+type GridView struct {
+	*ScrollView
+	itemSelection
+	itemView
+	content    *gridViewContent
+	source     GridDataSource
+	cellWidth  int
+	cellHeight int
+	spacing    int
+	columns    int
+	banding    bool
+	bandArmed  bool
+	bandStart  structs.Point
+	bandEnd    structs.Point
+	bandBase   map[int]bool
+}
+
+func NewGridView(parent *Widget, x, y, w, h float32) *GridView {
+	view := &GridView{
+		ScrollView:    NewScrollView(parent, x, y, w, h),
+		itemSelection: newItemSelection(),
+		cellWidth:     GRID_VIEW_CELL_WIDTH,
+		cellHeight:    GRID_VIEW_CELL_HEIGHT,
+		spacing:       GRID_VIEW_SPACING,
+		columns:       1,
+	}
+	view.itemView = newItemView(view.ScrollView, &view.itemSelection, view)
+	view.t = TYPE_GRID_VIEW
+	view.SetScrollType(SCROLL_TYPE_V)
+	view.I = view
+
+	view.content = &gridViewContent{
+		Widget: NewWidget(TYPE_VIEW_BASE, view.Widget, 0, 0, 0, 0),
+		view:   view,
+	}
+	view.content.I = view.content
+	view.updateLayout()
+
+	return view
+}
+
+func (view *GridView) SetDataSource(source GridDataSource) *GridView {
+	view.source = source
+	view.resetItems()
+
+	return view.Reload()
+}
+
+func (view *GridView) GetDataSource() GridDataSource {
+	return view.source
+}
+
+// Reload rereads the cell count and renders the visible cells again. Call it
+// whenever the data behind the source changes.
+func (view *GridView) Reload() *GridView {
+	view.reloadItems()
+
+	return view
+}
+
+// SetCellSize sets the size of every cell, including the item border.
+func (view *GridView) SetCellSize(w, h int) *GridView {
+	view.cellWidth = int(math.Max(1, float64(w)))
+	view.cellHeight = int(math.Max(1, float64(h)))
+
+	return view.Reload()
+}
+
+func (view *GridView) GetCellSize() (int, int) {
+	return view.cellWidth, view.cellHeight
+}
+
+// SetSpacing sets the gap between the cells and around them.
+func (view *GridView) SetSpacing(spacing int) *GridView {
+	view.spacing = int(math.Max(0, float64(spacing)))
+
+	return view.Reload()
+}
+
+func (view *GridView) GetSpacing() int {
+	return view.spacing
+}
+
+// GetColumns returns the number of cells per row for the current width.
+func (view *GridView) GetColumns() int {
+	return view.columns
+}
+
+func (view *GridView) SetSelectionMode(mode ListSelectionMode) *GridView {
+	if mode == LIST_SELECTION_RADIO {
+		mode = LIST_SELECTION_SINGLE
+	}
+	view.setMode(mode)
+	view.PostRedraw()
+
+	return view
+}
+
+// SetActivatedHandler sets the handler called when a cell is double clicked
+// or Enter is pressed on it.
+func (view *GridView) SetActivatedHandler(onActivated ListActivatedHandler) *GridView {
+	view.onActivated = onActivated
+
+	return view
+}
+
+func (view *GridView) SetCurrent(index int) *GridView {
+	view.setCurrent(index)
+
+	return view
+}
+
+// SetSelected selects or deselects a cell. Outside of
+// LIST_SELECTION_MULTIPLE mode selecting a cell deselects the others.
+func (view *GridView) SetSelected(index int, selected, notify bool) *GridView {
+	view.selectItem(index, selected, notify)
+
+	return view
+}
+
+func (view *GridView) SelectAll(notify bool) *GridView {
+	view.selectAllItems(notify)
+
+	return view
+}
+
+func (view *GridView) ClearSelection(notify bool) *GridView {
+	view.clearSelection(notify)
+
+	return view
+}
+
+// EnsureVisible scrolls the least needed to show the row of cell index.
+func (view *GridView) EnsureVisible(index int) *GridView {
+	view.ensureVisible(index)
+
+	return view
+}
+
+func (view *GridView) getCount() int {
+	if view.source == nil {
+		return 0
+	}
+
+	return view.source.GetCount()
+}
+
+// getItemSpan returns the row of cell index with the spacing around it.
+func (view *GridView) getItemSpan(index int) (top, bottom int) {
+	rect := view.getCellRect(index)
+
+	return rect.Y - view.spacing, rect.Y + rect.H + view.spacing
+}
+
+func (view *GridView) getPageHeight() int {
+	return view.getViewHeight()
+}
+
+func (view *GridView) getPageSize() int {
+	return view.columns * int(math.Max(1, float64(view.getViewHeight()/view.getRowHeight())))
+}
+
+func (view *GridView) getRowHeight() int {
+	return view.cellHeight + view.spacing
+}
+
+// getCellRect returns the rectangle of cell index in content coordinates.
+func (view *GridView) getCellRect(index int) *structs.Rect {
+	row := index / view.columns
+	col := index % view.columns
+
+	return structs.NewRect(
+		view.spacing+col*(view.cellWidth+view.spacing),
+		view.spacing+row*view.getRowHeight(),
+		view.cellWidth,
+		view.cellHeight)
+}
+
+func (view *GridView) getIndexAtContentPoint(p *structs.Point) int {
+	if p.X < view.spacing || p.Y < view.spacing {
+		return -1
+	}
+
+	col := (p.X - view.spacing) / (view.cellWidth + view.spacing)
+	row := (p.Y - view.spacing) / view.getRowHeight()
+	if col >= view.columns || (p.X-view.spacing)%(view.cellWidth+view.spacing) >= view.cellWidth ||
+		(p.Y-view.spacing)%view.getRowHeight() >= view.cellHeight {
+		return -1
+	}
+
+	index := row*view.columns + col
+	if index >= view.getCount() {
+		return -1
+	}
+
+	return index
+}
+
+// getVisibleRange returns the cells from first up to, excluding, last whose
+// rows intersect the view.
+func (view *GridView) getVisibleRange() (first, last int) {
+	top := int(view.getYOffset())
+	firstRow := int(math.Max(0, float64((top-view.spacing)/view.getRowHeight())))
+	lastRow := (top+view.getViewHeight())/view.getRowHeight() + 1
+	first = firstRow * view.columns
+	last = int(math.Min(float64(view.getCount()), float64(lastRow*view.columns)))
+
+	return first, last
+}
+
+// updateLayout fits as many columns as the width allows, sizes the content
+// child to all rows and refreshes the scroll bar. When the cells reflow the
+// current one is scrolled back into view.
+func (view *GridView) updateLayout() {
+	width := view.rect.W - int(view.scrollBarSize)
+	columns := int(math.Max(1, float64((width-view.spacing)/(view.cellWidth+view.spacing))))
+	reflowed := columns != view.columns
+	if reflowed {
+		view.columns = columns
+		view.dirty = true
+	}
+
+	rows := (view.getCount() + columns - 1) / columns
+	height := view.spacing + rows*view.getRowHeight()
+	view.resizeContent(view.content.Widget, width, int(math.Max(float64(height), float64(view.rect.H))))
+
+	if reflowed && view.current >= 0 {
+		view.EnsureVisible(view.current)
+	}
+
+	return
+}
+
+func (view *GridView) relayout(context canvas.Canvas2D, force bool) {
+	if view.needRelayout || force {
+		view.updateLayout()
+	}
+
+	return
+}
+
+// bindItems recycles the items of cells that scrolled out and binds items to
+// the cells that scrolled in.
+func (view *GridView) bindItems() {
+	first, last := view.getVisibleRange()
+	view.recycleItems(func(key interface{}) bool {
+		return key.(int) >= first && key.(int) < last
+	})
+
+	for i := first; i < last; i++ {
+		recycled, bound := view.bindItem(i, view.newItem)
+		item := recycled.(*GridItem)
+		item.index = i
+
+		if !bound || view.dirty {
+			rect := view.getCellRect(i)
+			item.Move(rect.X, rect.Y)
+			item.Resize(rect.W, rect.H)
+			item.SetVisible(true)
+			view.source.RenderItem(item, i)
+		}
+		item.selected = view.selected[i]
+	}
+	view.dirty = false
+
+	return
+}
+
+func (view *GridView) newItem() viewItem {
+	item := &GridItem{
+		ImageText: NewImageText(view.content.Widget, 0, 0, 0, 0),
+		view:      view,
+	}
+	item.t = TYPE_GRID_ITEM
+	item.selectable = true
+	item.SetVertical(true)
+	item.SetFgImageDisplay(image.DISPLAY_AUTO_SIZE_DOWN)
+	item.I = item
+
+	return item
+}
+
+// updateBand selects the cells touched by the rubber band, on top of the
+// selection kept from before the drag.
+func (view *GridView) updateBand() {
+	x0 := int(math.Min(float64(view.bandStart.X), float64(view.bandEnd.X)))
+	y0 := int(math.Min(float64(view.bandStart.Y), float64(view.bandEnd.Y)))
+	x1 := int(math.Max(float64(view.bandStart.X), float64(view.bandEnd.X)))
+	y1 := int(math.Max(float64(view.bandStart.Y), float64(view.bandEnd.Y)))
+
+	selected := make(map[int]bool, len(view.bandBase))
+	for index := range view.bandBase {
+		selected[index] = true
+	}
+
+	step := view.cellWidth + view.spacing
+	firstCol := int(math.Max(0, float64((x0-view.spacing)/step)))
+	lastCol := int(math.Min(float64(view.columns-1), float64((x1-view.spacing)/step)))
+	firstRow := int(math.Max(0, float64((y0-view.spacing)/view.getRowHeight())))
+	lastRow := (y1 - view.spacing) / view.getRowHeight()
+	count := view.getCount()
+
+	for row := firstRow; row <= lastRow; row++ {
+		for col := firstCol; col <= lastCol; col++ {
+			index := row*view.columns + col
+			if index >= count {
+				break
+			}
+
+			rect := view.getCellRect(index)
+			if rect.X <= x1 && rect.X+rect.W > x0 && rect.Y <= y1 && rect.Y+rect.H > y0 {
+				selected[index] = true
+			}
+		}
+	}
+
+	changed := len(selected) != len(view.selected)
+	for index := range selected {
+		if !view.selected[index] {
+			changed = true
+		}
+	}
+
+	if changed {
+		view.selected = selected
+		view.onSelectionChanged(true)
+	}
+
+	return
+}
+
+func (view *GridView) onContentPointerDown(point *structs.Point) {
+	if !view.enable {
+		return
+	}
+
+	if window := view.GetWindow(); window != nil {
+		window.SetFocus(view.Widget)
+	}
+
+	p := view.content.translatePoint(point)
+	index := view.getIndexAtContentPoint(p)
+	ctrl := view.isCtrlDown()
+	shift := view.isShiftDown()
+
+	if index >= 0 {
+		view.pressItem(index)
+	} else if !ctrl && !shift {
+		view.ClearSelection(true)
+	}
+
+	if view.selectionMode == LIST_SELECTION_MULTIPLE {
+		// A press on a cell only starts the band once the pointer moves away.
+		view.banding = index < 0
+		view.bandArmed = index >= 0
+		view.bandStart = *p
+		view.bandEnd = *p
+		view.bandBase = make(map[int]bool)
+		if ctrl || shift {
+			for index := range view.selected {
+				view.bandBase[index] = true
+			}
+		}
+	}
+
+	return
+}
+
+func (view *GridView) onContentPointerMove(point *structs.Point) {
+	if !view.banding && !view.bandArmed {
+		return
+	}
+
+	// The release went elsewhere when the button is up.
+	if !view.isPointerDown() {
+		view.endBand()
+		return
+	}
+
+	p := view.content.translatePoint(point)
+	if view.bandArmed {
+		dx := math.Abs(float64(p.X - view.bandStart.X))
+		dy := math.Abs(float64(p.Y - view.bandStart.Y))
+		if dx < GRID_VIEW_BAND_DRAG && dy < GRID_VIEW_BAND_DRAG {
+			return
+		}
+		view.bandArmed = false
+		view.banding = true
+	}
+
+	view.bandEnd = *p
+	view.updateBand()
+	view.PostRedraw()
+
+	return
+}
+
+func (view *GridView) onContentPointerUp(point *structs.Point) {
+	view.endBand()
+
+	return
+}
+
+func (view *GridView) endBand() {
+	if view.banding {
+		view.PostRedraw()
+	}
+	view.banding = false
+	view.bandArmed = false
+	view.bandBase = nil
+
+	return
+}
+
+func (view *GridView) onContentDoubleClick(point *structs.Point) {
+	view.activate(view.getIndexAtContentPoint(view.content.translatePoint(point)))
+
+	return
+}
+
+func (view *GridView) onKeyDown(code int) {
+	if !view.enable {
+		return
+	}
+
+	shift := view.isShiftDown()
+	ctrl := view.isCtrlDown()
+	columns := view.columns
+
+	switch code {
+	case keyevent.DOM_VK_LEFT:
+		view.moveCurrent(view.current-1, shift, ctrl)
+	case keyevent.DOM_VK_RIGHT:
+		view.moveCurrent(view.current+1, shift, ctrl)
+	case keyevent.DOM_VK_UP:
+		if view.current >= columns {
+			view.moveCurrent(view.current-columns, shift, ctrl)
+		} else {
+			view.moveCurrent(view.current, shift, ctrl)
+		}
+	case keyevent.DOM_VK_DOWN:
+		if view.current+columns < view.getCount() {
+			view.moveCurrent(view.current+columns, shift, ctrl)
+		} else {
+			view.moveCurrent(view.current, shift, ctrl)
+		}
+	default:
+		view.onItemKeyDown(code)
+	}
+
+	if view.keyDownHandler != nil {
+		view.keyDownHandler(code)
+	}
+
+	return
+}
+
+func (content *gridViewContent) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (content *gridViewContent) paintChildren(context canvas.Canvas2D) {
+	view := content.view
+	if view.source == nil {
+		return
+	}
+	view.bindItems()
+
+	context.Save()
+	context.BeginPath()
+	context.Rect(0, view.getYOffset(), float64(content.rect.W), float64(view.getViewHeight()))
+	context.Clip()
+	content.Widget.paintChildren(context)
+
+	if view.banding {
+		x := math.Min(float64(view.bandStart.X), float64(view.bandEnd.X))
+		y := math.Min(float64(view.bandStart.Y), float64(view.bandEnd.Y))
+		w := math.Abs(float64(view.bandEnd.X - view.bandStart.X))
+		h := math.Abs(float64(view.bandEnd.Y - view.bandStart.Y))
+
+		context.SetFillStyle(GRID_VIEW_BAND_FILL)
+		context.FillRect(x, y, w, h)
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(GRID_VIEW_BAND_LINE)
+		context.BeginPath()
+		context.Rect(math.Floor(x)+0.5, math.Floor(y)+0.5, w, h)
+		context.Stroke()
+	}
+	context.Restore()
+
+	return
+}
+
+func (content *gridViewContent) onPointerDown(point *structs.Point) {
+	content.view.onContentPointerDown(point)
+	content.Widget.onPointerDown(point)
+
+	return
+}
+
+func (content *gridViewContent) onPointerMove(point *structs.Point) {
+	content.view.onContentPointerMove(point)
+	content.Widget.onPointerMove(point)
+
+	return
+}
+
+func (content *gridViewContent) onPointerUp(point *structs.Point) {
+	content.view.onContentPointerUp(point)
+	content.Widget.onPointerUp(point)
+
+	return
+}
+
+func (content *gridViewContent) onDoubleClick(point *structs.Point) {
+	content.view.onContentDoubleClick(point)
+	content.Widget.onDoubleClick(point)
+
+	return
+}
@@ -0,0 +1,131 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/event"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// countGridSource is a GridDataSource of cells named by their index.
+type countGridSource int
+
+func (source countGridSource) GetCount() int {
+	return int(source)
+}
+
+func (source countGridSource) RenderItem(item *GridItem, index int) {
+	item.SetText(strconv.Itoa(index), false)
+
+	return
+}
+
+// newGridViewScene returns a GridView 400x300 of 12 cells in
+// LIST_SELECTION_MULTIPLE mode, beside a window covering 450,0 to 600,400.
+func newGridViewScene() (typist, *GridView) {
+	var view *GridView
+	k := newScene(600, 400, func(win *Window) {
+		view = NewGridView(win.Widget, 0, 0, 400, 300).SetDataSource(countGridSource(12))
+		view.SetSelectionMode(LIST_SELECTION_MULTIPLE)
+		NewWindow(win.manager, 450, 0, 150, 400)
+	})
+
+	return k, view
+}
+
+// getCellCenter returns the center of cell index.
+func getCellCenter(view *GridView, index int) (x, y int) {
+	rect := view.getCellRect(index)
+
+	return rect.X + rect.W/2, rect.Y + rect.H/2
+}
+
+func TestGridViewBand(t *testing.T) {
+	cases := []struct {
+		name     string
+		from     func(view *GridView) (x, y int)
+		to       func(view *GridView) (x, y int)
+		selected []int
+	}{
+		{"from a cell", func(view *GridView) (int, int) {
+			return getCellCenter(view, 0)
+		}, func(view *GridView) (int, int) {
+			return getCellCenter(view, view.columns+1)
+		}, nil},
+		{"from the gap", func(view *GridView) (int, int) {
+			return 2, 2
+		}, func(view *GridView) (int, int) {
+			return getCellCenter(view, view.columns+1)
+		}, nil},
+		{"short of the band distance", func(view *GridView) (int, int) {
+			return getCellCenter(view, 1)
+		}, func(view *GridView) (int, int) {
+			x, y := getCellCenter(view, 1)
+			return x + GRID_VIEW_BAND_DRAG - 1, y + 2
+		}, []int{1}},
+	}
+	for _, c := range cases {
+		k, view := newGridViewScene()
+		x0, y0 := c.from(view)
+		x1, y1 := c.to(view)
+		k.drag(x0, y0, x1, y1)
+
+		want := c.selected
+		if want == nil {
+			want = []int{0, 1, view.columns, view.columns + 1}
+		}
+		if selected := view.GetSelected(); !reflect.DeepEqual(selected, want) {
+			t.Errorf("%s: selected %v, want %v", c.name, selected, want)
+		}
+		if view.banding || view.bandArmed {
+			t.Errorf("%s: band left on after the release", c.name)
+		}
+	}
+}
+
+func TestGridViewBandReleasedOutside(t *testing.T) {
+	for _, fromCell := range []bool{false, true} {
+		k, view := newGridViewScene()
+		x0, y0 := 2, 2
+		if fromCell {
+			x0, y0 = getCellCenter(view, 0)
+		}
+		x1, y1 := getCellCenter(view, 1)
+
+		k.pointer(event.EVENT_POINTER_DOWN, x0, y0)
+		k.pointer(event.EVENT_POINTER_MOVE, x1, y1)
+		k.pointer(event.EVENT_POINTER_MOVE, 500, 50)
+		k.pointer(event.EVENT_POINTER_UP, 500, 50)
+		selected := view.GetSelected()
+
+		x2, y2 := getCellCenter(view, 2*view.columns+2)
+		k.pointer(event.EVENT_POINTER_MOVE, x1, y1)
+		k.pointer(event.EVENT_POINTER_MOVE, x2, y2)
+		if now := view.GetSelected(); !reflect.DeepEqual(now, selected) {
+			t.Errorf("from cell %v: hovering after the release changed the selection from %v to %v", fromCell, selected, now)
+		}
+		if view.banding || view.bandArmed {
+			t.Errorf("from cell %v: band left on after hovering with the button up", fromCell)
+		}
+	}
+}
+
+func TestGridViewBandKeepsSelectionWithCtrl(t *testing.T) {
+	k, view := newGridViewScene()
+	view.SetSelected(view.columns*2, true, false)
+
+	x0, y0 := getCellCenter(view, 0)
+	x1, y1 := getCellCenter(view, 1)
+	k.hold(keyevent.DOM_VK_CONTROL, func() {
+		k.drag(x0, y0, x1, y1)
+	})
+	if selected, want := view.GetSelected(), []int{0, 1, view.columns * 2}; !reflect.DeepEqual(selected, want) {
+		t.Errorf("ctrl band selected %v, want %v", selected, want)
+	}
+
+	k.click(2, 2)
+	if selected := view.GetSelected(); len(selected) != 0 {
+		t.Errorf("click between the cells kept %v", selected)
+	}
+}
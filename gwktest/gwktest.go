@@ -0,0 +1,165 @@
+// Package gwktest renders gwk widget trees with the headless backend and
+// compares the frames against golden PNG files.
+package gwktest
+
+import (
+	"fmt"
+	"github.com/Luncher/gwk"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv names the environment variable that, when set to a non-empty
+// value, makes AssertGolden rewrite the golden files instead of comparing.
+const UpdateEnv = "GWKTEST_UPDATE"
+
+type Options struct {
+	// Tolerance is the largest difference allowed on any RGBA channel of a
+	// pixel before that pixel counts as different.
+	Tolerance uint8
+	// MaxDiffPixels is the number of differing pixels tolerated per frame.
+	MaxDiffPixels int
+}
+
+var DefaultOptions = Options{Tolerance: 8}
+
+type BuildFunc func(win *gwk.Window)
+
+// Render builds a widget tree inside a full size window on a fresh headless
+// window manager, lays it out, draws it and returns a copy of the frame.
+func Render(w, h int, build BuildFunc) *image.RGBA {
+	manager := gwk.NewHeadlessWindowManager(w, h)
+	win := gwk.NewWindow(manager, 0, 0, float32(w), float32(h))
+	if build != nil {
+		build(win)
+	}
+
+	manager.Relayout()
+	frame := manager.Snapshot()
+	result := image.NewRGBA(frame.Bounds())
+	draw.Draw(result, result.Bounds(), frame, frame.Bounds().Min, draw.Src)
+
+	return result
+}
+
+// Compare returns the number of pixels of got that differ from want by more
+// than tolerance on any channel, along with an image that paints those
+// pixels red over a faded copy of want.
+func Compare(got, want image.Image, tolerance uint8) (int, *image.RGBA) {
+	bounds := got.Bounds().Union(want.Bounds())
+	diff := image.NewRGBA(bounds)
+	count := 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Pt(x, y)
+			g := color.RGBAModel.Convert(colorAt(got, p)).(color.RGBA)
+			w := color.RGBAModel.Convert(colorAt(want, p)).(color.RGBA)
+			if !p.In(got.Bounds()) || !p.In(want.Bounds()) || exceeds(g, w, tolerance) {
+				diff.SetRGBA(x, y, color.RGBA{0xff, 0, 0, 0xff})
+				count++
+			} else {
+				diff.SetRGBA(x, y, color.RGBA{w.R / 4, w.G / 4, w.B / 4, 0xff})
+			}
+		}
+	}
+
+	return count, diff
+}
+
+// AssertGolden compares got with the PNG at path. On mismatch it writes
+// <name>.got.png and <name>.diff.png next to the golden file and fails t.
+// With GWKTEST_UPDATE set, the golden file is (re)written instead.
+func AssertGolden(t testing.TB, got image.Image, path string, opts Options) {
+	t.Helper()
+
+	if len(os.Getenv(UpdateEnv)) > 0 {
+		if err := WritePNG(path, got); err != nil {
+			t.Fatalf("gwktest: update %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := ReadPNG(path)
+	if err != nil {
+		t.Fatalf("gwktest: %v (set %s=1 to create it)", err, UpdateEnv)
+		return
+	}
+
+	count, diff := Compare(got, want, opts.Tolerance)
+	if count <= opts.MaxDiffPixels {
+		return
+	}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	if err := WritePNG(base+".got.png", got); err != nil {
+		t.Errorf("gwktest: %v", err)
+	}
+	if err := WritePNG(base+".diff.png", diff); err != nil {
+		t.Errorf("gwktest: %v", err)
+	}
+	t.Errorf("gwktest: %s: %d pixels differ (tolerance %d, allowed %d), see %s.diff.png",
+		path, count, opts.Tolerance, opts.MaxDiffPixels, base)
+}
+
+func ReadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %v", path, err)
+	}
+
+	return img, nil
+}
+
+func WritePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func colorAt(img image.Image, p image.Point) color.Color {
+	if !p.In(img.Bounds()) {
+		return color.Transparent
+	}
+
+	return img.At(p.X, p.Y)
+}
+
+func exceeds(a, b color.RGBA, tolerance uint8) bool {
+	return absDiff(a.R, b.R) > tolerance ||
+		absDiff(a.G, b.G) > tolerance ||
+		absDiff(a.B, b.B) > tolerance ||
+		absDiff(a.A, b.A) > tolerance
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
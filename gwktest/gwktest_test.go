@@ -0,0 +1,129 @@
+package gwktest
+
+import (
+	"github.com/Luncher/gwk"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLabelWrapGolden(t *testing.T) {
+	frame := Render(160, 150, func(win *gwk.Window) {
+		label := gwk.NewLabel(win.Widget, 10, 10, 140, 130)
+		label.SetSingleLineMode(false)
+		label.SetTextAlignH("left")
+		label.SetTextAlignV("top")
+		label.SetText("The quick brown fox jumps over the lazy dog again and again", false)
+	})
+
+	AssertGolden(t, frame, filepath.Join("testdata", "label_wrap.png"), DefaultOptions)
+}
+
+func TestScrollBarDraggerGolden(t *testing.T) {
+	frame := Render(80, 140, func(win *gwk.Window) {
+		vbar := gwk.NewVScrollBar(win.Widget, 10, 10, 12, 100)
+		vbar.SetScrollRange(400)
+		vbar.SetCurrentPosition(150)
+
+		hbar := gwk.NewHScrollBar(win.Widget, 10, 120, 60, 12)
+		hbar.SetScrollRange(180)
+		hbar.SetCurrentPosition(60)
+	})
+
+	AssertGolden(t, frame, filepath.Join("testdata", "scroll_bar_dragger.png"), DefaultOptions)
+}
+
+func newFilledImage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestCompareTolerance(t *testing.T) {
+	want := newFilledImage(4, 4, color.RGBA{100, 100, 100, 255})
+	got := newFilledImage(4, 4, color.RGBA{100, 100, 100, 255})
+	got.SetRGBA(1, 1, color.RGBA{105, 100, 100, 255})
+	got.SetRGBA(2, 2, color.RGBA{100, 90, 100, 255})
+
+	cases := []struct {
+		tolerance uint8
+		count     int
+	}{
+		{0, 2},
+		{5, 1},
+		{10, 0},
+	}
+	for _, c := range cases {
+		if count, _ := Compare(got, want, c.tolerance); count != c.count {
+			t.Errorf("Compare with tolerance %d: %d pixels differ, want %d", c.tolerance, count, c.count)
+		}
+	}
+
+	count, diff := Compare(got, want, 5)
+	if diff.RGBAAt(2, 2) != (color.RGBA{0xff, 0, 0, 0xff}) || diff.RGBAAt(1, 1) == diff.RGBAAt(2, 2) {
+		t.Errorf("diff image does not mark only the differing pixel (count %d)", count)
+	}
+
+	if count, _ := Compare(newFilledImage(3, 4, color.RGBA{}), newFilledImage(4, 4, color.RGBA{}), 0); count != 4 {
+		t.Errorf("Compare of images of different sizes: %d pixels differ, want 4", count)
+	}
+}
+
+// recorder is a testing.TB remembering the failures instead of reporting
+// them.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func TestAssertGoldenMaxDiffPixels(t *testing.T) {
+	t.Setenv(UpdateEnv, "")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "golden.png")
+	want := newFilledImage(8, 8, color.RGBA{255, 255, 255, 255})
+	if err := WritePNG(path, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got := newFilledImage(8, 8, color.RGBA{255, 255, 255, 255})
+	for x := 0; x < 3; x++ {
+		got.SetRGBA(x, 0, color.RGBA{0, 0, 0, 255})
+	}
+
+	r := &recorder{TB: t}
+	AssertGolden(r, got, path, Options{MaxDiffPixels: 3})
+	if r.failed {
+		t.Errorf("3 differing pixels failed with MaxDiffPixels 3")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "golden.diff.png")); err == nil {
+		t.Errorf("diff image written for a passing comparison")
+	}
+
+	r = &recorder{TB: t}
+	AssertGolden(r, got, path, Options{MaxDiffPixels: 2})
+	if !r.failed {
+		t.Errorf("3 differing pixels passed with MaxDiffPixels 2")
+	}
+	for _, name := range []string{"golden.got.png", "golden.diff.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not written on failure: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/raster"
+	"image"
+)
+
+// HeadlessBackend renders into an in-memory image.RGBA with the pure Go
+// rasterizer. Frames are only produced when WindowManager.Snapshot is called.
+type HeadlessBackend struct {
+	canvas *raster.Canvas
+	w, h   int
+	cursor string
+	urls   []string
+}
+
+func NewHeadlessBackend(w, h int) *HeadlessBackend {
+	return &HeadlessBackend{
+		canvas: raster.NewCanvas(w, h),
+		w:      w,
+		h:      h,
+		cursor: "default",
+	}
+}
+
+func (backend *HeadlessBackend) GetCanvas2D() canvas.Canvas2D {
+	return backend.canvas
+}
+
+func (backend *HeadlessBackend) GetSize() (w, h int) {
+	return backend.w, backend.h
+}
+
+func (backend *HeadlessBackend) GetViewPort() (w, h int) {
+	return backend.w, backend.h
+}
+
+func (backend *HeadlessBackend) RequestAnimationFrame(onFrame func()) {
+	return
+}
+
+func (backend *HeadlessBackend) SetCursor(cursor string) {
+	backend.cursor = cursor
+
+	return
+}
+
+func (backend *HeadlessBackend) GetCursor() string {
+	return backend.cursor
+}
+
+// OpenURL records url instead of opening it.
+func (backend *HeadlessBackend) OpenURL(url string) {
+	backend.urls = append(backend.urls, url)
+
+	return
+}
+
+// GetOpenedURLs returns the URLs opened so far, oldest first.
+func (backend *HeadlessBackend) GetOpenedURLs() []string {
+	return backend.urls
+}
+
+func (backend *HeadlessBackend) GetFrame() *image.RGBA {
+	return backend.canvas.GetImage()
+}
+
+// NewHeadlessWindowManager resets the window manager singleton and binds it
+// to a HeadlessBackend of the given size. No DOM listeners are installed.
+func NewHeadlessWindowManager(w, h int) *WindowManager {
+	*manager = WindowManager{}
+
+	return manager.init(nil, NewHeadlessBackend(w, h))
+}
+
+// Snapshot draws the whole window stack synchronously and returns the frame.
+// It returns nil when the manager is not backed by a HeadlessBackend.
+func (manager *WindowManager) Snapshot() *image.RGBA {
+	backend, ok := manager.backend.(*HeadlessBackend)
+	if !ok {
+		return nil
+	}
+
+	backend.canvas.ClearRect(0, 0, float64(backend.w), float64(backend.h))
+	manager.onDrawFrame()
+
+	return backend.GetFrame()
+}
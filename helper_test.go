@@ -0,0 +1,80 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/event"
+)
+
+// typist drives a headless window manager the way a user would.
+type typist struct {
+	m *WindowManager
+}
+
+// newScene builds a window covering a fresh w by h headless window manager
+// with build, paints it once so the widgets are laid out and returns a typist
+// on it.
+func newScene(w, h int, build func(win *Window)) typist {
+	m := NewHeadlessWindowManager(w, h)
+	build(NewWindow(m, 0, 0, float32(w), float32(h)))
+	m.Snapshot()
+
+	return typist{m: m}
+}
+
+func (k typist) pointer(t string, x, y int) {
+	k.m.Inject(event.NewPointerEvent(t, x, y))
+
+	return
+}
+
+func (k typist) click(x, y int) {
+	k.pointer(event.EVENT_POINTER_DOWN, x, y)
+	k.pointer(event.EVENT_POINTER_UP, x, y)
+
+	return
+}
+
+// drag presses at x0,y0, moves to x1,y1 in steps and releases there.
+func (k typist) drag(x0, y0, x1, y1 int) {
+	k.pointer(event.EVENT_POINTER_DOWN, x0, y0)
+	for i := 1; i <= 4; i++ {
+		k.pointer(event.EVENT_POINTER_MOVE, x0+(x1-x0)*i/4, y0+(y1-y0)*i/4)
+	}
+	k.pointer(event.EVENT_POINTER_UP, x1, y1)
+
+	return
+}
+
+func (k typist) key(codes ...int) {
+	for _, code := range codes {
+		k.m.Inject(event.NewKeyEvent(event.EVENT_KEY_DOWN, code))
+		k.m.Inject(event.NewKeyEvent(event.EVENT_KEY_UP, code))
+	}
+
+	return
+}
+
+// hold calls press while holding modifier, DOM_VK_SHIFT or DOM_VK_CONTROL.
+func (k typist) hold(modifier int, press func()) {
+	k.m.Inject(event.NewKeyEvent(event.EVENT_KEY_DOWN, modifier))
+	press()
+	k.m.Inject(event.NewKeyEvent(event.EVENT_KEY_UP, modifier))
+
+	return
+}
+
+// keyWith presses codes while holding modifier.
+func (k typist) keyWith(modifier int, codes ...int) {
+	k.hold(modifier, func() {
+		k.key(codes...)
+	})
+
+	return
+}
+
+func (k typist) typeText(text string) {
+	for _, char := range text {
+		k.m.Inject(event.NewKeyPressEvent(char))
+	}
+
+	return
+}
@@ -0,0 +1,304 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/image"
+	"github.com/Luncher/gwk/pkg/structs"
+	"github.com/Luncher/gwk/pkg/theme"
+)
+
+const (
+	ICON_BUTTON_PADDING       = 4
+	ICON_BUTTON_HOVER_COLOR   = "#DCDCDC"
+	ICON_BUTTON_CHECKED_COLOR = "#B4D5FE"
+	ICON_BUTTON_DISABLE_COLOR = "rgba(240,240,240,0.6)"
+)
+
+// IconButton is a button showing an icon of the theme, or its text while the
+// icon is missing. A toggle button stays pressed while it is checked, and
+// toggle buttons added to the same ButtonGroup are mutually exclusive. The
+// changed handler receives the new checked value.
+type IconButton struct {
+	*Widget
+	iconName  string
+	icon      *image.Image
+	toggle    bool
+	checked   bool
+	separator bool
+	group     *ButtonGroup
+}
+
+func NewIconButton(parent *Widget, x, y, w, h float32) *IconButton {
+	button := &IconButton{
+		Widget: NewWidget(TYPE_ICON_BUTTON, parent, x, y, w, h),
+	}
+	button.I = button
+
+	return button
+}
+
+// SetIcon shows the icon name, looked up with theme.GetIconImage.
+func (button *IconButton) SetIcon(name string) *IconButton {
+	button.iconName = name
+	button.icon = nil
+	if len(name) > 0 {
+		button.icon = theme.GetIconImage(name)
+	}
+	button.PostRedraw()
+
+	return button
+}
+
+func (button *IconButton) GetIcon() string {
+	return button.iconName
+}
+
+// SetToggle makes clicks check and uncheck the button.
+func (button *IconButton) SetToggle(toggle bool) *IconButton {
+	button.toggle = toggle
+
+	return button
+}
+
+func (button *IconButton) IsToggle() bool {
+	return button.toggle
+}
+
+// SetChecked checks or unchecks the button. Checking it unchecks the other
+// buttons of its group.
+func (button *IconButton) SetChecked(checked, notify bool) *IconButton {
+	if button.checked == checked {
+		return button
+	}
+
+	button.checked = checked
+	if checked && button.group != nil {
+		button.group.onChecked(button, notify)
+	}
+
+	if notify && button.onChanged != nil {
+		button.onChanged(checked)
+	}
+	button.PostRedraw()
+
+	return button
+}
+
+func (button *IconButton) IsChecked() bool {
+	return button.checked
+}
+
+func (button *IconButton) GetGroup() *ButtonGroup {
+	return button.group
+}
+
+func (button *IconButton) IsSeparator() bool {
+	return button.separator
+}
+
+// toggleChecked flips a toggle button, leaving the checked button of a group
+// checked.
+func (button *IconButton) toggleChecked() {
+	if !button.toggle {
+		return
+	}
+
+	if button.group != nil {
+		button.SetChecked(true, true)
+	} else {
+		button.SetChecked(!button.checked, true)
+	}
+
+	return
+}
+
+// activate does what a click does, for the overflow menu of a Toolbar.
+func (button *IconButton) activate() {
+	if !button.enable || button.separator {
+		return
+	}
+
+	button.toggleChecked()
+	button.onClicked(button.GetAbsPosition())
+
+	return
+}
+
+func (button *IconButton) onPointerUp(point *structs.Point) {
+	if button.separator {
+		return
+	}
+
+	if button.enable && button.isClicked() {
+		button.toggleChecked()
+	}
+	button.Widget.onPointerUp(point)
+
+	return
+}
+
+func (button *IconButton) paintBackground(context canvas.Canvas2D) {
+	style := button.getStyle("")
+	w := float64(button.rect.W)
+	h := float64(button.rect.H)
+
+	if button.separator {
+		x := float64(button.rect.W/2) + 0.5
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+		context.BeginPath()
+		context.MoveTo(x, 2)
+		context.LineTo(x, h-2)
+		context.Stroke()
+		return
+	}
+
+	if !button.enable {
+		return
+	}
+
+	switch {
+	case button.checked || button.state == STATE_ACTIVE:
+		context.SetFillStyle(ICON_BUTTON_CHECKED_COLOR)
+	case button.state == STATE_OVER:
+		context.SetFillStyle(ICON_BUTTON_HOVER_COLOR)
+	default:
+		return
+	}
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(MENU_LINE_COLOR)
+	context.BeginPath()
+	context.Rect(0.5, 0.5, w-1, h-1)
+	context.Stroke()
+
+	return
+}
+
+func (button *IconButton) paintSelf(context canvas.Canvas2D) {
+	if button.separator {
+		return
+	}
+
+	style := button.getStyle("")
+	w := button.rect.W
+	h := button.rect.H
+
+	if icon := button.icon; icon != nil && icon.GetImage() != nil {
+		icon.Draw(context, image.DISPLAY_AUTO_SIZE_DOWN, ICON_BUTTON_PADDING, ICON_BUTTON_PADDING, w-2*ICON_BUTTON_PADDING, h-2*ICON_BUTTON_PADDING)
+		if !button.enable {
+			context.SetFillStyle(ICON_BUTTON_DISABLE_COLOR)
+			context.FillRect(0, 0, float64(w), float64(h))
+		}
+		return
+	}
+
+	context.SetFont(style.Font)
+	context.SetFillStyle(style.TextColor)
+	context.SetTextAlign("center")
+	context.SetTextBaseline("middle")
+	context.FillText(button.GetText(), float64(w)/2, float64(h)/2, float64(w-2*ICON_BUTTON_PADDING))
+
+	return
+}
+
+// ButtonGroup keeps at most one of its toggle buttons checked, like a
+// RadioGroup of icon buttons. Its changed handler receives the newly checked
+// *IconButton.
+type ButtonGroup struct {
+	buttons   []*IconButton
+	onChanged OnChangedHandler
+}
+
+// NewButtonGroup groups buttons, making them toggle buttons.
+func NewButtonGroup(buttons ...*IconButton) *ButtonGroup {
+	group := &ButtonGroup{}
+	group.Add(buttons...)
+
+	return group
+}
+
+// Add moves buttons into the group. If more than one of them is checked,
+// only the last stays checked.
+func (group *ButtonGroup) Add(buttons ...*IconButton) *ButtonGroup {
+	for _, button := range buttons {
+		if button.group != nil {
+			button.group.Remove(button)
+		}
+
+		button.group = group
+		button.toggle = true
+		group.buttons = append(group.buttons, button)
+		if button.checked {
+			group.onChecked(button, false)
+		}
+	}
+
+	return group
+}
+
+func (group *ButtonGroup) Remove(button *IconButton) *ButtonGroup {
+	if i := group.indexOf(button); i >= 0 {
+		group.buttons = append(group.buttons[:i], group.buttons[i+1:]...)
+		button.group = nil
+	}
+
+	return group
+}
+
+func (group *ButtonGroup) GetButtons() []*IconButton {
+	return group.buttons
+}
+
+func (group *ButtonGroup) GetChecked() *IconButton {
+	for _, button := range group.buttons {
+		if button.checked {
+			return button
+		}
+	}
+
+	return nil
+}
+
+// GetCheckedIndex returns the index of the checked button, or -1.
+func (group *ButtonGroup) GetCheckedIndex() int {
+	return group.indexOf(group.GetChecked())
+}
+
+func (group *ButtonGroup) SetCheckedIndex(index int, notify bool) *ButtonGroup {
+	if index >= 0 && index < len(group.buttons) {
+		group.buttons[index].SetChecked(true, notify)
+	}
+
+	return group
+}
+
+func (group *ButtonGroup) SetChangedHandler(onChanged OnChangedHandler) *ButtonGroup {
+	group.onChanged = onChanged
+
+	return group
+}
+
+func (group *ButtonGroup) indexOf(button *IconButton) int {
+	for i, iter := range group.buttons {
+		if iter == button {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (group *ButtonGroup) onChecked(checked *IconButton, notify bool) {
+	for _, button := range group.buttons {
+		if button != checked && button.checked {
+			button.SetChecked(false, notify)
+		}
+	}
+
+	if notify && group.onChanged != nil {
+		group.onChanged(checked)
+	}
+
+	return
+}
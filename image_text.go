@@ -1,8 +1,8 @@
 package gwk
 
 import (
+	"github.com/Luncher/gwk/pkg/canvas"
 	"github.com/Luncher/gwk/pkg/image"
-	"honnef.co/go/js/dom"
 )
 
 type ImageText struct {
@@ -27,48 +27,48 @@ func NewImageText(parent *Widget, x, y, w, h float32) *ImageText {
 	return imageText
 }
 
-func (imageText *ImageText) getImage() *image.Image {
+func (imageText *ImageText) GetImage() *image.Image {
 	return imageText.image
 }
 
-func (imageText *ImageText) setImage(image *image.Image) {
+func (imageText *ImageText) SetImage(image *image.Image) {
 	imageText.image = image
 }
 
-func (imageText *ImageText) setBorder(border int) {
+func (imageText *ImageText) SetBorder(border int) {
 	imageText.border = border
 }
 
-func (imageText *ImageText) setSpacer(spacer int) {
+func (imageText *ImageText) SetSpacer(spacer int) {
 	imageText.spacer = spacer
 }
 
-func (imageText *ImageText) setTextOverImage(overImage bool) {
+func (imageText *ImageText) SetTextOverImage(overImage bool) {
 	imageText.textOverImage = overImage
 }
 
-func (imageText *ImageText) setVertical(vertical bool) {
+func (imageText *ImageText) SetVertical(vertical bool) {
 	imageText.vertical = vertical
 }
 
-func (imageText *ImageText) setFgImageDisplay(display image.Display) {
+func (imageText *ImageText) SetFgImageDisplay(display image.Display) {
 	imageText.fgImageDiplay = display
 }
 
-func (imageText *ImageText) paintSelf(context *dom.CanvasRenderingContext2D) {
+func (imageText *ImageText) paintSelf(context canvas.Canvas2D) {
 	var x, y, w, h int
 	rect := imageText.rect
 	border := imageText.border
 	text := imageText.GetText()
-	image := imageText.getImage()
+	image := imageText.GetImage()
 	style := imageText.getStyle("")
 	fontSize := style.FontSize
 	if style.FontSize == 0 {
 		fontSize = 12
 	}
 
-	context.Font = style.Font
-	context.FillStyle = style.TextColor
+	context.SetFont(style.Font)
+	context.SetFillStyle(style.TextColor)
 	if len(text) > 0 && image != nil {
 		if imageText.textOverImage {
 			x = rect.W >> 1
@@ -76,8 +76,8 @@ func (imageText *ImageText) paintSelf(context *dom.CanvasRenderingContext2D) {
 			w = rect.W
 			h = rect.H
 			image.Draw(context, imageText.fgImageDiplay, 0, 0, w, h)
-			context.TextAlign = "center"
-			context.TextBaseline = "middle"
+			context.SetTextAlign("center")
+			context.SetTextBaseline("middle")
 		} else {
 			if imageText.vertical {
 				x = border
@@ -92,13 +92,13 @@ func (imageText *ImageText) paintSelf(context *dom.CanvasRenderingContext2D) {
 			}
 			image.Draw(context, imageText.fgImageDiplay, x, y, w, h)
 			if imageText.vertical {
-				context.TextAlign = "center"
-				context.TextBaseline = "bottom"
+				context.SetTextAlign("center")
+				context.SetTextBaseline("bottom")
 				x = rect.W >> 1
 				y = rect.H - border
 			} else {
-				context.TextAlign = "left"
-				context.TextBaseline = "middle"
+				context.SetTextAlign("left")
+				context.SetTextBaseline("middle")
 				x = rect.H + imageText.spacer
 				y = rect.H >> 1
 			}
@@ -108,14 +108,14 @@ func (imageText *ImageText) paintSelf(context *dom.CanvasRenderingContext2D) {
 		if imageText.textAlign == "left" {
 			x = border
 			y = rect.H >> 1
-			context.TextAlign = "left"
-			context.TextBaseline = "middle"
+			context.SetTextAlign("left")
+			context.SetTextBaseline("middle")
 			context.FillText(text, float64(x), float64(y), float64(rect.W))
 		} else {
 			x = rect.W >> 1
 			y = rect.H >> 1
-			context.TextAlign = "center"
-			context.TextBaseline = "middle"
+			context.SetTextAlign("center")
+			context.SetTextBaseline("middle")
 			context.FillText(text, float64(x), float64(y), float64(rect.W))
 		}
 	} else if image != nil {
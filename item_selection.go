@@ -0,0 +1,471 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"math"
+	"sort"
+)
+
+// itemSelection keeps the selected indexes, the current index and the anchor
+// of range selections for the views showing indexed items.
+type itemSelection struct {
+	selectionMode ListSelectionMode
+	selected      map[int]bool
+	current       int
+	anchor        int
+}
+
+func newItemSelection() itemSelection {
+	return itemSelection{
+		selectionMode: LIST_SELECTION_SINGLE,
+		selected:      make(map[int]bool),
+		current:       -1,
+		anchor:        -1,
+	}
+}
+
+func (s *itemSelection) reset() {
+	s.selected = make(map[int]bool)
+	s.current = -1
+	s.anchor = -1
+
+	return
+}
+
+// clamp drops the selected indexes and moves the current index and anchor
+// that are not below count.
+func (s *itemSelection) clamp(count int) {
+	for index := range s.selected {
+		if index >= count {
+			delete(s.selected, index)
+		}
+	}
+
+	if s.current >= count {
+		s.current = count - 1
+	}
+
+	if s.anchor >= count {
+		s.anchor = s.current
+	}
+
+	return
+}
+
+// setMode changes the selection mode, keeping only the first selected index
+// in the single selection modes.
+func (s *itemSelection) setMode(mode ListSelectionMode) {
+	s.selectionMode = mode
+	switch mode {
+	case LIST_SELECTION_NONE:
+		s.selected = make(map[int]bool)
+	case LIST_SELECTION_SINGLE, LIST_SELECTION_RADIO:
+		if index := s.GetSelectedIndex(); index >= 0 {
+			s.selectOnly(index)
+		}
+	}
+
+	return
+}
+
+func (s *itemSelection) IsSelected(index int) bool {
+	return s.selected[index]
+}
+
+// GetSelected returns the selected indexes in ascending order.
+func (s *itemSelection) GetSelected() []int {
+	selected := make([]int, 0, len(s.selected))
+	for index := range s.selected {
+		selected = append(selected, index)
+	}
+	sort.Ints(selected)
+
+	return selected
+}
+
+// GetSelectedIndex returns the first selected index, or -1.
+func (s *itemSelection) GetSelectedIndex() int {
+	first := -1
+	for index := range s.selected {
+		if first < 0 || index < first {
+			first = index
+		}
+	}
+
+	return first
+}
+
+// setSelected selects or deselects index and reports whether the selection
+// changed. Outside of LIST_SELECTION_MULTIPLE mode selecting an index
+// deselects the others.
+func (s *itemSelection) setSelected(index int, selected bool) bool {
+	if s.selectionMode == LIST_SELECTION_NONE {
+		return false
+	}
+
+	var changed bool
+	if !selected {
+		changed = s.selected[index]
+		delete(s.selected, index)
+	} else if s.selectionMode == LIST_SELECTION_MULTIPLE {
+		changed = !s.selected[index]
+		s.selected[index] = true
+	} else {
+		changed = s.selectOnly(index)
+	}
+
+	return changed
+}
+
+func (s *itemSelection) selectAll(count int) bool {
+	if s.selectionMode != LIST_SELECTION_MULTIPLE || len(s.selected) == count {
+		return false
+	}
+
+	for i := 0; i < count; i++ {
+		s.selected[i] = true
+	}
+
+	return true
+}
+
+func (s *itemSelection) deselectAll() bool {
+	if len(s.selected) == 0 {
+		return false
+	}
+	s.selected = make(map[int]bool)
+
+	return true
+}
+
+func (s *itemSelection) selectOnly(index int) bool {
+	changed := len(s.selected) != 1 || !s.selected[index]
+	if changed {
+		s.selected = map[int]bool{index: true}
+	}
+
+	return changed
+}
+
+func (s *itemSelection) selectRange(from, to int, keep bool) {
+	if !keep {
+		s.selected = make(map[int]bool)
+	}
+
+	if from > to {
+		from, to = to, from
+	}
+
+	for i := int(math.Max(0, float64(from))); i <= to; i++ {
+		s.selected[i] = true
+	}
+
+	return
+}
+
+// moveTo makes index the current one and reports whether the selection
+// changed. extend selects the range from the anchor in
+// LIST_SELECTION_MULTIPLE mode and keep leaves the selection alone;
+// otherwise index becomes the only selected one.
+func (s *itemSelection) moveTo(index int, extend, keep bool) bool {
+	s.current = index
+
+	switch {
+	case s.selectionMode == LIST_SELECTION_NONE:
+		s.anchor = index
+	case s.selectionMode == LIST_SELECTION_MULTIPLE && extend && s.anchor >= 0:
+		s.selectRange(s.anchor, index, keep)
+		return true
+	case s.selectionMode == LIST_SELECTION_MULTIPLE && keep:
+		s.anchor = index
+	default:
+		s.anchor = index
+		return s.selectOnly(index)
+	}
+
+	return false
+}
+
+// itemViewOwner is implemented by the views built on itemView, which supply
+// the count and the geometry of their items.
+type itemViewOwner interface {
+	getCount() int
+	// getItemSpan returns the top and bottom of item index in the scrolled
+	// content.
+	getItemSpan(index int) (top, bottom int)
+	// getPageHeight returns the height of the view showing items.
+	getPageHeight() int
+	// getPageSize returns the number of items Page Up and Page Down move by.
+	getPageSize() int
+	updateLayout()
+}
+
+// viewItem is a widget showing an item of an itemView.
+type viewItem interface {
+	SetVisible(visible bool) *Widget
+}
+
+// itemView is what the views showing indexed items share on top of their
+// itemSelection: the current item, the selection conventions, activation and
+// the recycling of the widgets of the visible items. The views only add the
+// geometry of their items.
+type itemView struct {
+	scroll      *ScrollView
+	selection   *itemSelection
+	itemOwner   itemViewOwner
+	items       map[interface{}]viewItem
+	freeItems   []viewItem
+	dirty       bool
+	onActivated ListActivatedHandler
+}
+
+func newItemView(scroll *ScrollView, selection *itemSelection, owner itemViewOwner) itemView {
+	return itemView{
+		scroll:    scroll,
+		selection: selection,
+		itemOwner: owner,
+		items:     make(map[interface{}]viewItem),
+	}
+}
+
+// GetCurrent returns the item with the keyboard focus, or -1.
+func (v *itemView) GetCurrent() int {
+	return v.selection.current
+}
+
+func (v *itemView) GetSelectionMode() ListSelectionMode {
+	return v.selection.selectionMode
+}
+
+// resetItems forgets the selection and scrolls back to the top, for a new
+// data source.
+func (v *itemView) resetItems() {
+	v.selection.reset()
+	v.scroll.SetScrollPositionV(0)
+
+	return
+}
+
+// reloadItems drops the selected items past the end and renders the visible
+// ones again.
+func (v *itemView) reloadItems() {
+	v.selection.clamp(v.itemOwner.getCount())
+	v.dirty = true
+	v.itemOwner.updateLayout()
+	v.scroll.PostRedraw()
+
+	return
+}
+
+// resizeContent sizes the scrolled content child and refreshes the scroll
+// bars.
+func (v *itemView) resizeContent(content *Widget, w, h int) {
+	content.Resize(w, h)
+	v.scroll.relayout(v.scroll.getCanvas2D(), true)
+
+	return
+}
+
+func (v *itemView) getViewWidth() int {
+	if v.scroll.workArea != nil {
+		return v.scroll.workArea.W
+	}
+
+	return v.scroll.rect.W
+}
+
+func (v *itemView) getViewHeight() int {
+	if v.scroll.workArea != nil {
+		return v.scroll.workArea.H
+	}
+
+	return v.scroll.rect.H
+}
+
+// ensureVisible scrolls the least needed to show item index.
+func (v *itemView) ensureVisible(index int) {
+	top, bottom := v.itemOwner.getItemSpan(index)
+	height := v.itemOwner.getPageHeight()
+
+	position := v.scroll.GetScrollPositionV()
+	if top < int(position) {
+		v.scroll.SetScrollPositionV(float64(top))
+	} else if bottom > int(position)+height {
+		v.scroll.SetScrollPositionV(float64(bottom - height))
+	}
+
+	return
+}
+
+func (v *itemView) setCurrent(index int) {
+	if index >= 0 && index < v.itemOwner.getCount() {
+		v.selection.current = index
+		v.selection.anchor = index
+		v.ensureVisible(index)
+		v.scroll.PostRedraw()
+	}
+
+	return
+}
+
+// selectItem selects or deselects an item. Outside of
+// LIST_SELECTION_MULTIPLE mode selecting an item deselects the others.
+func (v *itemView) selectItem(index int, selected, notify bool) {
+	if index >= 0 && index < v.itemOwner.getCount() && v.selection.setSelected(index, selected) {
+		v.onSelectionChanged(notify)
+	}
+
+	return
+}
+
+func (v *itemView) selectAllItems(notify bool) {
+	if v.selection.selectAll(v.itemOwner.getCount()) {
+		v.onSelectionChanged(notify)
+	}
+
+	return
+}
+
+func (v *itemView) clearSelection(notify bool) {
+	if v.selection.deselectAll() {
+		v.onSelectionChanged(notify)
+	}
+
+	return
+}
+
+func (v *itemView) onSelectionChanged(notify bool) {
+	if notify && v.scroll.onChanged != nil {
+		v.scroll.onChanged(v.selection.GetSelected())
+	}
+	v.scroll.PostRedraw()
+
+	return
+}
+
+// moveCurrent makes index the current item. extend selects the range from
+// the anchor in LIST_SELECTION_MULTIPLE mode and keep leaves the selection
+// alone; otherwise the current item becomes the only selected one.
+func (v *itemView) moveCurrent(index int, extend, keep bool) {
+	count := v.itemOwner.getCount()
+	if count == 0 {
+		return
+	}
+
+	index = int(math.Max(0, math.Min(float64(count-1), float64(index))))
+	if v.selection.moveTo(index, extend, keep) {
+		v.onSelectionChanged(true)
+	}
+	v.ensureVisible(index)
+	v.scroll.PostRedraw()
+
+	return
+}
+
+func (v *itemView) activate(index int) {
+	if index >= 0 && index < v.itemOwner.getCount() && v.onActivated != nil {
+		v.onActivated(index)
+	}
+
+	return
+}
+
+// pressItem handles a click on item index: Ctrl toggles it in
+// LIST_SELECTION_MULTIPLE mode, Shift extends the selection to it and a
+// plain click selects only it.
+func (v *itemView) pressItem(index int) {
+	s := v.selection
+	ctrl := v.scroll.isCtrlDown()
+	shift := v.scroll.isShiftDown()
+
+	if ctrl && !shift && s.selectionMode == LIST_SELECTION_MULTIPLE {
+		s.current = index
+		s.anchor = index
+		v.selectItem(index, !s.selected[index], true)
+		return
+	}
+	v.moveCurrent(index, shift, ctrl)
+
+	return
+}
+
+// onItemKeyDown handles the keys moving the current item, Space, Enter and
+// Ctrl+A.
+func (v *itemView) onItemKeyDown(code int) {
+	s := v.selection
+	shift := v.scroll.isShiftDown()
+	ctrl := v.scroll.isCtrlDown()
+	page := v.itemOwner.getPageSize()
+
+	switch code {
+	case keyevent.DOM_VK_UP:
+		v.moveCurrent(s.current-1, shift, ctrl)
+	case keyevent.DOM_VK_DOWN:
+		v.moveCurrent(s.current+1, shift, ctrl)
+	case keyevent.DOM_VK_PAGE_UP:
+		v.moveCurrent(s.current-page, shift, ctrl)
+	case keyevent.DOM_VK_PAGE_DOWN:
+		v.moveCurrent(s.current+page, shift, ctrl)
+	case keyevent.DOM_VK_HOME:
+		v.moveCurrent(0, shift, ctrl)
+	case keyevent.DOM_VK_END:
+		v.moveCurrent(v.itemOwner.getCount()-1, shift, ctrl)
+	case keyevent.DOM_VK_SPACE:
+		if s.current >= 0 {
+			selected := s.selectionMode != LIST_SELECTION_MULTIPLE || !s.selected[s.current]
+			v.selectItem(s.current, selected, true)
+		}
+	case keyevent.DOM_VK_RETURN:
+		v.activate(s.current)
+	case keyevent.DOM_VK_A:
+		if ctrl {
+			v.selectAllItems(true)
+		}
+	}
+
+	return
+}
+
+// recycleItems hides the items whose key is not kept and keeps them for
+// reuse.
+func (v *itemView) recycleItems(keep func(key interface{}) bool) {
+	for key, item := range v.items {
+		if !keep(key) {
+			delete(v.items, key)
+			item.SetVisible(false)
+			v.freeItems = append(v.freeItems, item)
+		}
+	}
+
+	return
+}
+
+// bindItem returns the item bound to key, binding a free one, or one made by
+// newItem, if there is none. bound reports whether key already had it.
+func (v *itemView) bindItem(key interface{}, newItem func() viewItem) (item viewItem, bound bool) {
+	if item, bound = v.items[key]; bound {
+		return item, true
+	}
+
+	if n := len(v.freeItems); n > 0 {
+		item = v.freeItems[n-1]
+		v.freeItems = v.freeItems[:n-1]
+	} else {
+		item = newItem()
+	}
+	v.items[key] = item
+
+	return item, false
+}
+
+// eachItem calls f with every item, bound or free.
+func (v *itemView) eachItem(f func(item viewItem)) {
+	for _, item := range v.items {
+		f(item)
+	}
+	for _, item := range v.freeItems {
+		f(item)
+	}
+
+	return
+}
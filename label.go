@@ -2,8 +2,7 @@ package gwk
 
 import (
 	"fmt"
-	"github.com/Luncher/gwk/pkg/theme"
-	"honnef.co/go/js/dom"
+	"github.com/Luncher/gwk/pkg/canvas"
 	"math"
 )
 
@@ -45,44 +44,7 @@ func NewLabel(parent *Widget, x, y, w, h float32) *Label {
 	return label
 }
 
-func (label *Label) getTipsStyle() *theme.ThemeStyle {
-	return label.theme.StateNormal
-}
-
-func (label *Label) drawTips(context *dom.CanvasRenderingContext2D) *Label {
-	if label.state != STATE_OVER {
-		return label
-	}
-
-	tips := label.getTips()
-	if len(tips) > 0 {
-		style := label.getTipsStyle()
-		h := 30
-		x := label.getWidth() + 3
-		y := label.getHeight()
-		w := context.MeasureText(tips).Width + 40
-
-		context.LineWidth = 1
-		context.FillStyle = style.TipsFillColor
-		context.StrokeStyle = style.TipsLineColor
-
-		context.Rect(float64(x), float64(y), w, float64(h))
-		context.Fill()
-		context.Stroke()
-
-		context.TextAlign = "center"
-		context.TextBaseline = "middle"
-		context.Font = style.Font
-		context.FillStyle = style.TipsTextColor
-		x = x + (int(w) >> 1)
-		y = y + (h >> 1)
-		context.FillText(tips, float64(x), float64(y), -1)
-	}
-
-	return label
-}
-
-func (label *Label) setBorder(sides ...int) *Label {
+func (label *Label) SetBorder(sides ...int) *Label {
 	if len(sides) > 0 {
 		label.leftBorder = sides[0]
 	}
@@ -108,10 +70,10 @@ func (label *Label) setLayoutFlexibleSize(flexibleSize int) *Label {
 	return label
 }
 
-func (label *Label) layoutText(context *dom.CanvasRenderingContext2D, text string) {
+func (label *Label) layoutText(context canvas.Canvas2D, text string) {
 	width := label.rect.W - label.leftBorder - label.rightBorder
 	if len(text) > 0 {
-		context.Font = label.getFont()
+		context.SetFont(label.getFont())
 		label.lines = layoutText(context, label.fontSize, text, width, label.flexibleSize)
 	} else {
 		label = nil
@@ -120,7 +82,7 @@ func (label *Label) layoutText(context *dom.CanvasRenderingContext2D, text strin
 	return
 }
 
-func (label *Label) relayout(context *dom.CanvasRenderingContext2D, force bool) {
+func (label *Label) relayout(context canvas.Canvas2D, force bool) {
 	if !label.needRelayout && !force && context == nil {
 		return
 	}
@@ -143,31 +105,31 @@ func (label *Label) SetText(str string, notify bool) *Label {
 	return label
 }
 
-func (label *Label) setTextAlignV(align string) *Label {
+func (label *Label) SetTextAlignV(align string) *Label {
 	label.textAlignV = align
 
 	return label
 }
 
-func (label *Label) setTextAlignH(align string) *Label {
+func (label *Label) SetTextAlignH(align string) *Label {
 	label.textAlignH = align
 
 	return label
 }
 
-func (label *Label) setTextColor(textColor string) *Label {
+func (label *Label) SetTextColor(textColor string) *Label {
 	label.textColor = textColor
 
 	return label
 }
 
-func (label *Label) setLineColor(lineColor string) *Label {
+func (label *Label) SetLineColor(lineColor string) *Label {
 	label.lineColor = lineColor
 
 	return label
 }
 
-func (label *Label) getTextColor() string {
+func (label *Label) GetTextColor() string {
 	if len(label.textColor) != 0 {
 		return label.textColor
 	} else {
@@ -175,7 +137,7 @@ func (label *Label) getTextColor() string {
 	}
 }
 
-func (label *Label) getLineColor() string {
+func (label *Label) GetLineColor() string {
 	if len(label.lineColor) != 0 {
 		return label.lineColor
 	} else {
@@ -183,31 +145,31 @@ func (label *Label) getLineColor() string {
 	}
 }
 
-func (label *Label) setTextBold(textB bool) *Label {
+func (label *Label) SetTextBold(textB bool) *Label {
 	label.textB = textB
 
 	return label.updateFont()
 }
 
-func (label *Label) setTextUnderline(textUnderline bool) *Label {
+func (label *Label) SetTextUnderline(textUnderline bool) *Label {
 	label.textU = textUnderline
 
 	return label.updateFont()
 }
 
-func (label *Label) setTextItalic(textItalic bool) *Label {
+func (label *Label) SetTextItalic(textItalic bool) *Label {
 	label.textI = textItalic
 
 	return label.updateFont()
 }
 
-func (label *Label) setFontSize(fontSize int) *Label {
+func (label *Label) SetFontSize(fontSize int) *Label {
 	label.fontSize = fontSize
 
 	return label.updateFont()
 }
 
-func (label *Label) setSingleLineMode(singleLine bool) *Label {
+func (label *Label) SetSingleLineMode(singleLine bool) *Label {
 	label.singleLine = singleLine
 
 	return label
@@ -242,7 +204,7 @@ func (label *Label) getLines() []string {
 	return label.lines
 }
 
-func (label *Label) paintSelf(context *dom.CanvasRenderingContext2D) {
+func (label *Label) paintSelf(context canvas.Canvas2D) {
 	if label.singleLine {
 		label.paintSelfSL(context)
 	} else {
@@ -252,39 +214,39 @@ func (label *Label) paintSelf(context *dom.CanvasRenderingContext2D) {
 	return
 }
 
-func (label *Label) paintSelfSL(context *dom.CanvasRenderingContext2D) {
+func (label *Label) paintSelfSL(context canvas.Canvas2D) {
 	text := label.text
 	label.paintSLText(context, text)
 
 	return
 }
 
-func (label *Label) paintSLText(context *dom.CanvasRenderingContext2D, text string) {
-	context.Font = label.getFont()
-	context.TextBaseline = "middle"
-	context.FillStyle = label.getTextColor()
+func (label *Label) paintSLText(context canvas.Canvas2D, text string) {
+	context.SetFont(label.getFont())
+	context.SetTextBaseline("middle")
+	context.SetFillStyle(label.GetTextColor())
 
 	var x int
-	var y = label.getHeight() >> 1
-	var w = label.getWidth()
+	var y = label.GetHeight() >> 1
+	var w = label.GetWidth()
 
 	switch label.textAlignH {
 	case "center":
 		x = w >> 1
-		context.TextAlign = "center"
+		context.SetTextAlign("center")
 	case "right":
 		x = w - label.rightBorder
-		context.TextAlign = "right"
+		context.SetTextAlign("right")
 	default:
 		x = label.leftBorder
-		context.TextAlign = "left"
+		context.SetTextAlign("left")
 	}
 	context.FillText(text, float64(x), float64(y), float64(w))
 
 	return
 }
 
-func (label *Label) paintSelfML(context *dom.CanvasRenderingContext2D) {
+func (label *Label) paintSelfML(context canvas.Canvas2D) {
 	lines := label.getLines()
 	if len(lines) == 0 {
 		return
@@ -312,12 +274,12 @@ func (label *Label) paintSelfML(context *dom.CanvasRenderingContext2D) {
 	leftBorder := label.leftBorder
 	rightBorder := label.rightBorder
 
-	context.TextAlign = "left"
-	context.TextBaseline = "top"
-	context.StrokeStyle = label.getLineColor()
-	context.FillStyle = label.getTextColor()
-	context.Font = label.getFont()
-	context.LineWidth = 1
+	context.SetTextAlign("left")
+	context.SetTextBaseline("top")
+	context.SetStrokeStyle(label.GetLineColor())
+	context.SetFillStyle(label.GetTextColor())
+	context.SetFont(label.getFont())
+	context.SetLineWidth(1)
 
 	for i := 0; i < maxLineNr; i++ {
 		str := lines[i]
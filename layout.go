@@ -1,7 +1,7 @@
 package gwk
 
 import (
-	"honnef.co/go/js/dom"
+	"github.com/Luncher/gwk/pkg/canvas"
 	"math"
 	"math/rand"
 	"regexp"
@@ -22,7 +22,7 @@ func (*Layout) canBreakBefore(char rune) bool {
 	return true
 }
 
-func (*Layout) justifyText(context *dom.CanvasRenderingContext2D, line string, maxWidth int) string {
+func (*Layout) justifyText(context canvas.Canvas2D, line string, maxWidth int) string {
 	M := 0
 	words := strings.Split(line, " ")
 	N := len(words) - 1
@@ -76,7 +76,7 @@ func (*Layout) justifyText(context *dom.CanvasRenderingContext2D, line string, m
 	return result
 }
 
-func (layout *Layout) wrapBySpace(context *dom.CanvasRenderingContext2D, width int, content string, justity bool) []string {
+func (layout *Layout) wrapBySpace(context canvas.Canvas2D, width int, content string, justity bool) []string {
 	result := []string{}
 
 	contents := strings.Split(content, "\n")
@@ -115,37 +115,35 @@ func (*Layout) checkCJK(char rune) bool {
 	return regex.MatchString(string([]rune{char}))
 }
 
-func (layout *Layout) getWordEnd(text string, start int) int {
+// getWordEnd returns the rune index just past the word starting at start.
+// Spaces and CJK characters are words of their own, and a following character
+// that cannot start a line is kept with them.
+func (layout *Layout) getWordEnd(text []rune, start int) int {
 	if len(text) <= start {
 		return -1
 	}
 
-	rtext := []rune(text)
-	c := rtext[start]
+	c := text[start]
 	if layout.checkCJK(c) || c == ' ' {
 		start++
-		if start < len(text) && !layout.canBreakBefore(rtext[start]) {
+		if start < len(text) && !layout.canBreakBefore(text[start]) {
 			start++
 		}
 		return start
-	} else {
-		flag := false
-		var i = start + 1
-		for ; i < len(text); i++ {
-			if rtext[i] == ' ' {
-				flag = true
-				break
-			}
-		}
-		if flag {
-			return i
-		} else {
-			return i - 1
-		}
 	}
+
+	i := start + 1
+	for i < len(text) && text[i] != ' ' {
+		i++
+	}
+
+	return i
 }
 
-func (layout *Layout) wrapByWord(context *dom.CanvasRenderingContext2D, width int, content string) []string {
+// wrapByWord breaks content into lines no wider than width. Concatenating
+// the lines of a paragraph gives back the paragraph unchanged, so callers can
+// map offsets in the text to lines.
+func (layout *Layout) wrapByWord(context canvas.Canvas2D, width int, content string) []string {
 	contents := strings.Split(content, "\n")
 	result := make([]string, 0, len(contents))
 	for _, it := range contents {
@@ -155,17 +153,15 @@ func (layout *Layout) wrapByWord(context *dom.CanvasRenderingContext2D, width in
 			continue
 		}
 
-		var startIndex int
 		itr := []rune(it)
-		for startIndex = 0; startIndex < len(itr); startIndex++ {
-			endIndex := layout.getWordEnd(it, startIndex)
-			if endIndex != -1 {
-				word = it[startIndex:endIndex]
-				lineTest = line + word
-				startIndex = endIndex
-			} else {
+		for startIndex := 0; startIndex < len(itr); {
+			endIndex := layout.getWordEnd(itr, startIndex)
+			if endIndex == -1 {
 				break
 			}
+			word = string(itr[startIndex:endIndex])
+			lineTest = line + word
+			startIndex = endIndex
 
 			if int(context.MeasureText(lineTest).Width) > width {
 				if int(context.MeasureText(word).Width) > width {
@@ -173,9 +169,9 @@ func (layout *Layout) wrapByWord(context *dom.CanvasRenderingContext2D, width in
 						result = append(result, line)
 					}
 					var singleLine, singleLineTest string
-					for c := range word {
+					for _, c := range word {
 						singleLineTest = singleLine + string(c)
-						if int(context.MeasureText(singleLineTest).Width) > width {
+						if int(context.MeasureText(singleLineTest).Width) > width && singleLine != "" {
 							result = append(result, singleLine)
 							singleLine = string(c)
 						} else {
@@ -183,7 +179,7 @@ func (layout *Layout) wrapByWord(context *dom.CanvasRenderingContext2D, width in
 						}
 					}
 					word = singleLine
-				} else {
+				} else if line != "" {
 					result = append(result, line)
 				}
 				line = word
@@ -200,6 +196,6 @@ func (layout *Layout) wrapByWord(context *dom.CanvasRenderingContext2D, width in
 
 var layoutInstance = &Layout{}
 
-func layoutText(context *dom.CanvasRenderingContext2D, fontSize int, str string, width int, flexibleWidth int) []string {
+func layoutText(context canvas.Canvas2D, fontSize int, str string, width int, flexibleWidth int) []string {
 	return layoutInstance.wrapByWord(context, width, str)
 }
@@ -0,0 +1,111 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newLayoutContext() canvas.Canvas2D {
+	context := NewHeadlessWindowManager(100, 100).getCanvas2D()
+	context.SetFont("14px sans-serif")
+
+	return context
+}
+
+func measureWidth(context canvas.Canvas2D, text string) int {
+	return int(context.MeasureText(text).Width)
+}
+
+func TestWrapByWord(t *testing.T) {
+	context := newLayoutContext()
+	layout := &Layout{}
+
+	cases := []struct {
+		name    string
+		content string
+		fit     string
+		lines   []string
+	}{
+		{"ascii", "the quick brown fox", "the quick",
+			[]string{"the quick", " brown ", "fox"}},
+		{"trailing word", "alpha beta gamma", "alpha beta",
+			[]string{"alpha beta", " gamma"}},
+		{"fits", "short", "short and more", []string{"short"}},
+		{"cjk", "你好世界你好世界", "你好世",
+			[]string{"你好世", "界你好", "世界"}},
+		{"cjk punctuation", "你好，世界。", "你好",
+			[]string{"你", "好，", "世", "界。"}},
+		{"over-wide word", "a mmmmmmmmmm b", "mmmm",
+			[]string{"a ", "mmmm", "mmmm", "mm b"}},
+		{"paragraphs", "one\n\ntwo", "one", []string{"one", "", "two"}},
+	}
+	for _, c := range cases {
+		width := measureWidth(context, c.fit)
+		lines := layout.wrapByWord(context, width, c.content)
+		if !reflect.DeepEqual(lines, c.lines) {
+			t.Errorf("%s: wrapped %q to %q, want %q", c.name, c.content, lines, c.lines)
+		}
+
+		for _, line := range lines {
+			if w := measureWidth(context, line); w > width && len([]rune(line)) > 1 {
+				t.Errorf("%s: line %q is %d wide, more than %d", c.name, line, w, width)
+			}
+		}
+		if joined := strings.Join(lines, ""); joined != strings.Replace(c.content, "\n", "", -1) {
+			t.Errorf("%s: lines join to %q", c.name, joined)
+		}
+	}
+}
+
+func TestWrapByWordNarrowerThanAChar(t *testing.T) {
+	context := newLayoutContext()
+	lines := (&Layout{}).wrapByWord(context, 1, "ab c")
+
+	if want := []string{"a", "b", " ", "c"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("wrapped to %q, want one rune per line %q", lines, want)
+	}
+}
+
+// TestTextAreaLinesCoverText checks that the wrapped lines of every paragraph
+// join back to it, so positions in the text map to lines.
+func TestTextAreaLinesCoverText(t *testing.T) {
+	var area *TextArea
+	newScene(200, 200, func(win *Window) {
+		area = NewTextArea(win.Widget, 0, 0, 120, 200)
+	})
+
+	texts := []string{
+		"",
+		"the quick brown fox jumps over the lazy dog",
+		"antidisestablishmentarianism is long\n\n  indented  twice  \nend ",
+		"你好，世界。你好世界你好世界 mixed with latin",
+		"trailing newline\n",
+	}
+	for _, text := range texts {
+		area.SetText(text, false)
+
+		paragraphs := strings.Split(text, "\n")
+		var joined []string
+		var current []rune
+		start := 0
+		for i, line := range area.lines {
+			if line.start != start {
+				t.Errorf("%q: line %d starts at %d, want %d", text, i, line.start, start)
+			}
+
+			current = append(current, line.text...)
+			start += len(line.text)
+			if i+1 == len(area.lines) || area.lines[i+1].start != start {
+				joined = append(joined, string(current))
+				current = nil
+				start++
+			}
+		}
+
+		if !reflect.DeepEqual(joined, paragraphs) {
+			t.Errorf("%q: lines join to paragraphs %q", text, joined)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"github.com/Luncher/gwk/pkg/theme"
+)
+
+const (
+	LINK_COLOR         = "#0066CC"
+	LINK_VISITED_COLOR = "#551A8B"
+	LINK_ACTIVE_COLOR  = "#CC0000"
+)
+
+// LinkActivatedHandler is called when a Link is clicked, or activated with
+// Space or Enter, in place of opening its URL.
+type LinkActivatedHandler func(link *Link)
+
+// Link is a Label showing a hyperlink: a pointer cursor and an underline
+// while the pointer is over it. Activating it calls the activated handler,
+// or opens the URL with WindowManager.OpenURL, and marks it visited. Themes
+// style visited links with the state-visited state.
+type Link struct {
+	*Label
+	url         string
+	visited     bool
+	onActivated LinkActivatedHandler
+}
+
+func NewLink(parent *Widget, x, y, w, h float32) *Link {
+	link := &Link{
+		Label: NewLabel(parent, x, y, w, h),
+	}
+	link.t = TYPE_LINK
+	link.textAlignH = "left"
+	link.SetCursor("pointer")
+	link.I = link
+
+	return link
+}
+
+func (link *Link) SetURL(url string) *Link {
+	link.url = url
+
+	return link
+}
+
+func (link *Link) GetURL() string {
+	return link.url
+}
+
+func (link *Link) SetVisited(visited bool) *Link {
+	link.visited = visited
+	link.PostRedraw()
+
+	return link
+}
+
+func (link *Link) IsVisited() bool {
+	return link.visited
+}
+
+func (link *Link) SetActivatedHandler(onActivated LinkActivatedHandler) *Link {
+	link.onActivated = onActivated
+
+	return link
+}
+
+// Activate does what clicking the link does.
+func (link *Link) Activate() *Link {
+	if !link.enable {
+		return link
+	}
+
+	if link.onActivated != nil {
+		link.onActivated(link)
+	} else if len(link.url) > 0 {
+		GetWindowManagerInstance().OpenURL(link.url)
+	}
+	link.SetVisited(true)
+
+	return link
+}
+
+func (link *Link) onPointerDown(point *structs.Point) {
+	if window := link.GetWindow(); window != nil && link.enable {
+		window.SetFocus(link.Widget)
+	}
+
+	return
+}
+
+func (link *Link) onPointerUp(point *structs.Point) {
+	if link.enable && link.isClicked() {
+		link.Activate()
+	}
+	link.Widget.onPointerUp(point)
+
+	return
+}
+
+func (link *Link) onKeyDown(code int) {
+	if code == keyevent.DOM_VK_SPACE || code == keyevent.DOM_VK_RETURN {
+		link.Activate()
+	}
+	link.Widget.onKeyDown(code)
+
+	return
+}
+
+func (link *Link) isHovered() bool {
+	return link.state == STATE_OVER || link.state == STATE_ACTIVE
+}
+
+// getLinkStyle returns the style of the current state, the visited one for a
+// visited link the pointer is not over.
+func (link *Link) getLinkStyle() *theme.ThemeStyle {
+	style := link.getStyle("")
+	if link.enable && link.visited && !link.isHovered() && link.theme.StateVisited != nil {
+		style = link.theme.StateVisited
+	}
+
+	return style
+}
+
+// getLinkColor returns the color of the text, falling back to the usual link
+// colors when the theme has no link style.
+func (link *Link) getLinkColor() string {
+	if len(link.textColor) > 0 {
+		return link.textColor
+	}
+
+	if !link.enable || theme.Has(TYPE_LINK) {
+		return link.getLinkStyle().TextColor
+	}
+
+	switch {
+	case link.state == STATE_ACTIVE:
+		return LINK_ACTIVE_COLOR
+	case link.visited:
+		return LINK_VISITED_COLOR
+	default:
+		return LINK_COLOR
+	}
+}
+
+func (link *Link) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (link *Link) paintSelf(context canvas.Canvas2D) {
+	style := link.getLinkStyle()
+	color := link.getLinkColor()
+	text := link.GetText()
+	w := link.rect.W
+	h := link.rect.H
+
+	font := link.font
+	if len(font) == 0 {
+		font = style.Font
+	}
+	context.SetFont(font)
+	context.SetTextBaseline("middle")
+	context.SetFillStyle(color)
+
+	maxWidth := float64(w - link.leftBorder - link.rightBorder)
+	textWidth := context.MeasureText(text).Width
+	if textWidth > maxWidth {
+		textWidth = maxWidth
+	}
+
+	var x float64
+	switch link.textAlignH {
+	case "center":
+		x = (float64(w) - textWidth) / 2
+	case "right":
+		x = float64(w-link.rightBorder) - textWidth
+	default:
+		x = float64(link.leftBorder)
+	}
+	context.SetTextAlign("left")
+	context.FillText(text, x, float64(h)/2, maxWidth)
+
+	if link.enable && (link.textU || link.isHovered()) {
+		y := float64(h/2+link.fontSize/2) + 1.5
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(color)
+		context.BeginPath()
+		context.MoveTo(x, y)
+		context.LineTo(x+textWidth, y)
+		context.Stroke()
+	}
+
+	if window := link.GetWindow(); window != nil && window.GetFocus() == link.Widget {
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(color)
+		context.BeginPath()
+		context.Rect(0.5, 0.5, float64(w)-1, float64(h)-1)
+		context.Stroke()
+	}
+
+	return
+}
@@ -0,0 +1,117 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"reflect"
+	"testing"
+)
+
+const linkURL = "https://example.com/docs"
+
+// newLinkScene returns a Link 120x20 at 10,10 to linkURL.
+func newLinkScene() (typist, *Link) {
+	var link *Link
+	k := newScene(300, 100, func(win *Window) {
+		link = NewLink(win.Widget, 10, 10, 120, 20).SetURL(linkURL)
+		link.SetText("Documentation", false)
+	})
+
+	return k, link
+}
+
+// getOpenedURLs returns the URLs the headless backend of k was asked to open.
+func getOpenedURLs(k typist) []string {
+	return k.m.backend.(*HeadlessBackend).GetOpenedURLs()
+}
+
+func TestLinkClick(t *testing.T) {
+	k, link := newLinkScene()
+	if link.IsVisited() {
+		t.Fatalf("a new link is visited")
+	}
+
+	// A press released off the link does nothing.
+	k.drag(20, 20, 200, 80)
+	if len(getOpenedURLs(k)) != 0 || link.IsVisited() {
+		t.Fatalf("releasing off the link opened %v", getOpenedURLs(k))
+	}
+
+	k.click(20, 20)
+	if urls := getOpenedURLs(k); !reflect.DeepEqual(urls, []string{linkURL}) {
+		t.Fatalf("clicking opened %v", urls)
+	}
+	if !link.IsVisited() {
+		t.Fatalf("the link is not visited once clicked")
+	}
+
+	link.SetEnable(false)
+	k.click(20, 20)
+	if len(getOpenedURLs(k)) != 1 {
+		t.Fatalf("clicking a disabled link opened it")
+	}
+}
+
+func TestLinkKeys(t *testing.T) {
+	k, link := newLinkScene()
+	link.GetWindow().SetFocus(link.Widget)
+
+	k.key(keyevent.DOM_VK_SPACE)
+	if !reflect.DeepEqual(getOpenedURLs(k), []string{linkURL}) || !link.IsVisited() {
+		t.Fatalf("Space opened %v, visited %v", getOpenedURLs(k), link.IsVisited())
+	}
+
+	link.SetVisited(false)
+	k.key(keyevent.DOM_VK_RETURN)
+	if !reflect.DeepEqual(getOpenedURLs(k), []string{linkURL, linkURL}) || !link.IsVisited() {
+		t.Fatalf("Enter opened %v, visited %v", getOpenedURLs(k), link.IsVisited())
+	}
+
+	k.key(keyevent.DOM_VK_A)
+	if len(getOpenedURLs(k)) != 2 {
+		t.Fatalf("another key opened the link")
+	}
+}
+
+func TestLinkActivatedHandler(t *testing.T) {
+	k, link := newLinkScene()
+	activated := []*Link{}
+	link.SetActivatedHandler(func(link *Link) {
+		activated = append(activated, link)
+	})
+
+	k.click(20, 20)
+	if len(activated) != 1 || activated[0] != link {
+		t.Fatalf("the handler got %v, want the link once", activated)
+	}
+	if len(getOpenedURLs(k)) != 0 {
+		t.Fatalf("the URL was opened as well as the handler called: %v", getOpenedURLs(k))
+	}
+	if !link.IsVisited() {
+		t.Fatalf("the link is not visited once handled")
+	}
+
+	link.SetActivatedHandler(nil)
+	link.Activate()
+	if len(activated) != 1 || len(getOpenedURLs(k)) != 1 {
+		t.Fatalf("without a handler: handled %d times, opened %v", len(activated), getOpenedURLs(k))
+	}
+}
+
+func TestLinkURLOpener(t *testing.T) {
+	k, link := newLinkScene()
+	opened := []string{}
+	k.m.SetURLOpener(func(url string) {
+		opened = append(opened, url)
+	})
+
+	k.click(20, 20)
+	if !reflect.DeepEqual(opened, []string{linkURL}) || len(getOpenedURLs(k)) != 0 {
+		t.Fatalf("the opener got %v and the backend %v", opened, getOpenedURLs(k))
+	}
+
+	k.m.SetURLOpener(nil)
+	link.Activate()
+	if len(opened) != 1 || !reflect.DeepEqual(getOpenedURLs(k), []string{linkURL}) {
+		t.Fatalf("after removing the opener it got %v and the backend %v", opened, getOpenedURLs(k))
+	}
+}
@@ -0,0 +1,468 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/structs"
+	"github.com/Luncher/gwk/pkg/theme"
+	"math"
+)
+
+type ListSelectionMode int
+
+const (
+	LIST_SELECTION_NONE ListSelectionMode = iota
+	LIST_SELECTION_SINGLE
+	LIST_SELECTION_MULTIPLE
+	LIST_SELECTION_RADIO
+)
+
+const (
+	LIST_VIEW_ITEM_HEIGHT    = 24
+	LIST_ITEM_PADDING        = 6
+	LIST_ITEM_SELECTED_COLOR = "#B4D5FE"
+)
+
+// ListDataSource supplies the rows of a ListView. RenderItem is called when a
+// recycled item is bound to row index, and for every visible row after
+// Reload. It typically sets the text of the item, its user data or a paint
+// handler.
+type ListDataSource interface {
+	GetCount() int
+	RenderItem(item *ListItem, index int)
+}
+
+type ListActivatedHandler func(index int)
+
+// StringListSource is a ListDataSource showing one string per row.
+type StringListSource []string
+
+func (source StringListSource) GetCount() int {
+	return len(source)
+}
+
+func (source StringListSource) RenderItem(item *ListItem, index int) {
+	item.SetText(source[index], false)
+
+	return
+}
+
+// ListItem is the widget showing a row of a ListView. Items are recycled as
+// the view scrolls, so they should not keep per-row state of their own.
+type ListItem struct {
+	*Widget
+	view  *ListView
+	index int
+}
+
+func (item *ListItem) GetIndex() int {
+	return item.index
+}
+
+func (item *ListItem) GetListView() *ListView {
+	return item.view
+}
+
+func (item *ListItem) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (item *ListItem) paintSelf(context canvas.Canvas2D) {
+	item.view.owner.paintItem(item, context)
+
+	return
+}
+
+// paintHighlight fills the background of a selected item and frames the
+// current one while the view has the focus.
+func (item *ListItem) paintHighlight(context canvas.Canvas2D, style *theme.ThemeStyle) {
+	view := item.view
+	w := float64(item.rect.W)
+	h := float64(item.rect.H)
+
+	if item.selected {
+		context.SetFillStyle(colorOr(style.FillColor, LIST_ITEM_SELECTED_COLOR))
+		context.FillRect(0, 0, w, h)
+	} else if len(style.FillColor) > 0 {
+		context.SetFillStyle(style.FillColor)
+		context.FillRect(0, 0, w, h)
+	}
+
+	if view.editing && view.current == item.index && view.selectionMode != LIST_SELECTION_NONE {
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+		context.BeginPath()
+		context.Rect(0.5, 0.5, w-1, h-1)
+		context.Stroke()
+	}
+
+	return
+}
+
+// listViewContent is the scrolled child of a ListView. It spans all rows so
+// the ScrollView machinery handles scrolling, but only holds the items of the
+// visible rows.
+type listViewContent struct {
+	*Widget
+	view *ListView
+}
+
+// listViewOwner is implemented by ListView and the views built on it, so that
+// the shared content and items dispatch to the outermost view.
+type listViewOwner interface {
+	getItemType() string
+	paintItem(item *ListItem, context canvas.Canvas2D)
+	onContentPointerDown(point *structs.Point)
+	onContentPointerMove(point *structs.Point)
+	onContentPointerUp(point *structs.Point)
+	onContentDoubleClick(point *structs.Point)
+}
+
+func (view *ListView) paintItem(item *ListItem, context canvas.Canvas2D) {
+	style := item.getStyle("")
+	w := float64(item.rect.W)
+	h := float64(item.rect.H)
+	item.paintHighlight(context, style)
+
+	x := float64(LIST_ITEM_PADDING)
+	context.SetFillStyle(style.TextColor)
+	context.SetStrokeStyle(style.TextColor)
+	if item.t == TYPE_LIST_ITEM_RADIO {
+		r := math.Min(7, h/2-2)
+		context.SetLineWidth(1)
+		context.BeginPath()
+		context.Arc(x+r, h/2, r-0.5, 0, 2*math.Pi, false)
+		context.Stroke()
+		if item.selected {
+			context.BeginPath()
+			context.Arc(x+r, h/2, r/2, 0, 2*math.Pi, false)
+			context.Fill()
+		}
+		x += 2*r + LIST_ITEM_PADDING
+	}
+
+	if text := item.GetText(); len(text) > 0 {
+		context.SetFont(style.Font)
+		context.SetTextAlign("left")
+		context.SetTextBaseline("middle")
+		context.FillText(text, x, h/2, w-x-LIST_ITEM_PADDING)
+	}
+
+	return
+}
+
+// ListView shows the rows of a ListDataSource in a vertically scrolled list.
+// Only the visible rows get an item widget, so lists of any length cost the
+// same to lay out and paint. Selection follows the usual conventions: Ctrl
+// toggles and Shift extends in LIST_SELECTION_MULTIPLE mode. The changed
+// handler receives the sorted []int of selected rows.
+type ListView struct {
+	*ScrollView
+	itemSelection
+	itemView
+	owner      listViewOwner
+	content    *listViewContent
+	source     ListDataSource
+	itemHeight int
+}
+
+func NewListView(parent *Widget, x, y, w, h float32) *ListView {
+	view := &ListView{
+		ScrollView:    NewScrollView(parent, x, y, w, h),
+		itemSelection: newItemSelection(),
+		itemHeight:    LIST_VIEW_ITEM_HEIGHT,
+	}
+	view.itemView = newItemView(view.ScrollView, &view.itemSelection, view)
+	view.t = TYPE_LIST_VIEW
+	view.SetScrollType(SCROLL_TYPE_V)
+	view.owner = view
+	view.I = view
+
+	view.content = &listViewContent{
+		Widget: NewWidget(TYPE_VIEW_BASE, view.Widget, 0, 0, 0, 0),
+		view:   view,
+	}
+	view.content.I = view.content
+	view.updateLayout()
+
+	return view
+}
+
+func (view *ListView) SetDataSource(source ListDataSource) *ListView {
+	view.source = source
+	view.resetItems()
+
+	return view.Reload()
+}
+
+func (view *ListView) GetDataSource() ListDataSource {
+	return view.source
+}
+
+// Reload rereads the row count and renders the visible rows again. Call it
+// whenever the data behind the source changes.
+func (view *ListView) Reload() *ListView {
+	view.reloadItems()
+
+	return view
+}
+
+func (view *ListView) SetItemHeight(itemHeight int) *ListView {
+	view.itemHeight = int(math.Max(1, float64(itemHeight)))
+
+	return view.Reload()
+}
+
+func (view *ListView) GetItemHeight() int {
+	return view.itemHeight
+}
+
+// SetSelectionMode changes how rows are selected. Switching to a single
+// selection mode keeps only the current or first selected row.
+func (view *ListView) SetSelectionMode(mode ListSelectionMode) *ListView {
+	view.setMode(mode)
+	t := view.owner.getItemType()
+	view.eachItem(func(item viewItem) {
+		item.(*ListItem).t = t
+	})
+	view.PostRedraw()
+
+	return view
+}
+
+// SetActivatedHandler sets the handler called when a row is double clicked or
+// Enter is pressed on it.
+func (view *ListView) SetActivatedHandler(onActivated ListActivatedHandler) *ListView {
+	view.onActivated = onActivated
+
+	return view
+}
+
+func (view *ListView) SetCurrent(index int) *ListView {
+	view.setCurrent(index)
+
+	return view
+}
+
+// SetSelected selects or deselects a row. Outside of
+// LIST_SELECTION_MULTIPLE mode selecting a row deselects the others.
+func (view *ListView) SetSelected(index int, selected, notify bool) *ListView {
+	view.selectItem(index, selected, notify)
+
+	return view
+}
+
+func (view *ListView) SelectAll(notify bool) *ListView {
+	view.selectAllItems(notify)
+
+	return view
+}
+
+func (view *ListView) ClearSelection(notify bool) *ListView {
+	view.clearSelection(notify)
+
+	return view
+}
+
+// EnsureVisible scrolls the least needed to show row index.
+func (view *ListView) EnsureVisible(index int) *ListView {
+	view.ensureVisible(index)
+
+	return view
+}
+
+func (view *ListView) getCount() int {
+	if view.source == nil {
+		return 0
+	}
+
+	return view.source.GetCount()
+}
+
+func (view *ListView) getItemSpan(index int) (top, bottom int) {
+	top = index * view.itemHeight
+
+	return top, top + view.itemHeight
+}
+
+func (view *ListView) getPageHeight() int {
+	return view.getViewHeight()
+}
+
+func (view *ListView) getPageSize() int {
+	return int(math.Max(1, float64(view.getViewHeight()/view.itemHeight)))
+}
+
+// getVisibleRange returns the rows from first up to, excluding, last that
+// intersect the view.
+func (view *ListView) getVisibleRange() (first, last int) {
+	top := int(view.getYOffset())
+	first = top / view.itemHeight
+	last = int(math.Min(float64(view.getCount()), float64((top+view.getViewHeight())/view.itemHeight+1)))
+
+	return first, last
+}
+
+// updateLayout sizes the content child to all rows and refreshes the scroll
+// bar.
+func (view *ListView) updateLayout() {
+	width := view.rect.W - int(view.scrollBarSize)
+	height := view.getCount() * view.itemHeight
+	view.resizeContent(view.content.Widget, width, int(math.Max(float64(height), float64(view.rect.H))))
+
+	return
+}
+
+func (view *ListView) relayout(context canvas.Canvas2D, force bool) {
+	if view.needRelayout || force {
+		view.updateLayout()
+	}
+
+	return
+}
+
+// bindItems recycles the items of rows that scrolled out and binds items to
+// the rows that scrolled in.
+func (view *ListView) bindItems() {
+	first, last := view.getVisibleRange()
+	view.recycleItems(func(key interface{}) bool {
+		return key.(int) >= first && key.(int) < last
+	})
+
+	for i := first; i < last; i++ {
+		recycled, bound := view.bindItem(i, view.newItem)
+		item := recycled.(*ListItem)
+		item.index = i
+
+		if !bound || view.dirty {
+			item.Move(0, i*view.itemHeight)
+			item.Resize(view.content.rect.W, view.itemHeight)
+			item.SetVisible(true)
+			view.source.RenderItem(item, i)
+		}
+		item.selected = view.selected[i]
+	}
+	view.dirty = false
+
+	return
+}
+
+func (view *ListView) newItem() viewItem {
+	item := &ListItem{
+		Widget: NewWidget(view.owner.getItemType(), view.content.Widget, 0, 0, 0, 0),
+		view:   view,
+	}
+	item.selectable = true
+	item.I = item
+
+	return item
+}
+
+func (view *ListView) getItemType() string {
+	if view.selectionMode == LIST_SELECTION_RADIO {
+		return TYPE_LIST_ITEM_RADIO
+	}
+
+	return TYPE_LIST_ITEM
+}
+
+func (view *ListView) getIndexAtPoint(point *structs.Point) int {
+	p := view.content.translatePoint(point)
+	index := int(math.Floor(float64(p.Y) / float64(view.itemHeight)))
+	if index < 0 || index >= view.getCount() {
+		return -1
+	}
+
+	return index
+}
+
+func (view *ListView) onContentPointerDown(point *structs.Point) {
+	if !view.enable {
+		return
+	}
+
+	if window := view.GetWindow(); window != nil {
+		window.SetFocus(view.Widget)
+	}
+
+	if index := view.getIndexAtPoint(point); index >= 0 {
+		view.pressItem(index)
+	}
+
+	return
+}
+
+func (view *ListView) onContentPointerMove(point *structs.Point) {
+	return
+}
+
+func (view *ListView) onContentPointerUp(point *structs.Point) {
+	return
+}
+
+func (view *ListView) onContentDoubleClick(point *structs.Point) {
+	view.activate(view.getIndexAtPoint(point))
+
+	return
+}
+
+func (view *ListView) onKeyDown(code int) {
+	if !view.enable {
+		return
+	}
+	view.onItemKeyDown(code)
+
+	if view.keyDownHandler != nil {
+		view.keyDownHandler(code)
+	}
+
+	return
+}
+
+func (content *listViewContent) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (content *listViewContent) paintChildren(context canvas.Canvas2D) {
+	view := content.view
+	if view.source == nil {
+		return
+	}
+	view.bindItems()
+
+	context.Save()
+	context.BeginPath()
+	context.Rect(0, view.getYOffset(), float64(content.rect.W), float64(view.getViewHeight()))
+	context.Clip()
+	content.Widget.paintChildren(context)
+	context.Restore()
+
+	return
+}
+
+func (content *listViewContent) onPointerDown(point *structs.Point) {
+	content.view.owner.onContentPointerDown(point)
+	content.Widget.onPointerDown(point)
+
+	return
+}
+
+func (content *listViewContent) onPointerMove(point *structs.Point) {
+	content.view.owner.onContentPointerMove(point)
+	content.Widget.onPointerMove(point)
+
+	return
+}
+
+func (content *listViewContent) onPointerUp(point *structs.Point) {
+	content.view.owner.onContentPointerUp(point)
+	content.Widget.onPointerUp(point)
+
+	return
+}
+
+func (content *listViewContent) onDoubleClick(point *structs.Point) {
+	content.view.owner.onContentDoubleClick(point)
+	content.Widget.onDoubleClick(point)
+
+	return
+}
@@ -0,0 +1,172 @@
+package gwk
+
+import (
+	"fmt"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"reflect"
+	"testing"
+)
+
+// newListViewScene returns a ListView 200x240, ten rows high, showing count
+// rows named "row <index>", with the changes of its selection.
+func newListViewScene(count int) (typist, *ListView, *[][]int) {
+	source := make(StringListSource, count)
+	for i := range source {
+		source[i] = fmt.Sprintf("row %d", i)
+	}
+
+	var view *ListView
+	k := newScene(200, 240, func(win *Window) {
+		view = NewListView(win.Widget, 0, 0, 200, 240).SetDataSource(source)
+	})
+	changes := &[][]int{}
+	view.SetChangedHandler(func(value interface{}) {
+		*changes = append(*changes, value.([]int))
+	})
+
+	return k, view, changes
+}
+
+// clickRow clicks row index, which must be among the first rows shown.
+func clickRow(k typist, view *ListView, index int) {
+	k.click(50, index*view.itemHeight+view.itemHeight/2)
+
+	return
+}
+
+func TestListViewVirtualization(t *testing.T) {
+	k, view, _ := newListViewScene(100000)
+
+	check := func(name string, first int) {
+		t.Helper()
+
+		k.m.Snapshot()
+		if n := len(view.items) + len(view.freeItems); n > 12 {
+			t.Errorf("%s: %d item widgets for ten visible rows", name, n)
+		}
+		if _, ok := view.items[first]; !ok {
+			t.Errorf("%s: row %d has no item", name, first)
+		}
+		for key, item := range view.items {
+			listItem := item.(*ListItem)
+			if listItem.index != key || listItem.GetText() != fmt.Sprintf("row %d", key) {
+				t.Errorf("%s: item of row %d shows row %d as %q", name, key, listItem.index, listItem.GetText())
+			}
+			if top := listItem.rect.Y; top != listItem.index*view.itemHeight {
+				t.Errorf("%s: row %d at %d, want %d", name, key, top, listItem.index*view.itemHeight)
+			}
+		}
+	}
+
+	check("top", 0)
+	view.EnsureVisible(50000)
+	check("middle", 49991)
+	k.click(50, 5)
+	k.key(keyevent.DOM_VK_END)
+	check("end", 99990)
+
+	view.SetDataSource(StringListSource{"row 0", "row 1"})
+	k.m.Snapshot()
+	if len(view.items) != 2 {
+		t.Errorf("%d items bound for two rows", len(view.items))
+	}
+}
+
+func TestListViewSelectionModes(t *testing.T) {
+	ctrl := func(k typist, press func()) {
+		k.hold(keyevent.DOM_VK_CONTROL, press)
+	}
+	shift := func(k typist, press func()) {
+		k.hold(keyevent.DOM_VK_SHIFT, press)
+	}
+
+	cases := []struct {
+		name     string
+		mode     ListSelectionMode
+		press    func(k typist, view *ListView)
+		selected []int
+	}{
+		{"single", LIST_SELECTION_SINGLE, func(k typist, view *ListView) {
+			clickRow(k, view, 1)
+			ctrl(k, func() { clickRow(k, view, 3) })
+		}, []int{3}},
+		{"single keys", LIST_SELECTION_SINGLE, func(k typist, view *ListView) {
+			clickRow(k, view, 1)
+			k.key(keyevent.DOM_VK_DOWN, keyevent.DOM_VK_DOWN)
+			k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_A)
+		}, []int{3}},
+		{"multiple", LIST_SELECTION_MULTIPLE, func(k typist, view *ListView) {
+			clickRow(k, view, 1)
+			ctrl(k, func() { clickRow(k, view, 3) })
+			shift(k, func() { clickRow(k, view, 6) })
+			ctrl(k, func() { clickRow(k, view, 4) })
+		}, []int{3, 5, 6}},
+		{"multiple shift keys", LIST_SELECTION_MULTIPLE, func(k typist, view *ListView) {
+			clickRow(k, view, 5)
+			k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_UP, keyevent.DOM_VK_UP)
+		}, []int{3, 4, 5}},
+		{"multiple ctrl+a", LIST_SELECTION_MULTIPLE, func(k typist, view *ListView) {
+			k.click(50, 5)
+			k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_A)
+		}, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"none", LIST_SELECTION_NONE, func(k typist, view *ListView) {
+			clickRow(k, view, 2)
+			k.key(keyevent.DOM_VK_SPACE)
+		}, []int{}},
+		{"radio", LIST_SELECTION_RADIO, func(k typist, view *ListView) {
+			clickRow(k, view, 2)
+			ctrl(k, func() { clickRow(k, view, 4) })
+			k.key(keyevent.DOM_VK_SPACE)
+		}, []int{4}},
+	}
+	for _, c := range cases {
+		k, view, changes := newListViewScene(8)
+		view.SetSelectionMode(c.mode)
+		c.press(k, view)
+
+		if selected := view.GetSelected(); !reflect.DeepEqual(selected, c.selected) {
+			t.Errorf("%s: selected %v, want %v", c.name, selected, c.selected)
+		}
+		if n := len(*changes); n > 0 && !reflect.DeepEqual((*changes)[n-1], c.selected) {
+			t.Errorf("%s: last change %v, want %v", c.name, (*changes)[n-1], c.selected)
+		} else if n == 0 && len(c.selected) > 0 {
+			t.Errorf("%s: selection changed without notifying", c.name)
+		}
+	}
+}
+
+func TestListViewSelectionModeSwitch(t *testing.T) {
+	k, view, _ := newListViewScene(8)
+	view.SetSelectionMode(LIST_SELECTION_MULTIPLE)
+	clickRow(k, view, 2)
+	k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_DOWN, keyevent.DOM_VK_DOWN)
+
+	view.SetSelectionMode(LIST_SELECTION_RADIO)
+	if selected := view.GetSelected(); !reflect.DeepEqual(selected, []int{2}) {
+		t.Errorf("switching to radio kept %v, want [2]", selected)
+	}
+	view.eachItem(func(item viewItem) {
+		if item.(*ListItem).t != TYPE_LIST_ITEM_RADIO {
+			t.Errorf("item of a radio list is a %s", item.(*ListItem).t)
+		}
+	})
+
+	view.SetSelectionMode(LIST_SELECTION_NONE)
+	if selected := view.GetSelected(); len(selected) != 0 {
+		t.Errorf("switching to none kept %v", selected)
+	}
+}
+
+func TestListViewActivate(t *testing.T) {
+	k, view, _ := newListViewScene(8)
+	var activated []int
+	view.SetActivatedHandler(func(index int) {
+		activated = append(activated, index)
+	})
+
+	clickRow(k, view, 3)
+	k.key(keyevent.DOM_VK_RETURN)
+	if !reflect.DeepEqual(activated, []int{3}) {
+		t.Errorf("activated %v, want [3]", activated)
+	}
+}
@@ -0,0 +1,747 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+)
+
+const (
+	MENU_PADDING          = 4
+	MENU_ITEM_HEIGHT      = 26
+	MENU_SEPARATOR_HEIGHT = 9
+	MENU_CHECK_WIDTH      = 24
+	MENU_ARROW_WIDTH      = 20
+	MENU_SPACER           = 24
+	MENU_MIN_WIDTH        = 120
+	MENU_FILL_COLOR       = "#FFFFFF"
+	MENU_LINE_COLOR       = "#A0A0A0"
+	MENU_HIGHLIGHT_COLOR  = "#B4D5FE"
+)
+
+// MenuItem is an entry of a Menu: a command, a checkable command, a submenu
+// or a separator. Commands report activation through the clicked handler;
+// SetCheckEnable binds the enabled state to a callback that is polled every
+// time the menu pops up.
+type MenuItem struct {
+	*Widget
+	menu        *Menu
+	submenu     *Menu
+	accelerator string
+	separator   bool
+	checked     bool
+}
+
+func newMenuItem(menu *Menu, text string) *MenuItem {
+	item := &MenuItem{
+		Widget: NewWidget(menu.itemType, menu.Widget, 0, 0, 0, MENU_ITEM_HEIGHT),
+		menu:   menu,
+	}
+	item.I = item
+	item.SetText(text, false)
+	menu.items = append(menu.items, item)
+
+	return item
+}
+
+func (item *MenuItem) GetMenu() *Menu {
+	return item.menu
+}
+
+func (item *MenuItem) GetSubmenu() *Menu {
+	return item.submenu
+}
+
+func (item *MenuItem) IsSeparator() bool {
+	return item.separator
+}
+
+// SetAccelerator sets the shortcut text shown at the right of the item, such
+// as "Ctrl+S". It is only displayed; the application handles the keys.
+func (item *MenuItem) SetAccelerator(accelerator string) *MenuItem {
+	item.accelerator = accelerator
+
+	return item
+}
+
+func (item *MenuItem) GetAccelerator() string {
+	return item.accelerator
+}
+
+// SetCheckable makes activating the item toggle its checked state before the
+// clicked handler runs.
+func (item *MenuItem) SetCheckable(checkable bool) *MenuItem {
+	item.checkable = checkable
+
+	return item
+}
+
+func (item *MenuItem) IsCheckable() bool {
+	return item.checkable
+}
+
+func (item *MenuItem) SetChecked(checked bool) *MenuItem {
+	item.checked = checked
+	item.PostRedraw()
+
+	return item
+}
+
+func (item *MenuItem) IsChecked() bool {
+	return item.checked
+}
+
+func (item *MenuItem) isSelectable() bool {
+	return item.visible && item.enable && !item.separator
+}
+
+// paintBackground is empty: the menu paints the background and paintSelf the
+// highlight.
+func (item *MenuItem) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (item *MenuItem) paintSelf(context canvas.Canvas2D) {
+	w := float64(item.rect.W)
+	h := float64(item.rect.H)
+
+	if item.separator {
+		style := item.getStyle(STATE_NORMAL)
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+		context.BeginPath()
+		context.MoveTo(MENU_PADDING, math.Floor(h/2)+0.5)
+		context.LineTo(w-MENU_PADDING, math.Floor(h/2)+0.5)
+		context.Stroke()
+		return
+	}
+
+	style := item.getStyle("")
+	if item.menu.current == item && item.enable {
+		style = item.getStyle(STATE_OVER)
+		context.SetFillStyle(colorOr(style.FillColor, MENU_HIGHLIGHT_COLOR))
+		context.FillRect(0, 0, w, h)
+	}
+
+	context.SetFont(style.Font)
+	context.SetFillStyle(style.TextColor)
+	context.SetStrokeStyle(style.TextColor)
+	context.SetTextBaseline("middle")
+
+	if item.checkable && item.checked {
+		x := float64(MENU_CHECK_WIDTH-12) / 2
+		y := h/2 - 6
+		context.SetLineWidth(2)
+		context.BeginPath()
+		context.MoveTo(x+2, y+6)
+		context.LineTo(x+5, y+9)
+		context.LineTo(x+10, y+3)
+		context.Stroke()
+	}
+
+	context.SetTextAlign("left")
+	context.FillText(item.GetText(), MENU_CHECK_WIDTH, h/2, w-MENU_CHECK_WIDTH-MENU_ARROW_WIDTH)
+
+	if len(item.accelerator) > 0 {
+		context.SetTextAlign("right")
+		context.FillText(item.accelerator, w-MENU_ARROW_WIDTH, h/2, w)
+	}
+
+	if item.submenu != nil {
+		x := w - MENU_ARROW_WIDTH/2
+		context.BeginPath()
+		context.MoveTo(x-3, h/2-5)
+		context.LineTo(x+3, h/2)
+		context.LineTo(x-3, h/2+5)
+		context.ClosePath()
+		context.Fill()
+	}
+
+	return
+}
+
+// Menu is a popup window listing MenuItems. It stays out of the window stack
+// until Popup is called and grabs all input while it is shown: clicking
+// outside of it, Escape or activating an item dismisses it. Submenus open on
+// hover, on click and with the Right key.
+type Menu struct {
+	*Window
+	itemType   string
+	items      []*MenuItem
+	current    *MenuItem
+	submenu    *Menu
+	parentMenu *Menu
+	menuBar    *MenuBar
+	shown      bool
+}
+
+func NewMenu() *Menu {
+	return newMenu(TYPE_MENU, TYPE_MENU_ITEM)
+}
+
+// NewContextMenu creates a menu styled as a context menu, to be attached to
+// a widget with Widget.SetContextMenu.
+func NewContextMenu() *Menu {
+	return newMenu(TYPE_CONTEXT_MENU_BAR, TYPE_CONTEXT_MENU_ITEM)
+}
+
+func newMenu(t, itemType string) *Menu {
+	menu := &Menu{
+		Window:   newPopupWindow(nil, 0, 0, MENU_MIN_WIDTH, 2*MENU_PADDING),
+		itemType: itemType,
+	}
+	menu.t = t
+	menu.I = menu
+
+	return menu
+}
+
+func (menu *Menu) AddItem(text string, onClicked ClickedHandler) *MenuItem {
+	item := newMenuItem(menu, text)
+	item.SetClickedHandler(onClicked)
+
+	return item
+}
+
+func (menu *Menu) AddCheckItem(text string, checked bool, onClicked ClickedHandler) *MenuItem {
+	return menu.AddItem(text, onClicked).SetCheckable(true).SetChecked(checked)
+}
+
+func (menu *Menu) AddSubmenu(text string, submenu *Menu) *MenuItem {
+	item := newMenuItem(menu, text)
+	item.submenu = submenu
+
+	return item
+}
+
+func (menu *Menu) AddSeparator() *MenuItem {
+	item := newMenuItem(menu, "")
+	item.separator = true
+	item.rect.H = MENU_SEPARATOR_HEIGHT
+
+	return item
+}
+
+func (menu *Menu) GetItems() []*MenuItem {
+	return menu.items
+}
+
+func (menu *Menu) IsShown() bool {
+	return menu.shown
+}
+
+// Popup shows the menu with its top left corner at x, y in window manager
+// coordinates, moving it as needed to keep it on screen.
+func (menu *Menu) Popup(x, y int) *Menu {
+	if menu.shown {
+		menu.Dismiss()
+	}
+
+	manager := menu.manager
+	menu.layout()
+	x = int(math.Max(0, math.Min(float64(x), float64(manager.w-menu.rect.W))))
+	y = int(math.Max(0, math.Min(float64(y), float64(manager.h-menu.rect.H))))
+	menu.Move(x, y)
+
+	menu.shown = true
+	menu.current = nil
+	menu.menuBar = nil
+	manager.addWindow(menu.Window)
+	manager.Grab(menu.Window)
+
+	return menu
+}
+
+// Dismiss closes the menu together with its open submenus and, for a submenu,
+// the menus it was opened from.
+func (menu *Menu) Dismiss() *Menu {
+	root := menu.getRoot()
+	root.hide()
+
+	if bar := root.menuBar; bar != nil && bar.openMenu == root {
+		bar.onMenuClosed()
+	}
+
+	return menu
+}
+
+func (menu *Menu) hide() {
+	if !menu.shown {
+		return
+	}
+
+	menu.closeSubmenu()
+	menu.shown = false
+	menu.current = nil
+	menu.manager.Ungrab(menu.Window)
+	menu.manager.removeWindow(menu.Window)
+	if menu.parentMenu != nil {
+		menu.parentMenu.submenu = nil
+		menu.parentMenu = nil
+	}
+
+	return
+}
+
+func (menu *Menu) getRoot() *Menu {
+	root := menu
+	for root.parentMenu != nil {
+		root = root.parentMenu
+	}
+
+	return root
+}
+
+// layout refreshes the enabled state of the items and stacks them, making the
+// menu as wide as its widest item.
+func (menu *Menu) layout() {
+	context := menu.getCanvas2D()
+	width := MENU_MIN_WIDTH
+
+	context.Save()
+	for _, item := range menu.items {
+		if item.checkEnable != nil {
+			item.SetEnable(item.checkEnable())
+		}
+
+		if item.separator || !item.visible {
+			continue
+		}
+
+		context.SetFont(item.getStyle("").Font)
+		w := MENU_CHECK_WIDTH + int(context.MeasureText(item.GetText()).Width) + MENU_ARROW_WIDTH
+		if len(item.accelerator) > 0 {
+			w += MENU_SPACER + int(context.MeasureText(item.accelerator).Width)
+		}
+		width = int(math.Max(float64(width), float64(w)))
+	}
+	context.Restore()
+
+	y := MENU_PADDING
+	for _, item := range menu.items {
+		if !item.visible {
+			continue
+		}
+
+		item.Move(1, y)
+		item.Resize(width, item.rect.H)
+		y += item.rect.H
+	}
+	menu.Resize(width+2, y+MENU_PADDING)
+
+	return
+}
+
+func (menu *Menu) itemAt(point *structs.Point) *MenuItem {
+	p := menu.translatePoint(point)
+	for _, item := range menu.items {
+		if item.visible && isPointInRect(p, item.rect) {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// menuAt returns the menu of the open chain, starting from the deepest one,
+// that contains point.
+func (menu *Menu) menuAt(point *structs.Point) *Menu {
+	deepest := menu
+	for deepest.submenu != nil {
+		deepest = deepest.submenu
+	}
+
+	for iter := deepest; iter != nil; iter = iter.parentMenu {
+		if isPointInRect(point, iter.rect) {
+			return iter
+		}
+	}
+
+	return nil
+}
+
+func (menu *Menu) setCurrent(item *MenuItem) {
+	if menu.current != item {
+		menu.current = item
+		menu.PostRedraw()
+	}
+
+	return
+}
+
+// selectNext highlights the next selectable item in direction delta,
+// wrapping around.
+func (menu *Menu) selectNext(delta int) {
+	n := len(menu.items)
+	i := -1
+	for k, item := range menu.items {
+		if item == menu.current {
+			i = k
+			break
+		}
+	}
+
+	if i < 0 && delta < 0 {
+		i = n
+	}
+
+	for k := 1; k <= n; k++ {
+		item := menu.items[((i+delta*k)%n+n)%n]
+		if item.isSelectable() {
+			menu.setCurrent(item)
+			break
+		}
+	}
+
+	return
+}
+
+func (menu *Menu) openSubmenu(item *MenuItem) {
+	submenu := item.submenu
+	if menu.submenu == submenu {
+		return
+	}
+
+	menu.closeSubmenu()
+	if submenu.shown {
+		submenu.Dismiss()
+	}
+
+	p := item.GetAbsPosition()
+	submenu.Popup(p.X+item.rect.W, p.Y-MENU_PADDING)
+	if submenu.rect.X < p.X+item.rect.W {
+		submenu.Move(int(math.Max(0, float64(menu.rect.X-submenu.rect.W))), submenu.rect.Y)
+	}
+	submenu.parentMenu = menu
+	menu.submenu = submenu
+
+	return
+}
+
+func (menu *Menu) closeSubmenu() {
+	if menu.submenu != nil {
+		menu.submenu.hide()
+	}
+
+	return
+}
+
+// trackPointer highlights the item under point and opens or closes submenus
+// to match it.
+func (menu *Menu) trackPointer(point *structs.Point) {
+	item := menu.itemAt(point)
+	if item != nil && !item.isSelectable() {
+		item = nil
+	}
+
+	if item == nil && menu.submenu != nil {
+		return
+	}
+
+	menu.setCurrent(item)
+	if item != nil && item.submenu != nil {
+		menu.openSubmenu(item)
+	} else {
+		menu.closeSubmenu()
+	}
+
+	return
+}
+
+func (menu *Menu) activate(item *MenuItem) {
+	if item == nil || !item.isSelectable() {
+		return
+	}
+
+	if item.submenu != nil {
+		menu.openSubmenu(item)
+		item.submenu.selectNext(1)
+		return
+	}
+
+	menu.Dismiss()
+	if item.checkable {
+		item.SetChecked(!item.checked)
+	}
+	item.onClicked(item.GetAbsPosition())
+
+	return
+}
+
+func (menu *Menu) onPointerDown(point *structs.Point) {
+	if target := menu.menuAt(point); target != nil {
+		target.trackPointer(point)
+		return
+	}
+
+	root := menu.getRoot()
+	if bar := root.menuBar; bar != nil {
+		if item := bar.itemAt(point); item != nil {
+			if item == bar.openItem {
+				root.Dismiss()
+			} else {
+				bar.open(item)
+			}
+			return
+		}
+	}
+	root.Dismiss()
+
+	return
+}
+
+func (menu *Menu) onPointerMove(point *structs.Point) {
+	if target := menu.menuAt(point); target != nil {
+		target.trackPointer(point)
+		return
+	}
+
+	root := menu.getRoot()
+	if bar := root.menuBar; bar != nil {
+		if item := bar.itemAt(point); item != nil && item != bar.openItem {
+			bar.open(item)
+			return
+		}
+	}
+
+	if menu.current != nil && menu.current.submenu == nil {
+		menu.setCurrent(nil)
+	}
+
+	return
+}
+
+func (menu *Menu) onPointerUp(point *structs.Point) {
+	if target := menu.menuAt(point); target != nil {
+		if item := target.itemAt(point); item != nil && item.submenu == nil {
+			target.activate(item)
+		}
+	}
+
+	return
+}
+
+func (menu *Menu) onContextMenu(point *structs.Point) {
+	menu.Dismiss()
+
+	return
+}
+
+func (menu *Menu) onKeyDown(code int) {
+	root := menu.getRoot()
+
+	switch code {
+	case keyevent.DOM_VK_UP:
+		menu.selectNext(-1)
+	case keyevent.DOM_VK_DOWN:
+		menu.selectNext(1)
+	case keyevent.DOM_VK_RIGHT:
+		if item := menu.current; item != nil && item.submenu != nil {
+			menu.activate(item)
+		} else if root.menuBar != nil {
+			root.menuBar.openNext(1)
+		}
+	case keyevent.DOM_VK_LEFT:
+		if menu.parentMenu != nil {
+			menu.parentMenu.closeSubmenu()
+		} else if root.menuBar != nil {
+			root.menuBar.openNext(-1)
+		}
+	case keyevent.DOM_VK_RETURN, keyevent.DOM_VK_SPACE:
+		menu.activate(menu.current)
+	case keyevent.DOM_VK_ESCAPE:
+		if menu.parentMenu != nil {
+			menu.parentMenu.closeSubmenu()
+		} else {
+			menu.Dismiss()
+		}
+	}
+
+	return
+}
+
+func (menu *Menu) paintBackground(context canvas.Canvas2D) {
+	style := menu.getStyle("")
+	w := float64(menu.rect.W)
+	h := float64(menu.rect.H)
+
+	context.SetFillStyle(colorOr(style.FillColor, MENU_FILL_COLOR))
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.Rect(0.5, 0.5, w-1, h-1)
+	context.Stroke()
+
+	return
+}
+
+// SetContextMenu pops menu up at the pointer when the widget is right
+// clicked. Pass nil to remove it.
+func (w *Widget) SetContextMenu(menu *Menu) *Widget {
+	if menu == nil {
+		return w.SetContextMenuHandler(nil)
+	}
+
+	return w.SetContextMenuHandler(func(point *structs.Point) {
+		menu.Popup(point.X+1, point.Y+1)
+	})
+}
+
+// MenuBarItem is a title of a MenuBar, opening its menu below itself.
+type MenuBarItem struct {
+	*Widget
+	bar  *MenuBar
+	menu *Menu
+}
+
+func (item *MenuBarItem) GetMenu() *Menu {
+	return item.menu
+}
+
+func (item *MenuBarItem) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (item *MenuBarItem) paintSelf(context canvas.Canvas2D) {
+	style := item.getStyle("")
+	w := float64(item.rect.W)
+	h := float64(item.rect.H)
+
+	if item.enable && (item.bar.openItem == item || item.state == STATE_OVER) {
+		style = item.getStyle(STATE_OVER)
+		context.SetFillStyle(colorOr(style.FillColor, MENU_HIGHLIGHT_COLOR))
+		context.FillRect(0, 0, w, h)
+	}
+
+	context.SetFont(style.Font)
+	context.SetFillStyle(style.TextColor)
+	context.SetTextAlign("center")
+	context.SetTextBaseline("middle")
+	context.FillText(item.GetText(), w/2, h/2, w)
+
+	return
+}
+
+// MenuBar lays out menu titles from left to right. Clicking a title opens its
+// menu; while a menu is open, hovering another title or pressing Left and
+// Right switches to its menu.
+type MenuBar struct {
+	*Widget
+	items    []*MenuBarItem
+	openItem *MenuBarItem
+	openMenu *Menu
+}
+
+func NewMenuBar(parent *Widget, x, y, w, h float32) *MenuBar {
+	bar := &MenuBar{
+		Widget: NewWidget(TYPE_MENU_BAR, parent, x, y, w, h),
+	}
+	bar.I = bar
+
+	return bar
+}
+
+// AddMenu appends a title opening menu.
+func (bar *MenuBar) AddMenu(text string, menu *Menu) *MenuBarItem {
+	x := 0
+	if n := len(bar.items); n > 0 {
+		last := bar.items[n-1]
+		x = last.rect.X + last.rect.W
+	}
+
+	item := &MenuBarItem{
+		Widget: NewWidget(TYPE_MENU_BAR_ITEM, bar.Widget, float32(x), 0, 0, float32(bar.rect.H)),
+		bar:    bar,
+		menu:   menu,
+	}
+	item.I = item
+	item.SetText(text, false)
+
+	context := bar.getCanvas2D()
+	context.Save()
+	context.SetFont(item.getStyle("").Font)
+	item.rect.W = int(context.MeasureText(text).Width) + MENU_SPACER
+	context.Restore()
+
+	bar.items = append(bar.items, item)
+
+	return item
+}
+
+func (bar *MenuBar) GetItems() []*MenuBarItem {
+	return bar.items
+}
+
+func (bar *MenuBar) itemAt(point *structs.Point) *MenuBarItem {
+	p := bar.translatePoint(point)
+	for _, item := range bar.items {
+		if item.visible && isPointInRect(p, item.rect) {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func (bar *MenuBar) open(item *MenuBarItem) {
+	if bar.openMenu != nil {
+		bar.openMenu.Dismiss()
+	}
+
+	if item == nil || !item.enable || item.menu == nil {
+		return
+	}
+
+	p := item.GetAbsPosition()
+	bar.openItem = item
+	bar.openMenu = item.menu
+	item.menu.Popup(p.X, p.Y+item.rect.H)
+	item.menu.menuBar = bar
+	bar.PostRedraw()
+
+	return
+}
+
+// openNext opens the menu of the next enabled title in direction delta and
+// highlights its first item.
+func (bar *MenuBar) openNext(delta int) {
+	n := len(bar.items)
+	i := -1
+	for k, item := range bar.items {
+		if item == bar.openItem {
+			i = k
+			break
+		}
+	}
+
+	for k := 1; k < n; k++ {
+		item := bar.items[((i+delta*k)%n+n)%n]
+		if item.visible && item.enable && item.menu != nil {
+			bar.open(item)
+			item.menu.selectNext(1)
+			break
+		}
+	}
+
+	return
+}
+
+func (bar *MenuBar) onMenuClosed() {
+	if bar.openItem != nil {
+		bar.openItem.setState(STATE_NORMAL, false)
+	}
+	bar.openItem = nil
+	bar.openMenu = nil
+	bar.PostRedraw()
+
+	return
+}
+
+func (bar *MenuBar) onPointerDown(point *structs.Point) {
+	bar.Widget.onPointerDown(point)
+	bar.open(bar.itemAt(point))
+
+	return
+}
@@ -0,0 +1,44 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/event"
+	"testing"
+)
+
+func TestBuildingPopupsKeepsHover(t *testing.T) {
+	var win *Window
+	var button *Button
+	var combo *ComboBox
+	var colorButton *ColorButton
+	k := newScene(300, 100, func(w *Window) {
+		win = w
+		button = NewButton(w.Widget, 10, 10, 80, 30)
+		combo = NewComboBox(w.Widget, 100, 10, 80, 30)
+		colorButton = NewColorButton(w.Widget, 200, 10, 80, 30)
+	})
+	k.pointer(event.EVENT_POINTER_MOVE, 40, 20)
+	if button.state != STATE_OVER {
+		t.Fatalf("hovered button in %s", button.state)
+	}
+
+	builders := []struct {
+		name  string
+		build func() *Window
+	}{
+		{"menu", func() *Window { return NewMenu().Window }},
+		{"context menu", func() *Window { return NewContextMenu().Window }},
+		{"combo box popup", func() *Window { return newComboBoxPopup(combo).Window }},
+		{"color picker", func() *Window { return newColorPicker(colorButton).Window }},
+	}
+	for _, b := range builders {
+		popup := b.build()
+		if button.state != STATE_OVER || k.m.target != win {
+			t.Errorf("building a %s left the button in %s", b.name, button.state)
+		}
+		for _, window := range k.m.windows {
+			if window == popup {
+				t.Errorf("building a %s added it to the windows", b.name)
+			}
+		}
+	}
+}
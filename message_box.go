@@ -0,0 +1,281 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/image"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"github.com/Luncher/gwk/pkg/theme"
+	"math"
+)
+
+const (
+	MESSAGE_BOX_WIDTH         = 360
+	MESSAGE_BOX_PADDING       = 12
+	MESSAGE_BOX_ICON_SIZE     = 32
+	MESSAGE_BOX_BUTTON_WIDTH  = 80
+	MESSAGE_BOX_BUTTON_HEIGHT = 30
+	MESSAGE_BOX_EDIT_HEIGHT   = 28
+	MESSAGE_BOX_BAR_HEIGHT    = 16
+	MESSAGE_BOX_ICON_INFO     = "info"
+	MESSAGE_BOX_ICON_QUESTION = "question"
+	MESSAGE_BOX_ICON_WARNING  = "warning"
+)
+
+// Button captions used by the message boxes, replace them to localize.
+var (
+	MESSAGE_BOX_OK_TEXT     = "OK"
+	MESSAGE_BOX_CANCEL_TEXT = "Cancel"
+)
+
+type AlertHandler func()
+type ConfirmHandler func(ok bool)
+type PromptHandler func(text string, ok bool)
+
+// MessageBox is a modal Dialog showing an icon and a wrapped message, with
+// an optional text entry or progress bar above its buttons. Use the Show
+// functions to create one; they return immediately and report the outcome
+// to their handler when the box is closed.
+type MessageBox struct {
+	*Dialog
+	icon    *ImageText
+	label   *Label
+	edit    *Edit
+	bar     *ProgressBar
+	buttons []*Button
+}
+
+// ShowAlert shows message with an OK button. onDone may be nil.
+func ShowAlert(title, message string, onDone AlertHandler) *MessageBox {
+	box := newMessageBox(MESSAGE_BOX_ICON_INFO, title, message, false, false)
+	ok := box.addButton(MESSAGE_BOX_OK_TEXT, true)
+	box.SetDefaultButton(ok.Widget).SetCancelButton(ok.Widget)
+	box.layout()
+
+	box.SetCloseHandler(func(retInfo interface{}) {
+		if onDone != nil {
+			onDone()
+		}
+	})
+
+	return box
+}
+
+// ShowConfirm asks the user to confirm message. onDone receives true for OK
+// and false for Cancel, Escape or the close button.
+func ShowConfirm(title, message string, onDone ConfirmHandler) *MessageBox {
+	box := newMessageBox(MESSAGE_BOX_ICON_QUESTION, title, message, false, false)
+	ok := box.addButton(MESSAGE_BOX_OK_TEXT, true)
+	cancel := box.addButton(MESSAGE_BOX_CANCEL_TEXT, false)
+	box.SetDefaultButton(ok.Widget).SetCancelButton(cancel.Widget)
+	box.layout()
+
+	box.SetCloseHandler(func(retInfo interface{}) {
+		if onDone != nil {
+			onDone(retInfo == DIALOG_RESULT_OK)
+		}
+	})
+
+	return box
+}
+
+// ShowPrompt asks for a line of text, starting with value selected. onDone
+// receives the text and whether OK was chosen.
+func ShowPrompt(title, message, value string, onDone PromptHandler) *MessageBox {
+	box := newMessageBox(MESSAGE_BOX_ICON_QUESTION, title, message, true, false)
+	ok := box.addButton(MESSAGE_BOX_OK_TEXT, true)
+	cancel := box.addButton(MESSAGE_BOX_CANCEL_TEXT, false)
+	box.SetDefaultButton(ok.Widget).SetCancelButton(cancel.Widget)
+	box.layout()
+
+	box.edit.SetText(value, false).SelectAll()
+	box.SetFocus(box.edit.Widget)
+	box.SetCloseHandler(func(retInfo interface{}) {
+		if onDone != nil {
+			onDone(box.edit.GetText(), retInfo == DIALOG_RESULT_OK)
+		}
+	})
+
+	return box
+}
+
+// ShowProgress shows message above a progress bar. Update it with
+// SetProgress and close it with Close when the work is done. If onCancel is
+// not nil a Cancel button is shown, and onCancel is called when the user
+// dismisses the box before it is closed with DIALOG_RESULT_OK.
+func ShowProgress(title, message string, onCancel AlertHandler) *MessageBox {
+	box := newMessageBox(MESSAGE_BOX_ICON_INFO, title, message, false, true)
+	box.ShowCloseButton(onCancel != nil)
+	if onCancel != nil {
+		cancel := box.addButton(MESSAGE_BOX_CANCEL_TEXT, false)
+		box.SetCancelButton(cancel.Widget)
+	}
+	box.layout()
+
+	box.SetCloseHandler(func(retInfo interface{}) {
+		if onCancel != nil && retInfo != DIALOG_RESULT_OK {
+			onCancel()
+		}
+	})
+
+	return box
+}
+
+func newMessageBox(icon, title, message string, hasEdit, hasProgress bool) *MessageBox {
+	box := &MessageBox{
+		Dialog: NewDialog(GetWindowManagerInstance(), 0, 0, MESSAGE_BOX_WIDTH, 200, title),
+	}
+	box.t = TYPE_MESSAGE_BOX
+	box.I = box
+	client := box.GetClient()
+
+	if img := theme.GetIconImage(icon); img != nil {
+		box.icon = NewImageText(client, MESSAGE_BOX_PADDING, MESSAGE_BOX_PADDING, MESSAGE_BOX_ICON_SIZE, MESSAGE_BOX_ICON_SIZE)
+		box.icon.SetImage(img)
+		box.icon.SetFgImageDisplay(image.DISPLAY_AUTO_SIZE_DOWN)
+	}
+
+	box.label = NewLabel(client, 0, MESSAGE_BOX_PADDING, 0, 0)
+	box.label.SetSingleLineMode(false).SetTextAlignH("left").SetTextAlignV("top")
+	box.label.SetText(message, false)
+
+	if hasEdit {
+		box.edit = NewEdit(client, MESSAGE_BOX_PADDING, 0, MESSAGE_BOX_WIDTH-2*MESSAGE_BOX_PADDING, MESSAGE_BOX_EDIT_HEIGHT)
+	}
+
+	if hasProgress {
+		box.bar = NewProgressBar(client, MESSAGE_BOX_PADDING, 0, MESSAGE_BOX_WIDTH-2*MESSAGE_BOX_PADDING, MESSAGE_BOX_BAR_HEIGHT)
+		box.bar.SetMax(1)
+	}
+
+	return box
+}
+
+func (box *MessageBox) addButton(text string, ok bool) *Button {
+	var button *Button
+	if ok {
+		button = NewOkButton(box.GetClient(), 0, 0, MESSAGE_BOX_BUTTON_WIDTH, MESSAGE_BOX_BUTTON_HEIGHT)
+	} else {
+		button = NewCancelButton(box.GetClient(), 0, 0, MESSAGE_BOX_BUTTON_WIDTH, MESSAGE_BOX_BUTTON_HEIGHT)
+	}
+	button.SetText(text, false)
+	button.SetClickedHandler(func(w *Widget, point *structs.Point) {
+		if ok {
+			w.CloseWindow(DIALOG_RESULT_OK)
+		} else {
+			w.CloseWindow(DIALOG_RESULT_CANCEL)
+		}
+	})
+	box.buttons = append(box.buttons, button)
+
+	return button
+}
+
+// layout sizes the message label to its wrapped text, stacks the entry,
+// progress bar and buttons below it, then fits, centers and grabs the box.
+func (box *MessageBox) layout() {
+	label := box.label
+	x := MESSAGE_BOX_PADDING
+	if box.icon != nil {
+		x += MESSAGE_BOX_ICON_SIZE + MESSAGE_BOX_PADDING
+	}
+	width := MESSAGE_BOX_WIDTH - x - MESSAGE_BOX_PADDING
+
+	context := box.getCanvas2D()
+	context.Save()
+	context.SetFont(label.getFont())
+	lines := layoutText(context, label.fontSize, label.GetText(), width-label.leftBorder-label.rightBorder, 0)
+	context.Restore()
+
+	lineHeight := float64(label.fontSize) * 1.5
+	height := int(math.Ceil(lineHeight*float64(len(lines)))) + label.topBorder + label.bottomBorder
+	label.Move(x, MESSAGE_BOX_PADDING)
+	label.Resize(width, height)
+
+	y := MESSAGE_BOX_PADDING + int(math.Max(float64(height), MESSAGE_BOX_ICON_SIZE)) + MESSAGE_BOX_PADDING
+	if box.edit != nil {
+		box.edit.Move(MESSAGE_BOX_PADDING, y)
+		y += MESSAGE_BOX_EDIT_HEIGHT + MESSAGE_BOX_PADDING
+	}
+
+	if box.bar != nil {
+		box.bar.Move(MESSAGE_BOX_PADDING, y)
+		y += MESSAGE_BOX_BAR_HEIGHT + MESSAGE_BOX_PADDING
+	}
+
+	if len(box.buttons) > 0 {
+		bx := MESSAGE_BOX_WIDTH - len(box.buttons)*(MESSAGE_BOX_BUTTON_WIDTH+MESSAGE_BOX_PADDING)
+		for _, button := range box.buttons {
+			button.Move(bx, y)
+			bx += MESSAGE_BOX_BUTTON_WIDTH + MESSAGE_BOX_PADDING
+		}
+		y += MESSAGE_BOX_BUTTON_HEIGHT + MESSAGE_BOX_PADDING
+	}
+
+	box.Resize(MESSAGE_BOX_WIDTH, DIALOG_TITLE_HEIGHT+y)
+	box.MoveToCenter()
+	box.SetModal(true)
+
+	return
+}
+
+func (box *MessageBox) GetMessage() string {
+	return box.label.GetText()
+}
+
+// SetMessage replaces the message. The box keeps its size, so the new text
+// should not need more lines than the original one.
+func (box *MessageBox) SetMessage(message string) *MessageBox {
+	box.label.SetText(message, false)
+	box.PostRedraw()
+
+	return box
+}
+
+// SetIcon replaces the icon with the theme icon called name, for example
+// MESSAGE_BOX_ICON_WARNING. Boxes created without an icon keep none.
+func (box *MessageBox) SetIcon(name string) *MessageBox {
+	if box.icon != nil {
+		box.icon.SetImage(theme.GetIconImage(name))
+	}
+
+	return box
+}
+
+// GetEdit returns the text entry of a prompt, or nil.
+func (box *MessageBox) GetEdit() *Edit {
+	return box.edit
+}
+
+// SetProgress sets the fraction, between 0 and 1, shown by a progress box.
+func (box *MessageBox) SetProgress(progress float64) *MessageBox {
+	if box.bar != nil {
+		box.bar.SetValue(progress)
+	}
+
+	return box
+}
+
+func (box *MessageBox) GetProgress() float64 {
+	if box.bar == nil {
+		return 0
+	}
+
+	return box.bar.GetValue()
+}
+
+// GetProgressBar returns the bar of a progress box, or nil. Make it
+// indeterminate when the length of the work is unknown.
+func (box *MessageBox) GetProgressBar() *ProgressBar {
+	return box.bar
+}
+
+// onKeyDown keeps Escape from dismissing a progress box that cannot be
+// cancelled.
+func (box *MessageBox) onKeyDown(code int) {
+	if code == keyevent.DOM_VK_ESCAPE && box.bar != nil && box.cancelButton == nil {
+		return
+	}
+	box.Dialog.onKeyDown(code)
+
+	return
+}
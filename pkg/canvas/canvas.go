@@ -0,0 +1,36 @@
+package canvas
+
+type TextMetrics struct {
+	Width float64
+}
+
+type Image interface {
+	GetWidth() int
+	GetHeight() int
+}
+
+type Canvas2D interface {
+	Save()
+	Restore()
+	Translate(x, y float64)
+	BeginPath()
+	ClosePath()
+	MoveTo(x, y float64)
+	LineTo(x, y float64)
+	Rect(x, y, w, h float64)
+	Arc(x, y, r, sAngle, eAngle float64, counterclockwise bool)
+	Fill()
+	Stroke()
+	Clip()
+	FillRect(x, y, w, h float64)
+	ClearRect(x, y, w, h float64)
+	FillText(text string, x, y, maxWidth float64)
+	MeasureText(text string) *TextMetrics
+	DrawImage(image Image, sx, sy, sw, sh, dx, dy, dw, dh float64)
+	SetFillStyle(fillStyle string)
+	SetStrokeStyle(strokeStyle string)
+	SetLineWidth(lineWidth int)
+	SetFont(font string)
+	SetTextAlign(textAlign string)
+	SetTextBaseline(textBaseline string)
+}
@@ -0,0 +1,127 @@
+package canvas
+
+import (
+	"honnef.co/go/js/dom"
+)
+
+type DOMImage struct {
+	*dom.HTMLImageElement
+}
+
+func NewDOMImage(element *dom.HTMLImageElement) *DOMImage {
+	return &DOMImage{HTMLImageElement: element}
+}
+
+func (image *DOMImage) GetWidth() int {
+	return image.Width
+}
+
+func (image *DOMImage) GetHeight() int {
+	return image.Height
+}
+
+type DOMCanvas struct {
+	ctx *dom.CanvasRenderingContext2D
+}
+
+func NewDOMCanvas(ctx *dom.CanvasRenderingContext2D) *DOMCanvas {
+	return &DOMCanvas{ctx: ctx}
+}
+
+func (c *DOMCanvas) GetContext() *dom.CanvasRenderingContext2D {
+	return c.ctx
+}
+
+func (c *DOMCanvas) Save() {
+	c.ctx.Save()
+}
+
+func (c *DOMCanvas) Restore() {
+	c.ctx.Restore()
+}
+
+func (c *DOMCanvas) Translate(x, y float64) {
+	c.ctx.Translate(x, y)
+}
+
+func (c *DOMCanvas) BeginPath() {
+	c.ctx.BeginPath()
+}
+
+func (c *DOMCanvas) ClosePath() {
+	c.ctx.ClosePath()
+}
+
+func (c *DOMCanvas) MoveTo(x, y float64) {
+	c.ctx.MoveTo(x, y)
+}
+
+func (c *DOMCanvas) LineTo(x, y float64) {
+	c.ctx.LineTo(x, y)
+}
+
+func (c *DOMCanvas) Rect(x, y, w, h float64) {
+	c.ctx.Rect(x, y, w, h)
+}
+
+func (c *DOMCanvas) Arc(x, y, r, sAngle, eAngle float64, counterclockwise bool) {
+	c.ctx.Arc(x, y, r, sAngle, eAngle, counterclockwise)
+}
+
+func (c *DOMCanvas) Fill() {
+	c.ctx.Fill()
+}
+
+func (c *DOMCanvas) Stroke() {
+	c.ctx.Stroke()
+}
+
+func (c *DOMCanvas) Clip() {
+	c.ctx.Clip()
+}
+
+func (c *DOMCanvas) FillRect(x, y, w, h float64) {
+	c.ctx.FillRect(x, y, w, h)
+}
+
+func (c *DOMCanvas) ClearRect(x, y, w, h float64) {
+	c.ctx.ClearRect(x, y, w, h)
+}
+
+func (c *DOMCanvas) FillText(text string, x, y, maxWidth float64) {
+	c.ctx.FillText(text, x, y, maxWidth)
+}
+
+func (c *DOMCanvas) MeasureText(text string) *TextMetrics {
+	return &TextMetrics{Width: c.ctx.MeasureText(text).Width}
+}
+
+func (c *DOMCanvas) DrawImage(image Image, sx, sy, sw, sh, dx, dy, dw, dh float64) {
+	if element, ok := image.(*DOMImage); ok {
+		c.ctx.Call("drawImage", element.HTMLImageElement, sx, sy, sw, sh, dx, dy, dw, dh)
+	}
+}
+
+func (c *DOMCanvas) SetFillStyle(fillStyle string) {
+	c.ctx.FillStyle = fillStyle
+}
+
+func (c *DOMCanvas) SetStrokeStyle(strokeStyle string) {
+	c.ctx.StrokeStyle = strokeStyle
+}
+
+func (c *DOMCanvas) SetLineWidth(lineWidth int) {
+	c.ctx.LineWidth = lineWidth
+}
+
+func (c *DOMCanvas) SetFont(font string) {
+	c.ctx.Font = font
+}
+
+func (c *DOMCanvas) SetTextAlign(textAlign string) {
+	c.ctx.TextAlign = textAlign
+}
+
+func (c *DOMCanvas) SetTextBaseline(textBaseline string) {
+	c.ctx.TextBaseline = textBaseline
+}
@@ -11,6 +11,7 @@ import (
 type EventConsumer interface {
 	OnKeyDown(code int)
 	OnKeyUp(code int)
+	OnKeyPress(char rune)
 	OnWheel(delta float64)
 	OnPointerDown(point *structs.Point)
 	OnPointerMove(point *structs.Point)
@@ -270,6 +271,28 @@ func (manager *EventsManager) onKeyDown(code int, event dom.Event) {
 	}
 }
 
+func (manager *EventsManager) onKeyPress(char rune, event dom.Event) {
+	if manager.eventsConsumer.PreprocessEvent("", event) {
+		manager.eventsConsumer.OnKeyPress(char)
+	}
+}
+
+// getKeyChar returns the character a keydown event types, if any. The
+// default action of keydown is cancelled, so keypress never fires and
+// character input has to be derived here.
+func (manager *EventsManager) getKeyChar(event *dom.KeyboardEvent) (rune, bool) {
+	if event.CtrlKey || event.MetaKey || event.AltKey {
+		return 0, false
+	}
+
+	chars := []rune(event.Key)
+	if len(chars) != 1 {
+		return 0, false
+	}
+
+	return chars[0], true
+}
+
 func (manager *EventsManager) onKeyDownGlobal(event *dom.KeyboardEvent) bool {
 	code := event.KeyCode
 
@@ -277,6 +300,9 @@ func (manager *EventsManager) onKeyDownGlobal(event *dom.KeyboardEvent) bool {
 		return true
 	} else {
 		manager.onKeyDown(code, event)
+		if char, ok := manager.getKeyChar(event); ok {
+			manager.onKeyPress(char, event)
+		}
 		return manager.cancelDefaultAction(event)
 	}
 }
@@ -0,0 +1,266 @@
+package event
+
+import (
+	"encoding/json"
+	"github.com/Luncher/gwk/pkg/structs"
+	"honnef.co/go/js/dom"
+	"io"
+	"time"
+)
+
+const (
+	EVENT_POINTER_DOWN = "pointerdown"
+	EVENT_POINTER_MOVE = "pointermove"
+	EVENT_POINTER_UP   = "pointerup"
+	EVENT_DOUBLE_CLICK = "dblclick"
+	EVENT_CONTEXT_MENU = "contextmenu"
+	EVENT_WHEEL        = "wheel"
+	EVENT_KEY_DOWN     = "keydown"
+	EVENT_KEY_UP       = "keyup"
+	EVENT_KEY_PRESS    = "keypress"
+)
+
+// Event is a serializable input event. Time is the offset from the start of
+// the recording it belongs to and is ignored when the event is dispatched.
+type Event struct {
+	Type  string        `json:"type"`
+	Time  time.Duration `json:"time"`
+	X     int           `json:"x,omitempty"`
+	Y     int           `json:"y,omitempty"`
+	Delta float64       `json:"delta,omitempty"`
+	Code  int           `json:"code,omitempty"`
+}
+
+func NewPointerEvent(t string, x, y int) *Event {
+	return &Event{Type: t, X: x, Y: y}
+}
+
+func NewWheelEvent(delta float64) *Event {
+	return &Event{Type: EVENT_WHEEL, Delta: delta}
+}
+
+func NewKeyEvent(t string, code int) *Event {
+	return &Event{Type: t, Code: code}
+}
+
+// NewKeyPressEvent creates a character input event; the character is stored
+// in Code.
+func NewKeyPressEvent(char rune) *Event {
+	return &Event{Type: EVENT_KEY_PRESS, Code: int(char)}
+}
+
+// Dispatch delivers e to consumer as if it came from the DOM listeners.
+func Dispatch(consumer EventConsumer, e *Event) {
+	point := structs.NewPoint(e.X, e.Y)
+
+	switch e.Type {
+	case EVENT_POINTER_DOWN:
+		consumer.OnPointerDown(point)
+	case EVENT_POINTER_MOVE:
+		consumer.OnPointerMove(point)
+	case EVENT_POINTER_UP:
+		consumer.OnPointerUp(point)
+	case EVENT_DOUBLE_CLICK:
+		consumer.OnDoubleClick(point)
+	case EVENT_CONTEXT_MENU:
+		consumer.OnContextMenu(point)
+	case EVENT_WHEEL:
+		consumer.OnWheel(e.Delta)
+	case EVENT_KEY_DOWN:
+		consumer.OnKeyDown(e.Code)
+	case EVENT_KEY_UP:
+		consumer.OnKeyUp(e.Code)
+	case EVENT_KEY_PRESS:
+		consumer.OnKeyPress(rune(e.Code))
+	}
+
+	return
+}
+
+type Recording struct {
+	Events []*Event `json:"events"`
+}
+
+func LoadRecording(reader io.Reader) (*Recording, error) {
+	var recording Recording
+	if err := json.NewDecoder(reader).Decode(&recording); err != nil {
+		return nil, err
+	}
+
+	return &recording, nil
+}
+
+func (recording *Recording) Save(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(recording)
+}
+
+// Recorder is an EventConsumer that timestamps and stores every event before
+// forwarding it to the wrapped consumer.
+type Recorder struct {
+	consumer  EventConsumer
+	startTime time.Time
+	recording *Recording
+}
+
+func NewRecorder(consumer EventConsumer) *Recorder {
+	return &Recorder{
+		consumer:  consumer,
+		startTime: time.Now(),
+		recording: &Recording{},
+	}
+}
+
+func (recorder *Recorder) GetRecording() *Recording {
+	return recorder.recording
+}
+
+func (recorder *Recorder) record(e *Event) {
+	e.Time = time.Since(recorder.startTime)
+	recorder.recording.Events = append(recorder.recording.Events, e)
+}
+
+func (recorder *Recorder) recordPointer(t string, point *structs.Point) {
+	recorder.record(NewPointerEvent(t, point.X, point.Y))
+}
+
+func (recorder *Recorder) OnKeyDown(code int) {
+	recorder.record(NewKeyEvent(EVENT_KEY_DOWN, code))
+	recorder.consumer.OnKeyDown(code)
+}
+
+func (recorder *Recorder) OnKeyUp(code int) {
+	recorder.record(NewKeyEvent(EVENT_KEY_UP, code))
+	recorder.consumer.OnKeyUp(code)
+}
+
+func (recorder *Recorder) OnKeyPress(char rune) {
+	recorder.record(NewKeyPressEvent(char))
+	recorder.consumer.OnKeyPress(char)
+}
+
+func (recorder *Recorder) OnWheel(delta float64) {
+	recorder.record(NewWheelEvent(delta))
+	recorder.consumer.OnWheel(delta)
+}
+
+func (recorder *Recorder) OnPointerDown(point *structs.Point) {
+	recorder.recordPointer(EVENT_POINTER_DOWN, point)
+	recorder.consumer.OnPointerDown(point)
+}
+
+func (recorder *Recorder) OnPointerMove(point *structs.Point) {
+	recorder.recordPointer(EVENT_POINTER_MOVE, point)
+	recorder.consumer.OnPointerMove(point)
+}
+
+func (recorder *Recorder) OnPointerUp(point *structs.Point) {
+	recorder.recordPointer(EVENT_POINTER_UP, point)
+	recorder.consumer.OnPointerUp(point)
+}
+
+func (recorder *Recorder) OnDoubleClick(point *structs.Point) {
+	recorder.recordPointer(EVENT_DOUBLE_CLICK, point)
+	recorder.consumer.OnDoubleClick(point)
+}
+
+func (recorder *Recorder) OnContextMenu(point *structs.Point) {
+	recorder.recordPointer(EVENT_CONTEXT_MENU, point)
+	recorder.consumer.OnContextMenu(point)
+}
+
+func (recorder *Recorder) PreprocessEvent(t string, e dom.Event) bool {
+	return recorder.consumer.PreprocessEvent(t, e)
+}
+
+func (recorder *Recorder) GetInputScale() (x, y float32) {
+	return recorder.consumer.GetInputScale()
+}
+
+// Replayer plays a Recording back into a consumer in recorded order. Step
+// and PlayAll are synchronous and ignore timestamps so playback is
+// deterministic; Play additionally waits out the recorded gaps.
+type Replayer struct {
+	consumer  EventConsumer
+	recording *Recording
+	index     int
+}
+
+func NewReplayer(consumer EventConsumer, recording *Recording) *Replayer {
+	return &Replayer{consumer: consumer, recording: recording}
+}
+
+func (replayer *Replayer) Done() bool {
+	return replayer.index >= len(replayer.recording.Events)
+}
+
+func (replayer *Replayer) Step() bool {
+	if replayer.Done() {
+		return false
+	}
+
+	e := *replayer.recording.Events[replayer.index]
+	replayer.index++
+	Dispatch(replayer.consumer, &e)
+
+	return true
+}
+
+func (replayer *Replayer) PlayAll() {
+	for replayer.Step() {
+	}
+
+	return
+}
+
+func (replayer *Replayer) Play() {
+	var last time.Duration
+	for !replayer.Done() {
+		next := replayer.recording.Events[replayer.index].Time
+		if next > last {
+			time.Sleep(next - last)
+			last = next
+		}
+		replayer.Step()
+	}
+
+	return
+}
+
+func (replayer *Replayer) Rewind() {
+	replayer.index = 0
+
+	return
+}
+
+// StartRecording wraps the consumer registered by SetEventsConsumer with a
+// Recorder so a real browser session can be captured. It returns nil when no
+// consumer has been registered yet.
+func StartRecording() *Recorder {
+	if eventManager.eventsConsumer == nil {
+		return nil
+	}
+
+	if recorder, ok := eventManager.eventsConsumer.(*Recorder); ok {
+		return recorder
+	}
+
+	recorder := NewRecorder(eventManager.eventsConsumer)
+	eventManager.eventsConsumer = recorder
+
+	return recorder
+}
+
+// StopRecording restores the original consumer and returns what was
+// captured since StartRecording, or nil if no recording was active.
+func StopRecording() *Recording {
+	recorder, ok := eventManager.eventsConsumer.(*Recorder)
+	if !ok {
+		return nil
+	}
+	eventManager.eventsConsumer = recorder.consumer
+
+	return recorder.GetRecording()
+}
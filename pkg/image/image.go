@@ -2,6 +2,7 @@ package image
 
 import (
 	"fmt"
+	"github.com/Luncher/gwk/pkg/canvas"
 	texturePacker "github.com/Luncher/gwk/pkg/texture_packer"
 	"github.com/Luncher/gwk/pkg/utils"
 	"honnef.co/go/js/dom"
@@ -45,7 +46,7 @@ type ImageSizeInfo struct {
 type Image struct {
 	src   string
 	rect  *ImageSizeInfo
-	image *dom.HTMLImageElement
+	image canvas.Image
 }
 
 var imagesCache = make(map[string]*Image)
@@ -61,7 +62,7 @@ func NewImage(url string) *Image {
 	}
 }
 
-func (image *Image) GetImage() *dom.HTMLImageElement {
+func (image *Image) GetImage() canvas.Image {
 	return image.image
 }
 
@@ -85,9 +86,9 @@ func (image *Image) SetImageSrc(url string) {
 }
 
 func (image *Image) setupNormalImage(url string) {
-	LoadImage(url, func(imageElement *dom.HTMLImageElement) {
-		image.image = imageElement
-		image.rect = GetImageRectDefault(imageElement)
+	LoadImage(url, func(img canvas.Image) {
+		image.image = img
+		image.rect = GetImageRectDefault(img)
 	})
 
 	return
@@ -117,7 +118,7 @@ func (image *Image) setupTexturePackerImage(url string) {
 			Rw:      imageJSON.SourceSize.W,
 			Rh:      imageJSON.SourceSize.H,
 		}
-		LoadImage(imagesUrl, func(img *dom.HTMLImageElement) {
+		LoadImage(imagesUrl, func(img canvas.Image) {
 			image.image = img
 		})
 	})
@@ -125,7 +126,7 @@ func (image *Image) setupTexturePackerImage(url string) {
 	return
 }
 
-func (image *Image) Draw(context *dom.CanvasRenderingContext2D, display Display, x, y, dw, dh int) {
+func (image *Image) Draw(context canvas.Canvas2D, display Display, x, y, dw, dh int) {
 	imageVal := image.GetImage()
 	rect := image.GetImageRect()
 	DrawImage(context, imageVal, display, x, y, dw, dh, rect)
@@ -133,28 +134,40 @@ func (image *Image) Draw(context *dom.CanvasRenderingContext2D, display Display,
 	return
 }
 
-func GetImageRectDefault(image *dom.HTMLImageElement) *ImageSizeInfo {
-	return &ImageSizeInfo{W: image.Width, H: image.Height}
+func GetImageRectDefault(image canvas.Image) *ImageSizeInfo {
+	return &ImageSizeInfo{W: image.GetWidth(), H: image.GetHeight()}
 }
 
-func LoadImage(url string, onDone func(*dom.HTMLImageElement)) {
+type ImageLoader func(url string, onDone func(canvas.Image))
+
+var imageLoader ImageLoader = loadDOMImage
+
+func SetImageLoader(loader ImageLoader) {
+	imageLoader = loader
+}
+
+func LoadImage(url string, onDone func(canvas.Image)) {
+	imageLoader(url, onDone)
+}
+
+func loadDOMImage(url string, onDone func(canvas.Image)) {
 	imageElement := dom.GetWindow().Document().CreateElement("img").(*dom.HTMLImageElement)
 	imageElement.AddEventListener("error", false, func(event dom.Event) {
 		fmt.Printf("loadImage error%v\n", event)
-		onDone(imageElement)
+		onDone(canvas.NewDOMImage(imageElement))
 	})
 
 	imageElement.AddEventListener("load", false, func(event dom.Event) {
 		fmt.Printf("loadImage %s done\n", url)
-		onDone(imageElement)
+		onDone(canvas.NewDOMImage(imageElement))
 	})
 	imageElement.Src = url
 
 	return
 }
 
-func DrawImage(context *dom.CanvasRenderingContext2D, image *dom.HTMLImageElement, display Display, x, y, dw, dh int, srcRect *ImageSizeInfo) {
-	if image == nil || image.Width == 0 {
+func DrawImage(context canvas.Canvas2D, image canvas.Image, display Display, x, y, dw, dh int, srcRect *ImageSizeInfo) {
+	if image == nil || image.GetWidth() == 0 {
 		return
 	}
 	sr := srcRect
@@ -187,7 +200,7 @@ func DrawImage(context *dom.CanvasRenderingContext2D, image *dom.HTMLImageElemen
 		dy += int(float64(oy) * scale)
 		dw := (float64(sw) * scale)
 		dh := (float64(sh) * scale)
-		context.Call("drawImage", image, float64(sx), float64(sy), float64(sw), float64(sh), float64(dx), float64(dy), float64(dw), float64(dh))
+		context.DrawImage(image, float64(sx), float64(sy), float64(sw), float64(sh), float64(dx), float64(dy), float64(dw), float64(dh))
 	case DISPLAY_9PATCH:
 		dx := x + ox
 		dy := y + oy
@@ -0,0 +1,402 @@
+package raster
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+type point struct {
+	x, y float64
+}
+
+type subPath struct {
+	points []point
+	closed bool
+}
+
+type state struct {
+	tx, ty       float64
+	fillStyle    color.Color
+	strokeStyle  color.Color
+	lineWidth    int
+	font         string
+	textAlign    string
+	textBaseline string
+	clip         *image.Alpha
+}
+
+// Canvas is a software implementation of canvas.Canvas2D that rasterizes
+// into an image.RGBA, so widgets can be painted without a browser.
+type Canvas struct {
+	dst   *image.RGBA
+	state state
+	stack []state
+	path  []*subPath
+}
+
+func NewCanvas(w, h int) *Canvas {
+	return &Canvas{
+		dst: image.NewRGBA(image.Rect(0, 0, w, h)),
+		state: state{
+			fillStyle:    color.Black,
+			strokeStyle:  color.Black,
+			lineWidth:    1,
+			font:         "10px sans-serif",
+			textAlign:    "start",
+			textBaseline: "alphabetic",
+		},
+	}
+}
+
+func (c *Canvas) GetImage() *image.RGBA {
+	return c.dst
+}
+
+func (c *Canvas) Save() {
+	c.stack = append(c.stack, c.state)
+}
+
+func (c *Canvas) Restore() {
+	if n := len(c.stack); n > 0 {
+		c.state = c.stack[n-1]
+		c.stack = c.stack[:n-1]
+	}
+}
+
+func (c *Canvas) Translate(x, y float64) {
+	c.state.tx += x
+	c.state.ty += y
+}
+
+func (c *Canvas) BeginPath() {
+	c.path = c.path[:0]
+}
+
+func (c *Canvas) ClosePath() {
+	if n := len(c.path); n > 0 && len(c.path[n-1].points) > 0 {
+		c.path[n-1].closed = true
+		first := c.path[n-1].points[0]
+		c.path = append(c.path, &subPath{points: []point{first}})
+	}
+}
+
+func (c *Canvas) MoveTo(x, y float64) {
+	c.path = append(c.path, &subPath{points: []point{c.transform(x, y)}})
+}
+
+func (c *Canvas) LineTo(x, y float64) {
+	if len(c.path) == 0 {
+		c.MoveTo(x, y)
+		return
+	}
+
+	sp := c.path[len(c.path)-1]
+	sp.points = append(sp.points, c.transform(x, y))
+}
+
+func (c *Canvas) Rect(x, y, w, h float64) {
+	c.MoveTo(x, y)
+	c.LineTo(x+w, y)
+	c.LineTo(x+w, y+h)
+	c.LineTo(x, y+h)
+	c.ClosePath()
+}
+
+func (c *Canvas) Arc(x, y, r, sAngle, eAngle float64, counterclockwise bool) {
+	sweep := eAngle - sAngle
+	if !counterclockwise {
+		if sweep >= 2*math.Pi {
+			sweep = 2 * math.Pi
+		} else {
+			for sweep < 0 {
+				sweep += 2 * math.Pi
+			}
+		}
+	} else {
+		if sweep <= -2*math.Pi {
+			sweep = -2 * math.Pi
+		} else {
+			for sweep > 0 {
+				sweep -= 2 * math.Pi
+			}
+		}
+	}
+
+	n := int(math.Ceil(math.Abs(sweep) * math.Max(r, 1) / 2))
+	if n < 8 {
+		n = 8
+	}
+
+	for i := 0; i <= n; i++ {
+		angle := sAngle + sweep*float64(i)/float64(n)
+		px := x + r*math.Cos(angle)
+		py := y + r*math.Sin(angle)
+		if i == 0 && len(c.path) == 0 {
+			c.MoveTo(px, py)
+		} else {
+			c.LineTo(px, py)
+		}
+	}
+}
+
+func (c *Canvas) Fill() {
+	z := c.newRasterizer()
+	for _, sp := range c.path {
+		if len(sp.points) < 3 {
+			continue
+		}
+		z.MoveTo(float32(sp.points[0].x), float32(sp.points[0].y))
+		for _, p := range sp.points[1:] {
+			z.LineTo(float32(p.x), float32(p.y))
+		}
+		z.ClosePath()
+	}
+
+	c.paint(c.rasterize(z), c.state.fillStyle)
+}
+
+func (c *Canvas) Stroke() {
+	hw := float64(c.state.lineWidth) / 2
+	z := c.newRasterizer()
+
+	for _, sp := range c.path {
+		points := sp.points
+		if sp.closed && len(points) > 1 {
+			points = append(points[:len(points):len(points)], points[0])
+		}
+		for i := 1; i < len(points); i++ {
+			addSegment(z, points[i-1], points[i], hw)
+		}
+		for i := 1; i < len(points)-1; i++ {
+			addJoin(z, points[i], hw)
+		}
+	}
+
+	c.paint(c.rasterize(z), c.state.strokeStyle)
+}
+
+func (c *Canvas) Clip() {
+	z := c.newRasterizer()
+	for _, sp := range c.path {
+		if len(sp.points) < 3 {
+			continue
+		}
+		z.MoveTo(float32(sp.points[0].x), float32(sp.points[0].y))
+		for _, p := range sp.points[1:] {
+			z.LineTo(float32(p.x), float32(p.y))
+		}
+		z.ClosePath()
+	}
+
+	c.state.clip = c.intersectClip(c.rasterize(z))
+}
+
+func (c *Canvas) FillRect(x, y, w, h float64) {
+	mask := image.NewAlpha(c.dst.Bounds())
+	draw.Draw(mask, c.deviceRect(x, y, w, h), image.Opaque, image.Point{}, draw.Src)
+	c.paint(mask, c.state.fillStyle)
+}
+
+func (c *Canvas) ClearRect(x, y, w, h float64) {
+	r := c.deviceRect(x, y, w, h)
+	if c.state.clip != nil {
+		draw.DrawMask(c.dst, r, image.Transparent, image.Point{}, c.state.clip, r.Min, draw.Src)
+	} else {
+		draw.Draw(c.dst, r, image.Transparent, image.Point{}, draw.Src)
+	}
+}
+
+func (c *Canvas) FillText(text string, x, y, maxWidth float64) {
+	if len(text) == 0 {
+		return
+	}
+
+	face := getFace(c.state.font)
+	p := c.transform(x, y)
+	width := fixedToFloat(font.MeasureString(face, text))
+	metrics := face.Metrics()
+	ascent := fixedToFloat(metrics.Ascent)
+	descent := fixedToFloat(metrics.Descent)
+
+	switch c.state.textAlign {
+	case "center":
+		p.x -= width / 2
+	case "right", "end":
+		p.x -= width
+	}
+
+	switch c.state.textBaseline {
+	case "top", "hanging":
+		p.y += ascent
+	case "middle":
+		p.y += (ascent - descent) / 2
+	case "bottom", "ideographic":
+		p.y -= descent
+	}
+
+	mask := image.NewAlpha(c.dst.Bounds())
+	drawer := &font.Drawer{
+		Dst:  mask,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.Point26_6{X: floatToFixed(p.x), Y: floatToFixed(p.y)},
+	}
+	drawer.DrawString(text)
+
+	c.paint(mask, c.state.fillStyle)
+}
+
+func (c *Canvas) MeasureText(text string) *canvas.TextMetrics {
+	face := getFace(c.state.font)
+
+	return &canvas.TextMetrics{Width: fixedToFloat(font.MeasureString(face, text))}
+}
+
+func (c *Canvas) DrawImage(img canvas.Image, sx, sy, sw, sh, dx, dy, dw, dh float64) {
+	src, ok := img.(*Image)
+	if !ok || src.img == nil {
+		return
+	}
+
+	origin := src.img.Bounds().Min
+	sr := image.Rect(int(sx), int(sy), int(sx+sw), int(sy+sh)).Add(origin).Intersect(src.img.Bounds())
+	dr := c.deviceRect(dx, dy, dw, dh)
+	if sr.Empty() || dr.Empty() {
+		return
+	}
+
+	opts := &xdraw.Options{}
+	if c.state.clip != nil {
+		opts.DstMask = c.state.clip
+	}
+
+	if sr.Dx() == dr.Dx() && sr.Dy() == dr.Dy() {
+		xdraw.Copy(c.dst, dr.Min, src.img, sr, xdraw.Over, opts)
+	} else {
+		xdraw.ApproxBiLinear.Scale(c.dst, dr, src.img, sr, xdraw.Over, opts)
+	}
+}
+
+func (c *Canvas) SetFillStyle(fillStyle string) {
+	if col, ok := ParseColor(fillStyle); ok {
+		c.state.fillStyle = col
+	}
+}
+
+func (c *Canvas) SetStrokeStyle(strokeStyle string) {
+	if col, ok := ParseColor(strokeStyle); ok {
+		c.state.strokeStyle = col
+	}
+}
+
+func (c *Canvas) SetLineWidth(lineWidth int) {
+	if lineWidth > 0 {
+		c.state.lineWidth = lineWidth
+	}
+}
+
+func (c *Canvas) SetFont(font string) {
+	c.state.font = font
+}
+
+func (c *Canvas) SetTextAlign(textAlign string) {
+	c.state.textAlign = textAlign
+}
+
+func (c *Canvas) SetTextBaseline(textBaseline string) {
+	c.state.textBaseline = textBaseline
+}
+
+func (c *Canvas) transform(x, y float64) point {
+	return point{x + c.state.tx, y + c.state.ty}
+}
+
+func (c *Canvas) deviceRect(x, y, w, h float64) image.Rectangle {
+	p := c.transform(x, y)
+	x0 := int(math.Round(p.x))
+	y0 := int(math.Round(p.y))
+	x1 := int(math.Round(p.x + w))
+	y1 := int(math.Round(p.y + h))
+
+	return image.Rect(x0, y0, x1, y1).Intersect(c.dst.Bounds())
+}
+
+func (c *Canvas) newRasterizer() *vector.Rasterizer {
+	b := c.dst.Bounds()
+
+	return vector.NewRasterizer(b.Dx(), b.Dy())
+}
+
+func (c *Canvas) rasterize(z *vector.Rasterizer) *image.Alpha {
+	mask := image.NewAlpha(c.dst.Bounds())
+	z.DrawOp = draw.Src
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+	return mask
+}
+
+func (c *Canvas) intersectClip(mask *image.Alpha) *image.Alpha {
+	if c.state.clip == nil {
+		return mask
+	}
+
+	for i := range mask.Pix {
+		mask.Pix[i] = uint8(uint16(mask.Pix[i]) * uint16(c.state.clip.Pix[i]) / 0xff)
+	}
+
+	return mask
+}
+
+func (c *Canvas) paint(mask *image.Alpha, col color.Color) {
+	mask = c.intersectClip(mask)
+	draw.DrawMask(c.dst, c.dst.Bounds(), image.NewUniform(col), image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+func addSegment(z *vector.Rasterizer, p0, p1 point, hw float64) {
+	dx := p1.x - p0.x
+	dy := p1.y - p0.y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return
+	}
+
+	nx := -dy / l * hw
+	ny := dx / l * hw
+	z.MoveTo(float32(p0.x+nx), float32(p0.y+ny))
+	z.LineTo(float32(p1.x+nx), float32(p1.y+ny))
+	z.LineTo(float32(p1.x-nx), float32(p1.y-ny))
+	z.LineTo(float32(p0.x-nx), float32(p0.y-ny))
+	z.ClosePath()
+}
+
+func addJoin(z *vector.Rasterizer, p point, hw float64) {
+	// Winds the same way as addSegment so overlapping coverage accumulates
+	// instead of cancelling out.
+	const n = 8
+	for i := 0; i < n; i++ {
+		angle := -2 * math.Pi * float64(i) / n
+		x := float32(p.x + hw*math.Cos(angle))
+		y := float32(p.y + hw*math.Sin(angle))
+		if i == 0 {
+			z.MoveTo(x, y)
+		} else {
+			z.LineTo(x, y)
+		}
+	}
+	z.ClosePath()
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+func floatToFixed(v float64) fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(v * 64))
+}
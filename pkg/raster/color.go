@@ -0,0 +1,116 @@
+package raster
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+var namedColors = map[string]color.RGBA{
+	"transparent": {0, 0, 0, 0},
+	"black":       {0x00, 0x00, 0x00, 0xff},
+	"white":       {0xff, 0xff, 0xff, 0xff},
+	"gray":        {0x80, 0x80, 0x80, 0xff},
+	"grey":        {0x80, 0x80, 0x80, 0xff},
+	"darkgray":    {0xa9, 0xa9, 0xa9, 0xff},
+	"lightgray":   {0xd3, 0xd3, 0xd3, 0xff},
+	"silver":      {0xc0, 0xc0, 0xc0, 0xff},
+	"red":         {0xff, 0x00, 0x00, 0xff},
+	"green":       {0x00, 0x80, 0x00, 0xff},
+	"lime":        {0x00, 0xff, 0x00, 0xff},
+	"blue":        {0x00, 0x00, 0xff, 0xff},
+	"navy":        {0x00, 0x00, 0x80, 0xff},
+	"yellow":      {0xff, 0xff, 0x00, 0xff},
+	"orange":      {0xff, 0xa5, 0x00, 0xff},
+	"purple":      {0x80, 0x00, 0x80, 0xff},
+	"cyan":        {0x00, 0xff, 0xff, 0xff},
+	"magenta":     {0xff, 0x00, 0xff, 0xff},
+	"maroon":      {0x80, 0x00, 0x00, 0xff},
+	"olive":       {0x80, 0x80, 0x00, 0xff},
+	"teal":        {0x00, 0x80, 0x80, 0xff},
+}
+
+// ParseColor understands the CSS color forms used by gwk themes: names,
+// #rgb, #rrggbb, #rrggbbaa, rgb() and rgba().
+func ParseColor(str string) (color.Color, bool) {
+	s := strings.ToLower(strings.TrimSpace(str))
+	if len(s) == 0 {
+		return nil, false
+	}
+
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+
+	if strings.HasPrefix(s, "#") {
+		return parseHexColor(s[1:])
+	}
+
+	if strings.HasPrefix(s, "rgb") {
+		start := strings.Index(s, "(")
+		end := strings.LastIndex(s, ")")
+		if start < 0 || end < start {
+			return nil, false
+		}
+
+		parts := strings.Split(s[start+1:end], ",")
+		if len(parts) < 3 {
+			return nil, false
+		}
+
+		var v [3]uint8
+		for i := 0; i < 3; i++ {
+			n, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+			if err != nil {
+				return nil, false
+			}
+			v[i] = clampUint8(n)
+		}
+
+		a := uint8(0xff)
+		if len(parts) > 3 {
+			f, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+			if err != nil {
+				return nil, false
+			}
+			a = clampUint8(int(f * 255))
+		}
+
+		return color.NRGBA{v[0], v[1], v[2], a}, true
+	}
+
+	return nil, false
+}
+
+func parseHexColor(s string) (color.Color, bool) {
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+
+	if len(s) == 6 {
+		s += "ff"
+	}
+
+	if len(s) != 8 {
+		return nil, false
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+
+	return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+}
+
+func clampUint8(n int) uint8 {
+	if n < 0 {
+		return 0
+	}
+
+	if n > 0xff {
+		return 0xff
+	}
+
+	return uint8(n)
+}
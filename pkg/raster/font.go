@@ -0,0 +1,89 @@
+package raster
+
+import (
+	"fmt"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var fontSizeRegexp = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(px|pt)`)
+
+var fonts = make(map[string]*opentype.Font)
+var faces = make(map[string]font.Face)
+
+// parseFont extracts size and style from a CSS font string such as
+// "bold 12px sans-serif" or "13pt bold sans-serif". The family is ignored:
+// the bundled Go fonts are always used so frames render the same everywhere.
+func parseFont(str string) (size float64, bold, italic bool) {
+	s := strings.ToLower(str)
+	size = 10
+
+	if m := fontSizeRegexp.FindStringSubmatch(s); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil && v > 0 {
+			size = v
+			if m[2] == "pt" {
+				size = v * 4 / 3
+			}
+		}
+	}
+
+	bold = strings.Contains(s, "bold")
+	italic = strings.Contains(s, "italic") || strings.Contains(s, "oblique")
+
+	return size, bold, italic
+}
+
+func getFont(bold, italic bool) *opentype.Font {
+	var name string
+	var ttf []byte
+
+	switch {
+	case bold && italic:
+		name, ttf = "bolditalic", gobolditalic.TTF
+	case bold:
+		name, ttf = "bold", gobold.TTF
+	case italic:
+		name, ttf = "italic", goitalic.TTF
+	default:
+		name, ttf = "regular", goregular.TTF
+	}
+
+	if f, ok := fonts[name]; ok {
+		return f
+	}
+
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(err)
+	}
+	fonts[name] = f
+
+	return f
+}
+
+func getFace(str string) font.Face {
+	size, bold, italic := parseFont(str)
+	key := fmt.Sprintf("%v-%v-%.2f", bold, italic, size)
+	if face, ok := faces[key]; ok {
+		return face
+	}
+
+	face, err := opentype.NewFace(getFont(bold, italic), &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		panic(err)
+	}
+	faces[key] = face
+
+	return face
+}
@@ -0,0 +1,57 @@
+package raster
+
+import (
+	"fmt"
+	"github.com/Luncher/gwk/pkg/canvas"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Image struct {
+	img image.Image
+}
+
+func NewImage(img image.Image) *Image {
+	return &Image{img: img}
+}
+
+func (image *Image) GetWidth() int {
+	return image.img.Bounds().Dx()
+}
+
+func (image *Image) GetHeight() int {
+	return image.img.Bounds().Dy()
+}
+
+func LoadImageFile(path string) (*Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewImage(img), nil
+}
+
+// NewFileImageLoader returns a loader for image.SetImageLoader that resolves
+// image URLs against the root directory instead of fetching them over HTTP.
+func NewFileImageLoader(root string) func(string, func(canvas.Image)) {
+	return func(url string, onDone func(canvas.Image)) {
+		path := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(url, "/")))
+		img, err := LoadImageFile(path)
+		if err != nil {
+			fmt.Printf("loadImage error %v\n", err)
+			return
+		}
+		onDone(img)
+	}
+}
@@ -54,6 +54,7 @@ type ThemeWidget struct {
 	StateDisableSelected *ThemeStyle `json:"state-disable-selected"`
 	StateSelected        *ThemeStyle `json:"state-selected"`
 	StateNormalCurrent   *ThemeStyle `json:"state-normal-current"`
+	StateVisited         *ThemeStyle `json:"state-visited"`
 }
 
 type ThemeFont struct {
@@ -151,6 +152,7 @@ func NewThemeWidget() *ThemeWidget {
 		NewThemeStyle("13pt bold sans-serif ", "", "Gray", ""),
 		NewThemeStyle("13pt bold sans-serif ", "", "#000000", "#000000"),
 		NewThemeStyle("13pt bold sans-serif ", "", "#000000", "#000000"),
+		NewThemeStyle("13pt bold sans-serif ", "", "#000000", "#000000"),
 	}
 
 	return widgetTheme
@@ -275,6 +277,9 @@ func loadTheme(themeURL string, reader io.ReadCloser) error {
 		if widgetTheme.StateNormalCurrent != nil {
 			applyDefaultFont(widgetTheme.StateNormalCurrent, font)
 		}
+		if widgetTheme.StateVisited != nil {
+			applyDefaultFont(widgetTheme.StateVisited, font)
+		}
 	}
 
 	themesLoaded = true
@@ -283,6 +288,10 @@ func loadTheme(themeURL string, reader io.ReadCloser) error {
 	return nil
 }
 
+func LoadTheme(url string, reader io.ReadCloser) error {
+	return loadTheme(url, reader)
+}
+
 func Get(name string, noDefault bool) *ThemeWidget {
 	theme := themes[name]
 	if theme == nil {
@@ -297,6 +306,14 @@ func Get(name string, noDefault bool) *ThemeWidget {
 	return theme
 }
 
+// Has reports whether the loaded theme styles the widget type name, rather
+// than leaving it to the default theme.
+func Has(name string) bool {
+	_, ok := themes[name]
+
+	return ok
+}
+
 func LoadThemeURL(url string) error {
 	go func() {
 		res, err := http.Get(url)
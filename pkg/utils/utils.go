@@ -1,7 +1,7 @@
 package utils
 
 import (
-	"honnef.co/go/js/dom"
+	"github.com/Luncher/gwk/pkg/canvas"
 	"math"
 )
 
@@ -13,7 +13,7 @@ const (
 	RoundRectALL = RoundRectTL | RoundRectTR | RoundRectBL | RoundRectBR
 )
 
-func DrawRoundRect(context *dom.CanvasRenderingContext2D, w, h, r float64, which int) {
+func DrawRoundRect(context canvas.Canvas2D, w, h, r float64, which int) {
 	hw := int(w) >> 1
 	hh := int(h) >> 1
 
@@ -70,22 +70,22 @@ func DrawRoundRect(context *dom.CanvasRenderingContext2D, w, h, r float64, which
 	return
 }
 
-func DrawNightPatchEx(context *dom.CanvasRenderingContext2D, image *dom.HTMLImageElement, s_x, s_y, s_w, s_h, x, y, w, h float64) {
+func DrawNightPatchEx(context canvas.Canvas2D, image canvas.Image, s_x, s_y, s_w, s_h, x, y, w, h float64) {
 	if image == nil {
 		context.FillRect(x, y, w, h)
 		return
 	}
 
-	if s_w == 0 || int(s_w) > image.Width {
-		s_w = float64(image.Width)
+	if s_w == 0 || int(s_w) > image.GetWidth() {
+		s_w = float64(image.GetWidth())
 	}
 
-	if s_h == 0 || int(s_h) > image.Height {
-		s_h = float64(image.Height)
+	if s_h == 0 || int(s_h) > image.GetHeight() {
+		s_h = float64(image.GetHeight())
 	}
 
 	if w < s_w && h < s_h && (s_w < 3 || s_h < 3) {
-		context.Call("drawImage", image, s_x, s_y, s_w, s_h, x, y, w, h)
+		context.DrawImage(image, s_x, s_y, s_w, s_h, x, y, w, h)
 		return
 	}
 
@@ -117,25 +117,25 @@ func DrawNightPatchEx(context *dom.CanvasRenderingContext2D, image *dom.HTMLImag
 	}
 
 	//draw four corner
-	context.Call("drawImage", image, s_x, s_y, tw, th, x, y, tw, th)
-	context.Call("drawImage", image, s_x+s_w-tw, s_y, tw, th, x+w-tw, y, tw, th)
-	context.Call("drawImage", image, s_x, s_y+s_h-th, tw, th, x, y+h-th, tw, th)
-	context.Call("drawImage", image, s_x+s_w-tw, s_y+s_h-th, tw, th, x+w-tw, y+h-th, tw, th)
+	context.DrawImage(image, s_x, s_y, tw, th, x, y, tw, th)
+	context.DrawImage(image, s_x+s_w-tw, s_y, tw, th, x+w-tw, y, tw, th)
+	context.DrawImage(image, s_x, s_y+s_h-th, tw, th, x, y+h-th, tw, th)
+	context.DrawImage(image, s_x+s_w-tw, s_y+s_h-th, tw, th, x+w-tw, y+h-th, tw, th)
 
 	//top/bottom center
 	if dcw > 0 {
-		context.Call("drawImage", image, s_x+tw, s_y, cw, th, x+tw, y, dcw, th)
-		context.Call("drawImage", image, s_x+tw, s_y+s_h-th, cw, th, x+tw, y+h-th, dcw, th)
+		context.DrawImage(image, s_x+tw, s_y, cw, th, x+tw, y, dcw, th)
+		context.DrawImage(image, s_x+tw, s_y+s_h-th, cw, th, x+tw, y+h-th, dcw, th)
 	}
 
 	//left/right center
 	if dch > 0 {
-		context.Call("drawImage", image, s_x, s_y+th, tw, ch, x, y+th, tw, dch)
-		context.Call("drawImage", image, s_x+s_w-tw, s_y+th, tw, ch, x+w-tw, y+th, tw, dch)
+		context.DrawImage(image, s_x, s_y+th, tw, ch, x, y+th, tw, dch)
+		context.DrawImage(image, s_x+s_w-tw, s_y+th, tw, ch, x+w-tw, y+th, tw, dch)
 	}
 
 	if dcw > 0 && dch > 0 {
-		context.Call("drawImage", image, s_x+tw, s_y+th, cw, ch, x+tw, y+th, dcw, dch)
+		context.DrawImage(image, s_x+tw, s_y+th, cw, ch, x+tw, y+th, dcw, dch)
 	}
 
 	return
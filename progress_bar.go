@@ -0,0 +1,176 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	PROGRESS_BAR_PADDING       = 2
+	PROGRESS_BAR_CHUNK         = 0.3
+	PROGRESS_BAR_PERIOD        = 1500 * time.Millisecond
+	PROGRESS_BAR_FILL_COLOR    = "#3399FF"
+	PROGRESS_BAR_TRACK_COLOR   = "#E8E8E8"
+	PROGRESS_BAR_DISABLE_COLOR = "#C8C8C8"
+)
+
+// ProgressBar shows how much of a task is done, filling from the left, or
+// from the bottom when it is higher than wide. In indeterminate mode a chunk
+// sweeps along the bar for as long as it is shown. The text, centered over
+// the bar, is the percentage when SetShowPercentage is on.
+type ProgressBar struct {
+	*Label
+	value          float64
+	max            float64
+	showPercentage bool
+	indeterminate  bool
+	startTime      time.Time
+}
+
+func NewProgressBar(parent *Widget, x, y, w, h float32) *ProgressBar {
+	bar := &ProgressBar{
+		Label: NewLabel(parent, x, y, w, h),
+		max:   100,
+	}
+	bar.t = TYPE_PROGRESSBAR
+	bar.SetFontSize(12)
+	bar.I = bar
+
+	return bar
+}
+
+// SetMax sets the value of a completed task, clamping the value to it.
+func (bar *ProgressBar) SetMax(max float64) *ProgressBar {
+	bar.max = math.Max(0, max)
+
+	return bar.SetValue(bar.value)
+}
+
+func (bar *ProgressBar) GetMax() float64 {
+	return bar.max
+}
+
+func (bar *ProgressBar) SetValue(value float64) *ProgressBar {
+	bar.value = math.Max(0, math.Min(bar.max, value))
+	bar.updateText()
+	bar.PostRedraw()
+
+	return bar
+}
+
+func (bar *ProgressBar) GetValue() float64 {
+	return bar.value
+}
+
+// GetFraction returns the value as a fraction of max, between 0 and 1.
+func (bar *ProgressBar) GetFraction() float64 {
+	if bar.max <= 0 {
+		return 0
+	}
+
+	return bar.value / bar.max
+}
+
+// SetShowPercentage replaces the text of the bar with the percentage done.
+func (bar *ProgressBar) SetShowPercentage(showPercentage bool) *ProgressBar {
+	bar.showPercentage = showPercentage
+	if !showPercentage {
+		bar.SetText("", false)
+	}
+	bar.updateText()
+
+	return bar
+}
+
+func (bar *ProgressBar) IsShowPercentage() bool {
+	return bar.showPercentage
+}
+
+// SetIndeterminate animates the bar for tasks of unknown length.
+func (bar *ProgressBar) SetIndeterminate(indeterminate bool) *ProgressBar {
+	bar.indeterminate = indeterminate
+	bar.startTime = time.Now()
+	bar.updateText()
+	bar.PostRedraw()
+
+	return bar
+}
+
+func (bar *ProgressBar) IsIndeterminate() bool {
+	return bar.indeterminate
+}
+
+func (bar *ProgressBar) updateText() {
+	if !bar.showPercentage {
+		return
+	}
+
+	text := ""
+	if !bar.indeterminate {
+		text = strconv.Itoa(int(math.Floor(bar.GetFraction()*100))) + "%"
+	}
+	bar.SetText(text, false)
+
+	return
+}
+
+func (bar *ProgressBar) isVertical() bool {
+	return bar.rect.H > bar.rect.W
+}
+
+// getChunk returns where the filled part starts and ends along the bar, as
+// fractions of its length.
+func (bar *ProgressBar) getChunk() (start, end float64) {
+	if !bar.indeterminate {
+		return 0, bar.GetFraction()
+	}
+
+	phase := float64(time.Since(bar.startTime)%PROGRESS_BAR_PERIOD) / float64(PROGRESS_BAR_PERIOD)
+	start = phase*(1+PROGRESS_BAR_CHUNK) - PROGRESS_BAR_CHUNK
+
+	return math.Max(0, start), math.Min(1, start+PROGRESS_BAR_CHUNK)
+}
+
+func (bar *ProgressBar) paintBackground(context canvas.Canvas2D) {
+	style := bar.getStyle("")
+	w := float64(bar.rect.W)
+	h := float64(bar.rect.H)
+
+	context.SetFillStyle(PROGRESS_BAR_TRACK_COLOR)
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.Rect(0.5, 0.5, w-1, h-1)
+	context.Stroke()
+
+	if bar.enable {
+		context.SetFillStyle(colorOr(style.FillColor, PROGRESS_BAR_FILL_COLOR))
+	} else {
+		context.SetFillStyle(PROGRESS_BAR_DISABLE_COLOR)
+	}
+
+	start, end := bar.getChunk()
+	inner := w - 2*PROGRESS_BAR_PADDING
+	if bar.isVertical() {
+		inner = h - 2*PROGRESS_BAR_PADDING
+	}
+
+	if end > start {
+		a := math.Floor(start * inner)
+		b := math.Floor(end * inner)
+		if bar.isVertical() {
+			context.FillRect(PROGRESS_BAR_PADDING, h-PROGRESS_BAR_PADDING-b, w-2*PROGRESS_BAR_PADDING, b-a)
+		} else {
+			context.FillRect(PROGRESS_BAR_PADDING+a, PROGRESS_BAR_PADDING, b-a, h-2*PROGRESS_BAR_PADDING)
+		}
+	}
+
+	if bar.indeterminate && bar.enable {
+		GetWindowManagerInstance().keepAnimating()
+	}
+
+	return
+}
@@ -0,0 +1,66 @@
+package gwk
+
+import (
+	"testing"
+)
+
+func TestProgressBarPercentage(t *testing.T) {
+	cases := []struct {
+		name  string
+		max   float64
+		value float64
+		want  string
+	}{
+		{"empty", 100, 0, "0%"},
+		{"rounded down", 100, 42.9, "42%"},
+		{"short of done", 100, 99.99, "99%"},
+		{"done", 100, 100, "100%"},
+		{"clamped above", 100, 150, "100%"},
+		{"clamped below", 100, -5, "0%"},
+		{"other max", 8, 2, "25%"},
+		{"zero max", 0, 3, "0%"},
+	}
+	for _, c := range cases {
+		bar := NewProgressBar(nil, 0, 0, 200, 20).SetMax(c.max).SetShowPercentage(true)
+		bar.SetValue(c.value)
+		if text := bar.GetText(); text != c.want {
+			t.Errorf("%s: text %q for %v of %v, want %q", c.name, text, c.value, c.max, c.want)
+		}
+	}
+}
+
+func TestProgressBarPercentageModes(t *testing.T) {
+	bar := NewProgressBar(nil, 0, 0, 200, 20).SetValue(30)
+	bar.SetText("copying", false)
+	if bar.GetText() != "copying" {
+		t.Fatalf("text %q without the percentage, want the text set", bar.GetText())
+	}
+
+	bar.SetShowPercentage(true)
+	if bar.GetText() != "30%" {
+		t.Fatalf("text %q once showing the percentage, want 30%%", bar.GetText())
+	}
+	bar.SetMax(60)
+	if bar.GetText() != "50%" {
+		t.Fatalf("text %q after changing max, want 50%%", bar.GetText())
+	}
+
+	bar.SetIndeterminate(true)
+	if bar.GetText() != "" {
+		t.Fatalf("text %q while indeterminate, want none", bar.GetText())
+	}
+	bar.SetValue(45)
+	if bar.GetText() != "" {
+		t.Fatalf("text %q after a value while indeterminate, want none", bar.GetText())
+	}
+	bar.SetIndeterminate(false)
+	if bar.GetText() != "75%" {
+		t.Fatalf("text %q back from indeterminate, want 75%%", bar.GetText())
+	}
+
+	bar.SetShowPercentage(false)
+	bar.SetValue(60)
+	if bar.GetText() != "" {
+		t.Fatalf("text %q after hiding the percentage, want none", bar.GetText())
+	}
+}
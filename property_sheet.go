@@ -0,0 +1,510 @@
+package gwk
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	PROPERTY_SHEET_ROW_HEIGHT    = 28
+	PROPERTY_SHEET_PADDING       = 4
+	PROPERTY_SHEET_LABEL_WIDTH   = 120
+	PROPERTY_SHEET_DEFAULT_GROUP = "General"
+)
+
+// PropertyChangedHandler is called after an edit was written back to the
+// object of a PropertySheet, with the name of the field and its new value.
+type PropertyChangedHandler func(name string, value interface{})
+
+// propertyTag is the parsed `property` tag of a field, a comma separated list
+// of key=value pairs:
+//
+//	Title  string  `property:"label=Window title,group=Window"`
+//	Width  int     `property:"group=Window,min=100,max=1000,step=10"`
+//	Color  string  `property:"type=color"`
+//	Align  string  `property:"options=left|center|right"`
+//	Image  string  `property:"type=file"`
+//	Ratio  float64 `property:"readonly"`
+//	Cache  []byte  `property:"-"`
+//
+// type is one of text, range, color, file and enum, and is guessed from the
+// field and the other keys when left out. Fields of other kinds are skipped.
+type propertyTag struct {
+	label    string
+	group    string
+	t        string
+	min      float64
+	max      float64
+	step     float64
+	hasRange bool
+	options  []string
+	readonly bool
+}
+
+func parsePropertyTag(field reflect.StructField) (tag propertyTag, ok bool) {
+	str := field.Tag.Get("property")
+	if str == "-" {
+		return tag, false
+	}
+
+	hasMin, hasMax := false, false
+	for _, pair := range strings.Split(str, ",") {
+		key, value := strings.TrimSpace(pair), ""
+		if i := strings.IndexByte(key, '='); i >= 0 {
+			key, value = strings.TrimSpace(key[:i]), strings.TrimSpace(key[i+1:])
+		}
+
+		switch key {
+		case "label":
+			tag.label = value
+		case "group":
+			tag.group = value
+		case "type":
+			tag.t = value
+		case "min":
+			tag.min, _ = strconv.ParseFloat(value, 64)
+			hasMin = true
+		case "max":
+			tag.max, _ = strconv.ParseFloat(value, 64)
+			hasMax = true
+		case "step":
+			tag.step, _ = strconv.ParseFloat(value, 64)
+		case "options":
+			tag.options = strings.Split(value, "|")
+		case "readonly":
+			tag.readonly = true
+		}
+	}
+	tag.hasRange = hasMin && hasMax
+
+	if len(tag.label) == 0 {
+		tag.label = splitFieldName(field.Name)
+	}
+	if len(tag.group) == 0 {
+		tag.group = PROPERTY_SHEET_DEFAULT_GROUP
+	}
+
+	kind := field.Type.Kind()
+	switch {
+	case len(tag.t) > 0:
+	case len(tag.options) > 0:
+		tag.t = "enum"
+	case kind == reflect.Bool:
+		tag.t = "bool"
+	case isNumberKind(kind) && tag.hasRange:
+		tag.t = "range"
+	case isNumberKind(kind):
+		tag.t = "number"
+	case kind == reflect.String:
+		tag.t = "text"
+	}
+
+	switch tag.t {
+	case "text", "color", "file":
+		return tag, kind == reflect.String
+	case "enum":
+		return tag, kind == reflect.String || isIntKind(kind)
+	case "range", "number":
+		return tag, isNumberKind(kind)
+	case "bool":
+		return tag, kind == reflect.Bool
+	}
+
+	return tag, false
+}
+
+// propertyRow is a row of a PropertySheet: the label of a field and the
+// editor of its value.
+type propertyRow struct {
+	*Widget
+	sheet  *PropertySheet
+	name   string
+	index  []int
+	label  *Label
+	editor *Widget
+	update func(value reflect.Value)
+}
+
+// PropertySheet edits the exported fields of a struct, one row of a label and
+// an editor per field, grouped under collapsible titles. The editor follows
+// the kind of the field and its `property` tag (see propertyTag): an Edit for
+// text and numbers, a RangeEdit for numbers with a range, a CheckButton for
+// bools, a ColorEdit, a FilenameEdit or a ComboBox of options. Edits are
+// written back to the struct as they are made.
+type PropertySheet struct {
+	*Accordion
+	object            reflect.Value
+	rows              []*propertyRow
+	labelWidth        int
+	onPropertyChanged PropertyChangedHandler
+	onBrowse          FileBrowseHandler
+}
+
+func NewPropertySheet(parent *Widget, x, y, w, h float32) *PropertySheet {
+	sheet := &PropertySheet{
+		Accordion:  NewAccordion(parent, x, y, w, h),
+		labelWidth: PROPERTY_SHEET_LABEL_WIDTH,
+	}
+	sheet.t = TYPE_PROPERTY_SHEET
+	sheet.multiOpen = true
+	sheet.I = sheet
+
+	return sheet
+}
+
+// SetObject builds the rows for object, which must be a pointer to a struct,
+// and panics otherwise. Nil empties the sheet. Groups come in the order of
+// their first field and start open.
+func (sheet *PropertySheet) SetObject(object interface{}) *PropertySheet {
+	for len(sheet.items) > 0 {
+		sheet.RemoveItem(sheet.items[0])
+	}
+	sheet.rows = nil
+	sheet.object = reflect.Value{}
+
+	if object == nil {
+		sheet.PostRedraw()
+		return sheet
+	}
+
+	value := reflect.ValueOf(object)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("PropertySheet: %T is not a pointer to a struct", object))
+	}
+	sheet.object = value
+
+	groups := make(map[string]*AccordionItem)
+	sheet.addFields(value.Elem().Type(), nil, groups)
+	sheet.layoutRows()
+	sheet.PostRedraw()
+
+	return sheet
+}
+
+func (sheet *PropertySheet) GetObject() interface{} {
+	if !sheet.object.IsValid() {
+		return nil
+	}
+
+	return sheet.object.Interface()
+}
+
+// Refresh reloads the editors from the object, after it was changed by other
+// means than the sheet.
+func (sheet *PropertySheet) Refresh() *PropertySheet {
+	for _, row := range sheet.rows {
+		row.update(sheet.getField(row))
+	}
+	sheet.PostRedraw()
+
+	return sheet
+}
+
+// SetLabelWidth sets the width of the label column.
+func (sheet *PropertySheet) SetLabelWidth(labelWidth int) *PropertySheet {
+	sheet.labelWidth = labelWidth
+	sheet.layoutRows()
+
+	return sheet
+}
+
+func (sheet *PropertySheet) GetLabelWidth() int {
+	return sheet.labelWidth
+}
+
+func (sheet *PropertySheet) SetPropertyChangedHandler(onPropertyChanged PropertyChangedHandler) *PropertySheet {
+	sheet.onPropertyChanged = onPropertyChanged
+
+	return sheet
+}
+
+// SetFileBrowseHandler sets the handler the file name editors ask for a file.
+func (sheet *PropertySheet) SetFileBrowseHandler(onBrowse FileBrowseHandler) *PropertySheet {
+	sheet.onBrowse = onBrowse
+	for _, row := range sheet.rows {
+		if row.editor.t == TYPE_FILENAME_EDIT {
+			row.editor.I.(*FilenameEdit).SetBrowseHandler(onBrowse)
+		}
+	}
+
+	return sheet
+}
+
+// Resize resizes the sheet and keeps the rows fitted to its width.
+func (sheet *PropertySheet) Resize(w, h int) *PropertySheet {
+	sheet.Accordion.Resize(w, h)
+	sheet.layoutRows()
+
+	return sheet
+}
+
+// addFields adds a row for each field of t, flattening embedded structs.
+func (sheet *PropertySheet) addFields(t reflect.Type, index []int, groups map[string]*AccordionItem) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			sheet.addFields(field.Type, fieldIndex, groups)
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+
+		tag, ok := parsePropertyTag(field)
+		if !ok {
+			continue
+		}
+
+		item := groups[tag.group]
+		if item == nil {
+			// Groups start open, unless saved closed under the state key.
+			item = sheet.newItem(tag.group, 0)
+			item.title.t = TYPE_PROPERTY_TITLE
+			if !sheet.restoreItem(item) {
+				sheet.setExpanded(item, true, false, false)
+			}
+			sheet.layout()
+			groups[tag.group] = item
+		}
+		sheet.addRow(item, field, fieldIndex, tag)
+	}
+
+	return
+}
+
+func (sheet *PropertySheet) addRow(item *AccordionItem, field reflect.StructField, index []int, tag propertyTag) {
+	row := &propertyRow{
+		Widget: NewWidget(TYPE_KEY_VALUE, item.content, 0, 0, 0, PROPERTY_SHEET_ROW_HEIGHT),
+		sheet:  sheet,
+		name:   field.Name,
+		index:  index,
+	}
+	row.I = row
+
+	row.label = NewLabel(row.Widget, 0, 0, 0, 0)
+	row.label.SetTextAlignH("left")
+	row.label.SetText(tag.label, false)
+	row.editor, row.update = sheet.newEditor(row, field.Type, tag)
+	row.editor.SetEnable(!tag.readonly)
+	row.update(sheet.getField(row))
+
+	sheet.rows = append(sheet.rows, row)
+
+	n := len(row.parent.children)
+	item.SetContentHeight(n*PROPERTY_SHEET_ROW_HEIGHT + 2*PROPERTY_SHEET_PADDING)
+
+	return
+}
+
+// newEditor creates the editor of row and returns it with the function
+// showing a value in it.
+func (sheet *PropertySheet) newEditor(row *propertyRow, t reflect.Type, tag propertyTag) (*Widget, func(reflect.Value)) {
+	parent := row.Widget
+	kind := t.Kind()
+
+	switch tag.t {
+	case "bool":
+		check := NewCheckButton(parent, 0, 0, 0, 0)
+		check.SetChangedHandler(func(interface{}) {
+			sheet.setField(row, reflect.ValueOf(check.IsChecked()))
+		})
+		return check.Widget, func(value reflect.Value) {
+			check.SetChecked(value.Bool(), false)
+		}
+	case "range":
+		rangeEdit := NewRangeEdit(parent, 0, 0, 0, 0)
+		step := tag.step
+		if step == 0 && (isIntKind(kind) || isUintKind(kind)) {
+			step = 1
+		} else if step == 0 {
+			step = 0.01
+		}
+		rangeEdit.SetRange(tag.min, tag.max).SetStep(step)
+		rangeEdit.SetChangedHandler(func(interface{}) {
+			sheet.setNumber(row, rangeEdit.GetValue())
+		})
+		return rangeEdit.Widget, func(value reflect.Value) {
+			rangeEdit.SetValue(getNumber(value), false)
+		}
+	case "number":
+		edit := NewEdit(parent, 0, 0, 0, 0)
+		edit.SetChangedHandler(func(interface{}) {
+			if number, ok := parseNumber(strings.TrimSpace(edit.GetText()), t); ok {
+				sheet.setField(row, number)
+			}
+		})
+		return edit.Widget, func(value reflect.Value) {
+			edit.SetText(fmt.Sprint(value.Interface()), false)
+		}
+	case "color":
+		colorEdit := NewColorEdit(parent, 0, 0, 0, 0)
+		colorEdit.SetChangedHandler(func(interface{}) {
+			sheet.setField(row, reflect.ValueOf(colorEdit.GetColor()))
+		})
+		return colorEdit.Widget, func(value reflect.Value) {
+			colorEdit.SetColor(value.String(), false)
+		}
+	case "file":
+		filenameEdit := NewFilenameEdit(parent, 0, 0, 0, 0)
+		filenameEdit.SetBrowseHandler(sheet.onBrowse)
+		filenameEdit.SetChangedHandler(func(interface{}) {
+			sheet.setField(row, reflect.ValueOf(filenameEdit.GetFilename()))
+		})
+		return filenameEdit.Widget, func(value reflect.Value) {
+			filenameEdit.SetFilename(value.String(), false)
+		}
+	case "enum":
+		combo := NewComboBox(parent, 0, 0, 0, 0)
+		combo.SetOptions(tag.options)
+		if kind == reflect.String {
+			combo.SetChangedHandler(func(interface{}) {
+				sheet.setField(row, reflect.ValueOf(combo.GetValue()))
+			})
+			return combo.Widget, func(value reflect.Value) {
+				combo.SetValue(value.String(), false)
+			}
+		}
+		combo.SetChangedHandler(func(interface{}) {
+			sheet.setNumber(row, float64(combo.GetSelectedIndex()))
+		})
+		return combo.Widget, func(value reflect.Value) {
+			combo.SetSelectedIndex(int(getNumber(value)), false)
+		}
+	}
+
+	edit := NewEdit(parent, 0, 0, 0, 0)
+	edit.SetChangedHandler(func(interface{}) {
+		sheet.setField(row, reflect.ValueOf(edit.GetText()))
+	})
+
+	return edit.Widget, func(value reflect.Value) {
+		edit.SetText(value.String(), false)
+	}
+}
+
+func (sheet *PropertySheet) getField(row *propertyRow) reflect.Value {
+	return sheet.object.Elem().FieldByIndex(row.index)
+}
+
+// setField writes value to the field of row and reports the change.
+func (sheet *PropertySheet) setField(row *propertyRow, value reflect.Value) {
+	field := sheet.getField(row)
+	field.Set(value.Convert(field.Type()))
+
+	if sheet.onPropertyChanged != nil {
+		sheet.onPropertyChanged(row.name, field.Interface())
+	}
+
+	return
+}
+
+// setNumber writes number to the numeric field of row, rounding it for
+// integers.
+func (sheet *PropertySheet) setNumber(row *propertyRow, number float64) {
+	field := sheet.getField(row)
+
+	switch kind := field.Kind(); {
+	case isIntKind(kind):
+		sheet.setField(row, reflect.ValueOf(int64(math.Round(number))))
+	case isUintKind(kind):
+		sheet.setField(row, reflect.ValueOf(uint64(math.Max(0, math.Round(number)))))
+	default:
+		sheet.setField(row, reflect.ValueOf(number))
+	}
+
+	return
+}
+
+// layoutRows stacks the rows of each group and splits them between the label
+// and the editor.
+func (sheet *PropertySheet) layoutRows() {
+	w := sheet.rect.W
+	labelWidth := int(math.Min(float64(sheet.labelWidth), float64(w/2)))
+	left := labelWidth + 2*PROPERTY_SHEET_PADDING
+	editorWidth := int(math.Max(0, float64(w-left-PROPERTY_SHEET_PADDING)))
+
+	for _, item := range sheet.items {
+		for i, child := range item.content.children {
+			child.Move(0, PROPERTY_SHEET_PADDING+i*PROPERTY_SHEET_ROW_HEIGHT)
+			child.Resize(w, PROPERTY_SHEET_ROW_HEIGHT)
+		}
+	}
+
+	for _, row := range sheet.rows {
+		row.label.Move(PROPERTY_SHEET_PADDING, 0)
+		row.label.Resize(labelWidth, PROPERTY_SHEET_ROW_HEIGHT)
+		row.editor.Move(left, 2)
+		row.editor.Resize(editorWidth, PROPERTY_SHEET_ROW_HEIGHT-4)
+	}
+
+	return
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return isIntKind(kind) || isUintKind(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func getNumber(value reflect.Value) float64 {
+	switch kind := value.Kind(); {
+	case isIntKind(kind):
+		return float64(value.Int())
+	case isUintKind(kind):
+		return float64(value.Uint())
+	}
+
+	return value.Float()
+}
+
+// parseNumber parses str as a number of type t, failing on numbers that do
+// not fit in it.
+func parseNumber(str string, t reflect.Type) (reflect.Value, bool) {
+	var value interface{}
+	var err error
+
+	switch kind := t.Kind(); {
+	case isIntKind(kind):
+		value, err = strconv.ParseInt(str, 10, t.Bits())
+	case isUintKind(kind):
+		value, err = strconv.ParseUint(str, 10, t.Bits())
+	default:
+		value, err = strconv.ParseFloat(str, t.Bits())
+	}
+
+	if err != nil {
+		return reflect.Value{}, false
+	}
+
+	return reflect.ValueOf(value), true
+}
+
+// splitFieldName turns a field name like FontSize into the label Font Size.
+func splitFieldName(name string) string {
+	runes := []rune(name)
+	words := make([]rune, 0, len(runes)+4)
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(runes[i-1]) || next {
+				words = append(words, ' ')
+			}
+		}
+		words = append(words, r)
+	}
+
+	return string(words)
+}
@@ -0,0 +1,235 @@
+package gwk
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type sheetLevel int
+
+type sheetName string
+
+type sheetBase struct {
+	Visible bool
+}
+
+// sheetObject has a field for each form of the property tag, and fields the
+// sheet guesses the editor of or skips.
+type sheetObject struct {
+	sheetBase
+	Title    string  `property:"label=Window title,group=Window"`
+	Width    int     `property:"group=Window,min=100,max=1000,step=10"`
+	Color    string  `property:"type=color"`
+	Align    string  `property:"options=left|center|right"`
+	Image    string  `property:"type=file"`
+	Ratio    float64 `property:"readonly"`
+	Cache    []byte  `property:"-"`
+	Name     sheetName
+	Level    sheetLevel `property:"options=low|medium|high"`
+	Depth    int8       `property:"min=-10,max=10"`
+	Count    uint16
+	Scale    float32
+	FontSize int
+	Tags     []string
+	hidden   string
+}
+
+func newSheetScene(object *sheetObject) *PropertySheet {
+	var sheet *PropertySheet
+	newScene(400, 600, func(win *Window) {
+		sheet = NewPropertySheet(win.Widget, 0, 0, 400, 600).SetObject(object)
+	})
+
+	return sheet
+}
+
+func getSheetRow(t *testing.T, sheet *PropertySheet, name string) *propertyRow {
+	t.Helper()
+
+	for _, row := range sheet.rows {
+		if row.name == name {
+			return row
+		}
+	}
+	t.Fatalf("no row for %s", name)
+
+	return nil
+}
+
+func TestPropertySheetRows(t *testing.T) {
+	sheet := newSheetScene(&sheetObject{Width: 200, Align: "center", Level: 1, Depth: 3, Ratio: 0.5})
+
+	rows := []struct {
+		name   string
+		label  string
+		group  string
+		editor string
+		enable bool
+	}{
+		{"Visible", "Visible", "General", "*gwk.CheckButton", true},
+		{"Title", "Window title", "Window", "*gwk.Edit", true},
+		{"Width", "Width", "Window", "*gwk.RangeEdit", true},
+		{"Color", "Color", "General", "*gwk.ColorEdit", true},
+		{"Align", "Align", "General", "*gwk.ComboBox", true},
+		{"Image", "Image", "General", "*gwk.FilenameEdit", true},
+		{"Ratio", "Ratio", "General", "*gwk.Edit", false},
+		{"Name", "Name", "General", "*gwk.Edit", true},
+		{"Level", "Level", "General", "*gwk.ComboBox", true},
+		{"Depth", "Depth", "General", "*gwk.RangeEdit", true},
+		{"Count", "Count", "General", "*gwk.Edit", true},
+		{"Scale", "Scale", "General", "*gwk.Edit", true},
+		{"FontSize", "Font Size", "General", "*gwk.Edit", true},
+	}
+	if len(sheet.rows) != len(rows) {
+		t.Errorf("%d rows, want %d", len(sheet.rows), len(rows))
+	}
+	for i, want := range rows {
+		if i >= len(sheet.rows) {
+			break
+		}
+
+		row := sheet.rows[i]
+		group := row.parent.parent.I.(*AccordionItem).GetTitle()
+		editor := fmt.Sprintf("%T", row.editor.I)
+		if row.name != want.name || row.label.GetText() != want.label || group != want.group || editor != want.editor {
+			t.Errorf("row %d: %s %q in %s edited by %s, want %s %q in %s edited by %s",
+				i, row.name, row.label.GetText(), group, editor, want.name, want.label, want.group, want.editor)
+		}
+		if row.editor.IsEnable() != want.enable {
+			t.Errorf("%s: editor enabled %v, want %v", row.name, row.editor.IsEnable(), want.enable)
+		}
+	}
+
+	if n := sheet.GetItemCount(); n != 2 || sheet.GetItem(0).GetTitle() != "General" || sheet.GetItem(1).GetTitle() != "Window" {
+		t.Errorf("%d groups, want General then Window", n)
+	}
+
+	if value := getSheetRow(t, sheet, "Width").editor.I.(*RangeEdit).GetValue(); value != 200 {
+		t.Errorf("Width shows %v, want 200", value)
+	}
+	if align := getSheetRow(t, sheet, "Align").editor.I.(*ComboBox).GetValue(); align != "center" {
+		t.Errorf("Align shows %q, want %q", align, "center")
+	}
+	if index := getSheetRow(t, sheet, "Level").editor.I.(*ComboBox).GetSelectedIndex(); index != 1 {
+		t.Errorf("Level shows option %d, want 1", index)
+	}
+	if value := getSheetRow(t, sheet, "Depth").editor.I.(*RangeEdit).GetValue(); value != 3 {
+		t.Errorf("Depth shows %v, want 3", value)
+	}
+	if text := getSheetRow(t, sheet, "Ratio").editor.I.(*Edit).GetText(); text != "0.5" {
+		t.Errorf("Ratio shows %q, want %q", text, "0.5")
+	}
+}
+
+func TestPropertySheetWriteBack(t *testing.T) {
+	object := &sheetObject{}
+	sheet := newSheetScene(object)
+	changes := make(map[string]interface{})
+	sheet.SetPropertyChangedHandler(func(name string, value interface{}) {
+		changes[name] = value
+	})
+	editor := func(name string) interface{} {
+		return getSheetRow(t, sheet, name).editor.I
+	}
+
+	editor("Visible").(*CheckButton).SetChecked(true, true)
+	editor("Title").(*Edit).SetText("Main", true)
+	editor("Width").(*RangeEdit).SetValue(437, true)
+	editor("Color").(*ColorEdit).SetColor("#FF0000", true)
+	editor("Align").(*ComboBox).SetValue("right", true)
+	editor("Image").(*FilenameEdit).SetFilename("a.png", true)
+	editor("Name").(*Edit).SetText("bob", true)
+	editor("Level").(*ComboBox).SetSelectedIndex(2, true)
+	editor("Depth").(*RangeEdit).SetValue(-7.4, true)
+	editor("Count").(*Edit).SetText("65535", true)
+	editor("Scale").(*Edit).SetText("1.5", true)
+	editor("FontSize").(*Edit).SetText(" 12 ", true)
+
+	want := sheetObject{
+		sheetBase: sheetBase{Visible: true},
+		Title:     "Main",
+		Width:     440,
+		Color:     "#FF0000",
+		Align:     "right",
+		Image:     "a.png",
+		Name:      "bob",
+		Level:     2,
+		Depth:     -7,
+		Count:     65535,
+		Scale:     1.5,
+		FontSize:  12,
+	}
+	if !reflect.DeepEqual(*object, want) {
+		t.Errorf("object %+v, want %+v", *object, want)
+	}
+	if len(changes) != 12 {
+		t.Errorf("notified %d fields, want 12", len(changes))
+	}
+	if level, ok := changes["Level"].(sheetLevel); !ok || level != 2 {
+		t.Errorf("Level notified %#v, want sheetLevel(2)", changes["Level"])
+	}
+	if name, ok := changes["Name"].(sheetName); !ok || name != "bob" {
+		t.Errorf("Name notified %#v, want sheetName(bob)", changes["Name"])
+	}
+	if depth, ok := changes["Depth"].(int8); !ok || depth != -7 {
+		t.Errorf("Depth notified %#v, want int8(-7)", changes["Depth"])
+	}
+
+	for _, text := range []string{"65536", "-1", "1.5", "x"} {
+		editor("Count").(*Edit).SetText(text, true)
+		if object.Count != 65535 {
+			t.Errorf("typing %q in a uint16 set it to %d", text, object.Count)
+		}
+	}
+	editor("Depth").(*RangeEdit).SetValue(200, true)
+	if object.Depth != 10 {
+		t.Errorf("Depth set past its range to %d, want 10", object.Depth)
+	}
+
+	object.Title = "Other"
+	sheet.Refresh()
+	if text := editor("Title").(*Edit).GetText(); text != "Other" {
+		t.Errorf("Refresh showed %q, want %q", text, "Other")
+	}
+}
+
+func TestPropertySheetSetObject(t *testing.T) {
+	sheet := newSheetScene(&sheetObject{})
+
+	sheet.SetObject(nil)
+	if sheet.GetItemCount() != 0 || len(sheet.rows) != 0 || sheet.GetObject() != nil {
+		t.Errorf("SetObject(nil) left %d groups and %d rows", sheet.GetItemCount(), len(sheet.rows))
+	}
+
+	for _, object := range []interface{}{sheetObject{}, (*sheetObject)(nil), new(int)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SetObject(%T) did not panic", object)
+				}
+			}()
+			sheet.SetObject(object)
+		}()
+	}
+}
+
+func TestPropertySheetGroupsOpen(t *testing.T) {
+	sheet := newSheetScene(&sheetObject{})
+	for _, item := range sheet.GetItems() {
+		if !item.IsExpanded() {
+			t.Fatalf("group %q starts closed", item.GetTitle())
+		}
+	}
+
+	// With a state key the groups come back as the user left them.
+	sheet.SetStateKey("sheet")
+	window := sheet.GetItem(0)
+	window.SetExpanded(false)
+	sheet.SetObject(&sheetObject{})
+	for _, item := range sheet.GetItems() {
+		if item.IsExpanded() != (item.GetTitle() != window.GetTitle()) {
+			t.Fatalf("group %q open %v after setting the object again", item.GetTitle(), item.IsExpanded())
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/image"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+)
+
+// RadioButton is an ImageText with a round indicator in front of the text.
+// Radio buttons added to the same RadioGroup are mutually exclusive. The
+// changed handler receives the new checked value.
+type RadioButton struct {
+	*ImageText
+	checked bool
+	group   *RadioGroup
+}
+
+func NewRadioButton(parent *Widget, x, y, w, h float32) *RadioButton {
+	button := &RadioButton{
+		ImageText: NewImageText(parent, x, y, w, h),
+	}
+	button.t = TYPE_RADIO_BUTTON
+	button.checkable = true
+	button.setChecked = func(checked, notify bool) *Widget {
+		return button.SetChecked(checked, notify).Widget
+	}
+	button.I = button
+
+	return button
+}
+
+// SetChecked checks or unchecks the button. Checking it unchecks the other
+// buttons of its group.
+func (button *RadioButton) SetChecked(checked, notify bool) *RadioButton {
+	if button.checked == checked {
+		return button
+	}
+
+	button.checked = checked
+	if checked && button.group != nil {
+		button.group.onChecked(button, notify)
+	}
+
+	if notify && button.onChanged != nil {
+		button.onChanged(checked)
+	}
+	button.PostRedraw()
+
+	return button
+}
+
+func (button *RadioButton) IsChecked() bool {
+	return button.checked
+}
+
+func (button *RadioButton) GetGroup() *RadioGroup {
+	return button.group
+}
+
+func (button *RadioButton) onPointerDown(point *structs.Point) {
+	if window := button.GetWindow(); window != nil && button.enable {
+		window.SetFocus(button.Widget)
+	}
+	button.Widget.onPointerDown(point)
+
+	return
+}
+
+func (button *RadioButton) onPointerUp(point *structs.Point) {
+	if button.enable && button.isClicked() {
+		button.SetChecked(true, true)
+	}
+	button.Widget.onPointerUp(point)
+
+	return
+}
+
+func (button *RadioButton) onKeyDown(code int) {
+	if button.enable {
+		switch code {
+		case keyevent.DOM_VK_SPACE:
+			button.SetChecked(true, true)
+		case keyevent.DOM_VK_UP, keyevent.DOM_VK_LEFT:
+			button.moveInGroup(-1)
+		case keyevent.DOM_VK_DOWN, keyevent.DOM_VK_RIGHT:
+			button.moveInGroup(1)
+		}
+	}
+	button.Widget.onKeyDown(code)
+
+	return
+}
+
+// moveInGroup checks and focuses the next enabled button of the group in
+// direction delta, wrapping around.
+func (button *RadioButton) moveInGroup(delta int) {
+	group := button.group
+	if group == nil {
+		return
+	}
+
+	n := len(group.buttons)
+	i := group.indexOf(button)
+	for k := 1; k < n; k++ {
+		next := group.buttons[((i+delta*k)%n+n)%n]
+		if next.enable && next.visible {
+			next.SetChecked(true, true)
+			if window := next.GetWindow(); window != nil {
+				window.SetFocus(next.Widget)
+			}
+			break
+		}
+	}
+
+	return
+}
+
+func (button *RadioButton) paintSelf(context canvas.Canvas2D) {
+	style := button.getStyle("")
+	size := button.paintIndicatorText(context, style)
+	x := button.border
+	y := (button.rect.H - size) >> 1
+
+	var img *image.Image
+	if button.checked {
+		img = style.CheckedImage
+	} else {
+		img = style.UncheckedImage
+	}
+
+	if img != nil {
+		img.Draw(context, image.DISPLAY_AUTO_SIZE_DOWN, x, y, size, size)
+		return
+	}
+
+	r := float64(size) / 2
+	cx := float64(x) + r
+	cy := float64(y) + r
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(style.TextColor)
+	context.BeginPath()
+	context.Arc(cx, cy, r-0.5, 0, 2*math.Pi, false)
+	context.Stroke()
+
+	if button.checked {
+		context.SetFillStyle(style.TextColor)
+		context.BeginPath()
+		context.Arc(cx, cy, r/2, 0, 2*math.Pi, false)
+		context.Fill()
+	}
+
+	return
+}
+
+// RadioGroup keeps at most one of its radio buttons checked. Its changed
+// handler receives the newly checked *RadioButton.
+type RadioGroup struct {
+	buttons   []*RadioButton
+	onChanged OnChangedHandler
+}
+
+func NewRadioGroup(buttons ...*RadioButton) *RadioGroup {
+	group := &RadioGroup{}
+	group.Add(buttons...)
+
+	return group
+}
+
+// Add moves buttons into the group. If more than one of them is checked,
+// only the last stays checked.
+func (group *RadioGroup) Add(buttons ...*RadioButton) *RadioGroup {
+	for _, button := range buttons {
+		if button.group != nil {
+			button.group.Remove(button)
+		}
+
+		button.group = group
+		group.buttons = append(group.buttons, button)
+		if button.checked {
+			group.onChecked(button, false)
+		}
+	}
+
+	return group
+}
+
+func (group *RadioGroup) Remove(button *RadioButton) *RadioGroup {
+	if i := group.indexOf(button); i >= 0 {
+		group.buttons = append(group.buttons[:i], group.buttons[i+1:]...)
+		button.group = nil
+	}
+
+	return group
+}
+
+func (group *RadioGroup) GetButtons() []*RadioButton {
+	return group.buttons
+}
+
+func (group *RadioGroup) GetChecked() *RadioButton {
+	for _, button := range group.buttons {
+		if button.checked {
+			return button
+		}
+	}
+
+	return nil
+}
+
+// GetCheckedIndex returns the index of the checked button, or -1.
+func (group *RadioGroup) GetCheckedIndex() int {
+	return group.indexOf(group.GetChecked())
+}
+
+func (group *RadioGroup) SetCheckedIndex(index int, notify bool) *RadioGroup {
+	if index >= 0 && index < len(group.buttons) {
+		group.buttons[index].SetChecked(true, notify)
+	}
+
+	return group
+}
+
+func (group *RadioGroup) SetChangedHandler(onChanged OnChangedHandler) *RadioGroup {
+	group.onChanged = onChanged
+
+	return group
+}
+
+func (group *RadioGroup) indexOf(button *RadioButton) int {
+	for i, iter := range group.buttons {
+		if iter == button {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (group *RadioGroup) onChecked(checked *RadioButton, notify bool) {
+	for _, button := range group.buttons {
+		if button != checked && button.checked {
+			button.SetChecked(false, notify)
+		}
+	}
+
+	if notify && group.onChanged != nil {
+		group.onChanged(checked)
+	}
+
+	return
+}
@@ -0,0 +1,182 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	RANGE_EDIT_EDIT_WIDTH = 60
+	RANGE_EDIT_SPACING    = 8
+)
+
+// rangeEditEdit is the Edit of a RangeEdit. Enter rewrites the text as the
+// value it set, and Up and Down step the value.
+type rangeEditEdit struct {
+	*Edit
+	rangeEdit *RangeEdit
+}
+
+func (edit *rangeEditEdit) onKeyDown(code int) {
+	slider := edit.rangeEdit.slider
+
+	switch code {
+	case keyevent.DOM_VK_RETURN:
+		edit.rangeEdit.updateText()
+	case keyevent.DOM_VK_UP:
+		slider.setValue(slider.value+slider.getArrowStep(), true)
+	case keyevent.DOM_VK_DOWN:
+		slider.setValue(slider.value-slider.getArrowStep(), true)
+	case keyevent.DOM_VK_PAGE_UP:
+		slider.setValue(slider.value+slider.getPageStep(), true)
+	case keyevent.DOM_VK_PAGE_DOWN:
+		slider.setValue(slider.value-slider.getPageStep(), true)
+	default:
+		edit.Edit.onKeyDown(code)
+	}
+
+	return
+}
+
+// RangeEdit pairs a Slider with an Edit showing its value. Typing a number in
+// range moves the slider and moving the slider rewrites the text. The changed
+// handler receives the new float64 value.
+type RangeEdit struct {
+	*Widget
+	slider *Slider
+	edit   *rangeEditEdit
+	typing bool
+}
+
+func NewRangeEdit(parent *Widget, x, y, w, h float32) *RangeEdit {
+	rangeEdit := &RangeEdit{
+		Widget: NewWidget(TYPE_RANGE_EDIT, parent, x, y, w, h),
+	}
+	rangeEdit.I = rangeEdit
+
+	rangeEdit.slider = NewSlider(rangeEdit.Widget, 0, 0, 0, 0)
+	rangeEdit.slider.SetChangedHandler(func(interface{}) {
+		rangeEdit.onSliderChanged()
+	})
+
+	rangeEdit.edit = &rangeEditEdit{
+		Edit:      NewEdit(rangeEdit.Widget, 0, 0, 0, 0),
+		rangeEdit: rangeEdit,
+	}
+	rangeEdit.edit.I = rangeEdit.edit
+	rangeEdit.edit.SetChangedHandler(func(interface{}) {
+		rangeEdit.onTextChanged()
+	})
+
+	rangeEdit.layout()
+	rangeEdit.updateText()
+
+	return rangeEdit
+}
+
+func (rangeEdit *RangeEdit) GetSlider() *Slider {
+	return rangeEdit.slider
+}
+
+func (rangeEdit *RangeEdit) GetEdit() *Edit {
+	return rangeEdit.edit.Edit
+}
+
+func (rangeEdit *RangeEdit) SetRange(min, max float64) *RangeEdit {
+	rangeEdit.slider.SetRange(min, max)
+	rangeEdit.updateText()
+
+	return rangeEdit
+}
+
+func (rangeEdit *RangeEdit) SetStep(step float64) *RangeEdit {
+	rangeEdit.slider.SetStep(step)
+	rangeEdit.updateText()
+
+	return rangeEdit
+}
+
+func (rangeEdit *RangeEdit) GetValue() float64 {
+	return rangeEdit.slider.GetValue()
+}
+
+func (rangeEdit *RangeEdit) SetValue(value float64, notify bool) *RangeEdit {
+	rangeEdit.slider.SetValue(value, notify)
+	rangeEdit.updateText()
+
+	return rangeEdit
+}
+
+// formatValue prints value with the decimals of the step grid.
+func (rangeEdit *RangeEdit) formatValue(value float64) string {
+	slider := rangeEdit.slider
+	if slider.step > 0 {
+		return strconv.FormatFloat(value, 'f', slider.getStepDecimals(), 64)
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func (rangeEdit *RangeEdit) updateText() {
+	text := rangeEdit.formatValue(rangeEdit.slider.value)
+	if rangeEdit.edit.GetText() != text {
+		rangeEdit.edit.SetText(text, false)
+	}
+
+	return
+}
+
+func (rangeEdit *RangeEdit) onSliderChanged() {
+	if !rangeEdit.typing {
+		rangeEdit.updateText()
+	}
+
+	if rangeEdit.onChanged != nil {
+		rangeEdit.onChanged(rangeEdit.slider.value)
+	}
+
+	return
+}
+
+// onTextChanged moves the slider to the number typed, leaving the text alone
+// while it is not a number in range yet.
+func (rangeEdit *RangeEdit) onTextChanged() {
+	slider := rangeEdit.slider
+	value, err := strconv.ParseFloat(strings.TrimSpace(rangeEdit.edit.GetText()), 64)
+	if err != nil || value < slider.min || value > slider.max {
+		return
+	}
+
+	rangeEdit.typing = true
+	slider.setValue(value, true)
+	rangeEdit.typing = false
+
+	return
+}
+
+func (rangeEdit *RangeEdit) layout() {
+	w := rangeEdit.rect.W
+	h := rangeEdit.rect.H
+	editWidth := int(math.Min(RANGE_EDIT_EDIT_WIDTH, float64(w)))
+
+	rangeEdit.slider.Move(0, 0)
+	rangeEdit.slider.Resize(int(math.Max(0, float64(w-editWidth-RANGE_EDIT_SPACING))), h)
+	rangeEdit.edit.Move(w-editWidth, 0)
+	rangeEdit.edit.Resize(editWidth, h)
+
+	return
+}
+
+func (rangeEdit *RangeEdit) relayout(context canvas.Canvas2D, force bool) {
+	rangeEdit.layout()
+	rangeEdit.Widget.relayout(context, force)
+
+	return
+}
+
+func (rangeEdit *RangeEdit) paintBackground(context canvas.Canvas2D) {
+	return
+}
@@ -0,0 +1,93 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"testing"
+)
+
+// newRangeEditScene returns a RangeEdit 0..10 by 0.5 at 10,10 with its Edit
+// focused and emptied.
+func newRangeEditScene() (typist, *RangeEdit, *[]float64) {
+	var rangeEdit *RangeEdit
+	k := newScene(300, 60, func(win *Window) {
+		rangeEdit = NewRangeEdit(win.Widget, 10, 10, 260, 30).SetRange(0, 10).SetStep(0.5)
+	})
+	values := &[]float64{}
+	rangeEdit.SetChangedHandler(func(value interface{}) {
+		*values = append(*values, value.(float64))
+	})
+	k.click(250, 25)
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_A)
+	k.key(keyevent.DOM_VK_BACK_SPACE)
+
+	return k, rangeEdit, values
+}
+
+func TestRangeEditTyping(t *testing.T) {
+	k, rangeEdit, values := newRangeEditScene()
+
+	steps := []struct {
+		text  string
+		value float64
+	}{
+		{"1", 1},
+		{".", 1},
+		{"2", 1},
+		{"6", 1.5},
+		{"9", 1.5},
+	}
+	typed := ""
+	for _, step := range steps {
+		typed += step.text
+		k.typeText(step.text)
+		if text := rangeEdit.GetEdit().GetText(); text != typed {
+			t.Errorf("typing %q: text rewritten to %q", typed, text)
+		}
+		if value := rangeEdit.GetValue(); value != step.value {
+			t.Errorf("typing %q: value %v, want %v", typed, value, step.value)
+		}
+	}
+	if want := []float64{1, 1.5}; len(*values) != len(want) || (*values)[0] != want[0] || (*values)[1] != want[1] {
+		t.Errorf("notified %v, want %v", *values, want)
+	}
+
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_A)
+	k.typeText("12")
+	if text, value := rangeEdit.GetEdit().GetText(), rangeEdit.GetValue(); text != "12" || value != 1 {
+		t.Errorf("typing out of range: %q value %v, want %q value 1", text, value, "12")
+	}
+
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_A)
+	k.typeText("3.7")
+	if text, value := rangeEdit.GetEdit().GetText(), rangeEdit.GetValue(); text != "3.7" || value != 3.5 {
+		t.Errorf("typing off the step: %q value %v, want %q value 3.5", text, value, "3.7")
+	}
+
+	k.key(keyevent.DOM_VK_RETURN)
+	if text := rangeEdit.GetEdit().GetText(); text != "3.5" {
+		t.Errorf("enter rewrote the text to %q, want %q", text, "3.5")
+	}
+}
+
+func TestRangeEditFollowsSlider(t *testing.T) {
+	k, rangeEdit, values := newRangeEditScene()
+	k.typeText("2")
+
+	k.key(keyevent.DOM_VK_UP)
+	if text, value := rangeEdit.GetEdit().GetText(), rangeEdit.GetValue(); text != "2.5" || value != 2.5 {
+		t.Errorf("up: %q value %v, want %q value 2.5", text, value, "2.5")
+	}
+
+	rangeEdit.GetSlider().SetValue(7.25, true)
+	if text := rangeEdit.GetEdit().GetText(); text != "7.5" {
+		t.Errorf("moving the slider rewrote the text to %q, want %q", text, "7.5")
+	}
+	if last := (*values)[len(*values)-1]; last != 7.5 {
+		t.Errorf("moving the slider notified %v, want 7.5", last)
+	}
+
+	rangeEdit.SetValue(0, false)
+	if text := rangeEdit.GetEdit().GetText(); text != "0.0" {
+		t.Errorf("SetValue rewrote the text to %q, want %q", text, "0.0")
+	}
+}
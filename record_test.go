@@ -0,0 +1,127 @@
+package gwk
+
+import (
+	"bytes"
+	"github.com/Luncher/gwk/pkg/event"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"reflect"
+	"testing"
+)
+
+// recordScene is a window with a check button, an edit and a button, under
+// a second window overlapping the button.
+type recordScene struct {
+	check       *CheckButton
+	edit        *Edit
+	lowerClicks int
+	upperClicks int
+}
+
+func newRecordScene() (typist, *recordScene) {
+	scene := &recordScene{}
+	k := newScene(300, 120, func(lower *Window) {
+		scene.check = NewCheckButton(lower.Widget, 10, 10, 100, 30)
+		scene.edit = NewEdit(lower.Widget, 10, 50, 120, 30)
+		NewButton(lower.Widget, 140, 10, 60, 30).SetClickedHandler(func(*Widget, *structs.Point) {
+			scene.lowerClicks++
+		})
+
+		upper := NewWindow(lower.manager, 150, 0, 100, 100)
+		NewButton(upper.Widget, 0, 0, 100, 100).SetClickedHandler(func(*Widget, *structs.Point) {
+			scene.upperClicks++
+		})
+	})
+
+	return k, scene
+}
+
+func (scene *recordScene) verify(t *testing.T, name string) {
+	t.Helper()
+
+	if !scene.check.IsChecked() {
+		t.Errorf("%s: check button not checked", name)
+	}
+	if text := scene.edit.GetText(); text != "ab" {
+		t.Errorf("%s: edit text %q, want %q", name, text, "ab")
+	}
+	if scene.lowerClicks != 0 || scene.upperClicks != 1 {
+		t.Errorf("%s: clicks lower %d upper %d, want 0 and 1", name, scene.lowerClicks, scene.upperClicks)
+	}
+}
+
+func recordEvents() []*event.Event {
+	events := []*event.Event{
+		event.NewPointerEvent(event.EVENT_POINTER_DOWN, 20, 20),
+		event.NewPointerEvent(event.EVENT_POINTER_UP, 20, 20),
+		event.NewPointerEvent(event.EVENT_POINTER_DOWN, 50, 60),
+		event.NewPointerEvent(event.EVENT_POINTER_UP, 50, 60),
+	}
+	for _, c := range "abc" {
+		events = append(events, event.NewKeyPressEvent(c))
+	}
+
+	return append(events,
+		event.NewKeyEvent(event.EVENT_KEY_DOWN, keyevent.DOM_VK_BACK_SPACE),
+		event.NewKeyEvent(event.EVENT_KEY_UP, keyevent.DOM_VK_BACK_SPACE),
+		event.NewPointerEvent(event.EVENT_POINTER_MOVE, 170, 20),
+		event.NewPointerEvent(event.EVENT_POINTER_DOWN, 170, 20),
+		event.NewPointerEvent(event.EVENT_POINTER_UP, 170, 20))
+}
+
+func TestInjectTargetsTopWindow(t *testing.T) {
+	k, scene := newRecordScene()
+	for _, e := range recordEvents() {
+		k.m.Inject(e)
+	}
+
+	scene.verify(t, "inject")
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	k, scene := newRecordScene()
+	recorder := event.NewRecorder(k.m)
+	for _, e := range recordEvents() {
+		event.Dispatch(recorder, e)
+	}
+	scene.verify(t, "recorded")
+
+	recording := recorder.GetRecording()
+	if len(recording.Events) != len(recordEvents()) {
+		t.Fatalf("recorded %d events, want %d", len(recording.Events), len(recordEvents()))
+	}
+	for i := 1; i < len(recording.Events); i++ {
+		if recording.Events[i].Time < recording.Events[i-1].Time {
+			t.Errorf("event %d recorded at %v, before event %d at %v", i, recording.Events[i].Time, i-1, recording.Events[i-1].Time)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := recording.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := event.LoadRecording(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, recording) {
+		t.Fatalf("recording changed through JSON:\n%+v\n%+v", loaded.Events, recording.Events)
+	}
+
+	k, scene = newRecordScene()
+	replayer := event.NewReplayer(k.m, loaded)
+	replayer.PlayAll()
+	if !replayer.Done() {
+		t.Errorf("PlayAll left events to play")
+	}
+	scene.verify(t, "PlayAll")
+
+	replayer.Rewind()
+	if replayer.Done() {
+		t.Fatalf("Rewind did not go back to the first event")
+	}
+	// newScene resets the manager the replayer plays into.
+	_, scene = newRecordScene()
+	replayer.Play()
+	scene.verify(t, "Play")
+}
@@ -2,22 +2,58 @@ package gwk
 
 import (
 	"fmt"
+	"github.com/Luncher/gwk/pkg/canvas"
 	"github.com/Luncher/gwk/pkg/image"
 	"github.com/Luncher/gwk/pkg/structs"
-	"honnef.co/go/js/dom"
 	"math"
 )
 
+// barDrag follows the pointer dragging the thumb of a bar along its long
+// axis, for the scroll bars and the sliders.
+type barDrag struct {
+	dragging   bool
+	horizontal bool
+	downPoint  structs.Point
+	downValue  float64
+}
+
+func (drag *barDrag) begin(point *structs.Point, horizontal bool, value float64) {
+	drag.dragging = true
+	drag.horizontal = horizontal
+	drag.downPoint = *point
+	drag.downValue = value
+
+	return
+}
+
+func (drag *barDrag) end() {
+	drag.dragging = false
+
+	return
+}
+
+// valueAt returns the value for the pointer at point, moving the thumb by
+// length pixels changing the value by valueRange.
+func (drag *barDrag) valueAt(point *structs.Point, length, valueRange float64) float64 {
+	if length <= 0 {
+		return drag.downValue
+	}
+
+	delta := point.Y - drag.downPoint.Y
+	if drag.horizontal {
+		delta = point.X - drag.downPoint.X
+	}
+
+	return drag.downValue + (float64(delta)/length)*valueRange
+}
+
 type ScrollBar struct {
 	*Widget
-	dragging             bool
-	scrollRange          float64
-	currentPosition      float64
-	draggerRect          *structs.Rect
-	pointerDownPoint     *structs.Point
-	lastPointerPoint     *structs.Point
-	currentPositionSaved float64
-	scrolledHandler      onScrolledHandler
+	drag            barDrag
+	scrollRange     float64
+	currentPosition float64
+	draggerRect     *structs.Rect
+	scrolledHandler onScrolledHandler
 }
 
 type onScrolledHandler func(currentPosition, scrollRange float64)
@@ -42,15 +78,12 @@ func (bar *ScrollBar) onScrolled(currentPosition, scrollRange float64) {
 }
 
 func (bar *ScrollBar) onPointerDown(point *structs.Point) {
-	bar.getWindow().grab(bar.Widget)
-	bar.pointerDownPoint.X = point.X
-	bar.pointerDownPoint.Y = point.Y
+	bar.GetWindow().Grab(bar.Widget)
 	p := bar.translatePoint(point)
 
 	if bar.draggerRect != nil && isPointInRect(p, bar.draggerRect) {
-		bar.dragging = true
+		bar.drag.begin(point, bar.rect.W > bar.rect.H, bar.currentPosition)
 		bar.setState(STATE_ACTIVE, false)
-		bar.currentPositionSaved = bar.currentPosition
 	} else {
 		bar.setState(STATE_NORMAL, false)
 	}
@@ -59,16 +92,12 @@ func (bar *ScrollBar) onPointerDown(point *structs.Point) {
 }
 
 func (bar *ScrollBar) onPointerMove(point *structs.Point) {
-	if bar.dragging {
-		ww := float64(bar.rect.W)
-		hh := float64(bar.rect.H)
-		if ww > hh {
-			dx := float64(point.X - bar.pointerDownPoint.X)
-			bar.setCurrentPosition(bar.currentPositionSaved + (dx/ww)*bar.scrollRange)
-		} else {
-			dy := float64(point.Y - bar.pointerDownPoint.Y)
-			bar.setCurrentPosition(bar.currentPositionSaved + (dy/hh)*bar.scrollRange)
+	if bar.drag.dragging {
+		length := float64(bar.rect.H)
+		if bar.drag.horizontal {
+			length = float64(bar.rect.W)
 		}
+		bar.SetCurrentPosition(bar.drag.valueAt(point, length, bar.scrollRange))
 	}
 
 	return
@@ -81,7 +110,7 @@ func (bar *ScrollBar) onPointerUp(point *structs.Point) {
 		return
 	}
 
-	if !bar.dragging {
+	if !bar.drag.dragging {
 		r := bar.draggerRect
 		p := bar.translatePoint(point)
 		if ww > hh {
@@ -98,37 +127,37 @@ func (bar *ScrollBar) onPointerUp(point *structs.Point) {
 			}
 		}
 	}
-	bar.dragging = false
-	bar.getWindow().ungrab()
+	bar.drag.end()
+	bar.GetWindow().Ungrab()
 	bar.setState(STATE_NORMAL, false)
 
 	return
 }
 
-func (bar *ScrollBar) setScrollRange(val float64) {
+func (bar *ScrollBar) SetScrollRange(val float64) {
 	bar.scrollRange = val
 	bar.updateDraggerSize()
 
 	return
 }
 
-func (bar *ScrollBar) getScrollRange() float64 {
+func (bar *ScrollBar) GetScrollRange() float64 {
 	return bar.scrollRange
 }
 
 func (bar *ScrollBar) addToCurrentPosition(delta float64) {
 	currentPosition := bar.currentPosition + delta
-	bar.setCurrentPosition(currentPosition)
+	bar.SetCurrentPosition(currentPosition)
 
 	return
 }
 
-func (bar *ScrollBar) getCurrentPosition() float64 {
+func (bar *ScrollBar) GetCurrentPosition() float64 {
 	return bar.currentPosition
 }
 
-func (bar *ScrollBar) setCurrentPosition(currentPosition float64) {
-	size := math.Max(float64(bar.getWidth()), float64(bar.getHeight()))
+func (bar *ScrollBar) SetCurrentPosition(currentPosition float64) {
+	size := math.Max(float64(bar.GetWidth()), float64(bar.GetHeight()))
 	bar.currentPosition = math.Max(math.Min(bar.scrollRange-size, currentPosition), 0)
 
 	bar.PostRedraw()
@@ -172,14 +201,14 @@ func (bar *ScrollBar) updateDraggerSize() {
 	return
 }
 
-func (bar *ScrollBar) paintSelf(context *dom.CanvasRenderingContext2D) {
+func (bar *ScrollBar) paintSelf(context canvas.Canvas2D) {
 	if bar.draggerRect != nil {
 		r := bar.draggerRect
 		style := bar.getStyle("")
 		if style.FgImage != nil {
 			style.FgImage.Draw(context, image.DISPLAY_9PATCH, r.X, r.Y, r.W, r.H)
 		} else {
-			context.FillStyle = style.DragColor
+			context.SetFillStyle(style.DragColor)
 			context.FillRect(float64(r.X), float64(r.Y), float64(r.W), float64(r.H))
 		}
 	}
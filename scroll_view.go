@@ -1,9 +1,9 @@
 package gwk
 
 import (
+	"github.com/Luncher/gwk/pkg/canvas"
 	"github.com/Luncher/gwk/pkg/keyevent"
 	"github.com/Luncher/gwk/pkg/structs"
-	"honnef.co/go/js/dom"
 	"math"
 )
 
@@ -54,6 +54,7 @@ func NewScrollView(parent *Widget, x, y, w, h float32) *ScrollView {
 		isScrollView:  true,
 		scrollBarSize: 8,
 	}
+	scrollView.I = scrollView
 
 	scrollView.vScrollBar =
 		NewVScrollBar(scrollView.Widget, w-float32(scrollView.scrollBarSize), 0, float32(scrollView.scrollBarSize), h)
@@ -83,33 +84,33 @@ func (view *ScrollView) onScrolledV(offset float64) {
 
 }
 
-func (view *ScrollView) getScrollPositionH() float64 {
-	return view.hScrollBar.getCurrentPosition()
+func (view *ScrollView) GetScrollPositionH() float64 {
+	return view.hScrollBar.GetCurrentPosition()
 }
 
-func (view *ScrollView) getScrollPositionV() float64 {
-	return view.vScrollBar.getCurrentPosition()
+func (view *ScrollView) GetScrollPositionV() float64 {
+	return view.vScrollBar.GetCurrentPosition()
 }
 
-func (view *ScrollView) setScrollPositionH(position float64) {
-	view.hScrollBar.setCurrentPosition(position)
+func (view *ScrollView) SetScrollPositionH(position float64) {
+	view.hScrollBar.SetCurrentPosition(position)
 
 	return
 }
 
-func (view *ScrollView) setScrollPositionV(position float64) {
-	view.vScrollBar.setCurrentPosition(position)
+func (view *ScrollView) SetScrollPositionV(position float64) {
+	view.vScrollBar.SetCurrentPosition(position)
 
 	return
 }
 
-func (view *ScrollView) setScrollBarSize(scrollBarSize float64) {
+func (view *ScrollView) SetScrollBarSize(scrollBarSize float64) {
 	view.scrollBarSize = scrollBarSize
 
 	return
 }
 
-func (view *ScrollView) setScrollType(t ScrollType) {
+func (view *ScrollView) SetScrollType(t ScrollType) {
 	view.scrollType = t
 
 	return
@@ -131,13 +132,13 @@ func (view *ScrollView) setNeedRelayout(value bool) {
 	return
 }
 
-func (view *ScrollView) relayout(context *dom.CanvasRenderingContext2D, force bool) {
+func (view *ScrollView) relayout(context canvas.Canvas2D, force bool) {
 	if view.needRelayout || force {
-		v := view.getScrollPositionV()
+		v := view.GetScrollPositionV()
 		view.updateScrollBar()
 		view.needRelayout = false
 		view.onRelayout(view.workArea, view.virtualSize)
-		view.setScrollPositionV(v)
+		view.SetScrollPositionV(v)
 	}
 
 	return
@@ -179,46 +180,46 @@ func (view *ScrollView) updateScrollBar() bool {
 	hScrollBar := view.hScrollBar
 	scrollBarSize := view.scrollBarSize
 
-	vScrollBar.resize(int(scrollBarSize), rect.H-int(scrollBarSize))
-	vScrollBar.move(rect.W-int(scrollBarSize), 0)
-	hScrollBar.resize(rect.W-int(scrollBarSize), int(scrollBarSize))
-	hScrollBar.move(0, rect.H-int(scrollBarSize))
+	vScrollBar.Resize(int(scrollBarSize), rect.H-int(scrollBarSize))
+	vScrollBar.Move(rect.W-int(scrollBarSize), 0)
+	hScrollBar.Resize(rect.W-int(scrollBarSize), int(scrollBarSize))
+	hScrollBar.Move(0, rect.H-int(scrollBarSize))
 
 	switch view.scrollType {
 	case SCROLL_TYPE_V:
-		hScrollBar.show(false)
-		vScrollBar.show(true)
-		vScrollBar.setScrollRange(float64(size.H))
-		vScrollBar.setCurrentPosition(0)
-		vScrollBar.resize(int(scrollBarSize), rect.H)
+		hScrollBar.Show(false)
+		vScrollBar.Show(true)
+		vScrollBar.SetScrollRange(float64(size.H))
+		vScrollBar.SetCurrentPosition(0)
+		vScrollBar.Resize(int(scrollBarSize), rect.H)
 	case SCROLL_TYPE_H:
-		vScrollBar.show(false)
-		hScrollBar.show(true)
-		hScrollBar.setScrollRange(float64(size.W))
-		hScrollBar.setCurrentPosition(0)
-		hScrollBar.resize(rect.W, int(scrollBarSize))
+		vScrollBar.Show(false)
+		hScrollBar.Show(true)
+		hScrollBar.SetScrollRange(float64(size.W))
+		hScrollBar.SetCurrentPosition(0)
+		hScrollBar.Resize(rect.W, int(scrollBarSize))
 	case SCROLL_TYPE_BOTH:
-		vScrollBar.show(true)
-		hScrollBar.show(true)
-		vScrollBar.setScrollRange(float64(size.H))
-		vScrollBar.setCurrentPosition(0)
-		hScrollBar.setScrollRange(float64(size.W))
-		hScrollBar.setCurrentPosition(0)
+		vScrollBar.Show(true)
+		hScrollBar.Show(true)
+		vScrollBar.SetScrollRange(float64(size.H))
+		vScrollBar.SetCurrentPosition(0)
+		hScrollBar.SetScrollRange(float64(size.W))
+		hScrollBar.SetCurrentPosition(0)
 	case SCROLL_TYPE_NONE:
-		vScrollBar.show(false)
-		vScrollBar.show(false)
+		vScrollBar.Show(false)
+		vScrollBar.Show(false)
 	default:
 		if size.W > rect.W {
-			hScrollBar.setScrollRange(float64(size.W))
-			hScrollBar.setCurrentPosition(0)
+			hScrollBar.SetScrollRange(float64(size.W))
+			hScrollBar.SetCurrentPosition(0)
 		} else {
-			hScrollBar.show(false)
+			hScrollBar.Show(false)
 		}
 		if size.H > rect.H {
-			vScrollBar.setScrollRange(float64(size.H))
-			vScrollBar.setCurrentPosition(0)
+			vScrollBar.SetScrollRange(float64(size.H))
+			vScrollBar.SetCurrentPosition(0)
 		} else {
-			vScrollBar.show(false)
+			vScrollBar.Show(false)
 		}
 	}
 
@@ -235,7 +236,7 @@ func (view *ScrollView) updateScrollBar() bool {
 	return true
 }
 
-func (view *ScrollView) paintChildren(context *dom.CanvasRenderingContext2D) {
+func (view *ScrollView) paintChildren(context canvas.Canvas2D) {
 	ww := view.rect.W
 	hh := view.rect.H
 	xOffset := view.getXOffset()
@@ -356,32 +357,34 @@ func (view *ScrollView) onContextMenu(point *structs.Point) {
 }
 
 func (view *ScrollView) getXOffset() float64 {
-	return view.hScrollBar.getCurrentPosition()
+	return view.hScrollBar.GetCurrentPosition()
 }
 
 func (view *ScrollView) getYOffset() float64 {
-	return view.vScrollBar.getCurrentPosition()
+	return view.vScrollBar.GetCurrentPosition()
 }
 
 func (view *ScrollView) getXScrollRange() float64 {
-	return view.hScrollBar.getScrollRange()
+	return view.hScrollBar.GetScrollRange()
 }
 
 func (view *ScrollView) getYScrollRange() float64 {
-	return view.vScrollBar.getScrollRange()
+	return view.vScrollBar.GetScrollRange()
 }
 
 func (view *ScrollView) setXOffset(xOffset float64) {
-	view.hScrollBar.setCurrentPosition(xOffset)
+	view.hScrollBar.SetCurrentPosition(xOffset)
 }
 
 func (view *ScrollView) setYOffset(yOffset float64) {
-	view.vScrollBar.setCurrentPosition(yOffset)
+	view.vScrollBar.SetCurrentPosition(yOffset)
 }
 
-func (view *ScrollView) onWheel(delta float64) {
+func (view *ScrollView) onWheel(delta float64) bool {
 	yOffset := view.getYOffset() + delta
 	view.setYOffset(yOffset)
+
+	return true
 }
 
 func (view *ScrollView) onKeyDown(code int) {
@@ -399,13 +402,13 @@ func (view *ScrollView) onKeyDown(code int) {
 	case keyevent.DOM_VK_DOWN:
 		yOffset = view.getYOffset() + delta
 	case keyevent.DOM_VK_PAGE_UP:
-		yOffset = view.getYOffset() - float64(view.getHeight()) + delta
+		yOffset = view.getYOffset() - float64(view.GetHeight()) + delta
 	case keyevent.DOM_VK_PAGE_DOWN:
-		xOffset = view.getXOffset() - float64(view.getWidth()) + delta
+		xOffset = view.getXOffset() - float64(view.GetWidth()) + delta
 	case keyevent.DOM_VK_HOME:
 		xOffset = 0
 	case keyevent.DOM_VK_END:
-		xOffset = view.getYScrollRange() - float64(view.getHeight())
+		xOffset = view.getYScrollRange() - float64(view.GetHeight())
 	default:
 		view.Widget.onKeyDown(code)
 	}
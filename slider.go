@@ -0,0 +1,368 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	SLIDER_THUMB_WIDTH  = 10
+	SLIDER_THUMB_HEIGHT = 20
+	SLIDER_TRACK_SIZE   = 4
+	SLIDER_TICK_SIZE    = 4
+	SLIDER_TRACK_COLOR  = "#C8C8C8"
+	SLIDER_FILL_COLOR   = "#3399FF"
+	SLIDER_THUMB_COLOR  = "#FFFFFF"
+)
+
+// Slider picks a number between a minimum and a maximum by dragging a thumb.
+// Like the scroll bars it is horizontal when it is wider than high, and
+// vertical otherwise, with the minimum at the bottom. The changed handler
+// receives the new float64 value.
+type Slider struct {
+	*Widget
+	min          float64
+	max          float64
+	step         float64
+	value        float64
+	tickInterval float64
+	drag         barDrag
+}
+
+func NewSlider(parent *Widget, x, y, w, h float32) *Slider {
+	slider := &Slider{
+		Widget: NewWidget(TYPE_SLIDER, parent, x, y, w, h),
+		max:    100,
+		step:   1,
+	}
+	slider.I = slider
+
+	return slider
+}
+
+// SetRange sets the minimum and the maximum, clamping the value between them.
+func (slider *Slider) SetRange(min, max float64) *Slider {
+	slider.min = math.Min(min, max)
+	slider.max = math.Max(min, max)
+	slider.setValue(slider.value, false)
+
+	return slider
+}
+
+func (slider *Slider) GetMin() float64 {
+	return slider.min
+}
+
+func (slider *Slider) GetMax() float64 {
+	return slider.max
+}
+
+// SetStep makes the value a multiple of step from the minimum, 0 lets it
+// take any value. The arrow keys move it by one step.
+func (slider *Slider) SetStep(step float64) *Slider {
+	slider.step = math.Max(0, step)
+	slider.setValue(slider.value, false)
+
+	return slider
+}
+
+func (slider *Slider) GetStep() float64 {
+	return slider.step
+}
+
+// SetTickInterval draws a tick mark every interval from the minimum, 0 draws
+// none. PageUp and PageDown move the value by one interval.
+func (slider *Slider) SetTickInterval(interval float64) *Slider {
+	slider.tickInterval = math.Max(0, interval)
+	slider.PostRedraw()
+
+	return slider
+}
+
+func (slider *Slider) GetTickInterval() float64 {
+	return slider.tickInterval
+}
+
+func (slider *Slider) GetValue() float64 {
+	return slider.value
+}
+
+func (slider *Slider) SetValue(value float64, notify bool) *Slider {
+	slider.setValue(value, notify)
+
+	return slider
+}
+
+// setValue clamps value to the range, snaps it to the nearest multiple of
+// the step from the minimum that is in range and reports whether it changed.
+func (slider *Slider) setValue(value float64, notify bool) bool {
+	value = math.Max(slider.min, math.Min(slider.max, value))
+	if step := slider.step; step > 0 {
+		n := math.Round((value - slider.min) / step)
+		value = slider.roundToStep(slider.min + n*step)
+		if value > slider.max {
+			value = slider.roundToStep(slider.min + (n-1)*step)
+		}
+	}
+
+	if value == slider.value {
+		return false
+	}
+
+	slider.value = value
+	if notify && slider.onChanged != nil {
+		slider.onChanged(value)
+	}
+	slider.PostRedraw()
+
+	return true
+}
+
+func (slider *Slider) isHorizontal() bool {
+	return slider.rect.W > slider.rect.H
+}
+
+// getTrackLength returns how far the thumb travels from the minimum to the
+// maximum.
+func (slider *Slider) getTrackLength() float64 {
+	if slider.isHorizontal() {
+		return float64(slider.rect.W - SLIDER_THUMB_WIDTH)
+	}
+
+	return float64(slider.rect.H - SLIDER_THUMB_WIDTH)
+}
+
+// getCenter returns the position of the track across the slider, leaving
+// room for the ticks.
+func (slider *Slider) getCenter() float64 {
+	size := float64(slider.rect.H)
+	if !slider.isHorizontal() {
+		size = float64(slider.rect.W)
+	}
+
+	if slider.tickInterval > 0 {
+		size -= SLIDER_TICK_SIZE + 2
+	}
+
+	return math.Floor(size / 2)
+}
+
+// getOffsetOf returns the position of value along the track, from the left
+// or the bottom.
+func (slider *Slider) getOffsetOf(value float64) float64 {
+	if slider.max <= slider.min {
+		return SLIDER_THUMB_WIDTH / 2
+	}
+
+	return SLIDER_THUMB_WIDTH/2 + (value-slider.min)/(slider.max-slider.min)*slider.getTrackLength()
+}
+
+// getThumbRect returns the thumb in the coordinates of the slider.
+func (slider *Slider) getThumbRect() *structs.Rect {
+	offset := int(slider.getOffsetOf(slider.value)) - SLIDER_THUMB_WIDTH/2
+	center := int(slider.getCenter())
+
+	if slider.isHorizontal() {
+		h := int(math.Min(SLIDER_THUMB_HEIGHT, float64(slider.rect.H)))
+		return structs.NewRect(offset, center-h/2, SLIDER_THUMB_WIDTH, h)
+	}
+
+	w := int(math.Min(SLIDER_THUMB_HEIGHT, float64(slider.rect.W)))
+
+	return structs.NewRect(center-w/2, slider.rect.H-offset-SLIDER_THUMB_WIDTH, w, SLIDER_THUMB_WIDTH)
+}
+
+// getValueAt returns the value under point, in window coordinates.
+func (slider *Slider) getValueAt(point *structs.Point) float64 {
+	p := slider.translatePoint(point)
+	offset := float64(slider.rect.H - p.Y)
+	if slider.isHorizontal() {
+		offset = float64(p.X)
+	}
+
+	length := slider.getTrackLength()
+	if length <= 0 {
+		return slider.min
+	}
+
+	return slider.min + (offset-SLIDER_THUMB_WIDTH/2)/length*(slider.max-slider.min)
+}
+
+// getPageStep returns how far PageUp and PageDown move the value.
+func (slider *Slider) getPageStep() float64 {
+	if slider.tickInterval > 0 {
+		return slider.tickInterval
+	}
+
+	return math.Max(slider.step, (slider.max-slider.min)/10)
+}
+
+func (slider *Slider) getArrowStep() float64 {
+	if slider.step > 0 {
+		return slider.step
+	}
+
+	return (slider.max - slider.min) / 100
+}
+
+// onPointerDown moves the thumb under the pointer, unless it is already
+// there, and starts dragging it.
+func (slider *Slider) onPointerDown(point *structs.Point) {
+	if !slider.enable {
+		return
+	}
+
+	window := slider.GetWindow()
+	window.SetFocus(slider.Widget)
+	window.Grab(slider.Widget)
+
+	if !isPointInRect(slider.translatePoint(point), slider.getThumbRect()) {
+		slider.setValue(slider.getValueAt(point), true)
+	}
+
+	slider.drag.begin(point, slider.isHorizontal(), slider.value)
+	slider.setState(STATE_ACTIVE, false)
+
+	return
+}
+
+func (slider *Slider) onPointerMove(point *structs.Point) {
+	if slider.drag.dragging {
+		valueRange := slider.max - slider.min
+		if !slider.isHorizontal() {
+			valueRange = -valueRange
+		}
+		slider.setValue(slider.drag.valueAt(point, slider.getTrackLength(), valueRange), true)
+	}
+	slider.Widget.onPointerMove(point)
+
+	return
+}
+
+func (slider *Slider) onPointerUp(point *structs.Point) {
+	if slider.drag.dragging {
+		slider.drag.end()
+		slider.GetWindow().Ungrab()
+		slider.setState(STATE_NORMAL, false)
+	}
+
+	return
+}
+
+func (slider *Slider) onKeyDown(code int) {
+	if slider.enable {
+		switch code {
+		case keyevent.DOM_VK_LEFT, keyevent.DOM_VK_DOWN:
+			slider.setValue(slider.value-slider.getArrowStep(), true)
+		case keyevent.DOM_VK_RIGHT, keyevent.DOM_VK_UP:
+			slider.setValue(slider.value+slider.getArrowStep(), true)
+		case keyevent.DOM_VK_PAGE_DOWN:
+			slider.setValue(slider.value-slider.getPageStep(), true)
+		case keyevent.DOM_VK_PAGE_UP:
+			slider.setValue(slider.value+slider.getPageStep(), true)
+		case keyevent.DOM_VK_HOME:
+			slider.setValue(slider.min, true)
+		case keyevent.DOM_VK_END:
+			slider.setValue(slider.max, true)
+		}
+	}
+	slider.Widget.onKeyDown(code)
+
+	return
+}
+
+func (slider *Slider) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (slider *Slider) paintSelf(context canvas.Canvas2D) {
+	style := slider.getStyle("")
+	h := float64(slider.rect.H)
+	horizontal := slider.isHorizontal()
+	center := slider.getCenter()
+	start := slider.getOffsetOf(slider.min)
+	end := slider.getOffsetOf(slider.max)
+	offset := slider.getOffsetOf(slider.value)
+
+	// track draws a bar along the track from a to b, counted from the left
+	// or the bottom.
+	track := func(a, b float64) {
+		if horizontal {
+			context.FillRect(a, center-SLIDER_TRACK_SIZE/2, b-a, SLIDER_TRACK_SIZE)
+		} else {
+			context.FillRect(center-SLIDER_TRACK_SIZE/2, h-b, SLIDER_TRACK_SIZE, b-a)
+		}
+	}
+
+	context.SetFillStyle(SLIDER_TRACK_COLOR)
+	track(start, end)
+	if slider.enable {
+		context.SetFillStyle(colorOr(style.FillColor, SLIDER_FILL_COLOR))
+		track(start, offset)
+	}
+
+	if interval := slider.tickInterval; interval > 0 && slider.max > slider.min {
+		across := 2*center + 2
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(colorOr(style.TextColor, SLIDER_TRACK_COLOR))
+		context.BeginPath()
+		for value := slider.min; value <= slider.max+interval/1000; value += interval {
+			at := math.Floor(slider.getOffsetOf(math.Min(value, slider.max))) + 0.5
+			if horizontal {
+				context.MoveTo(at, across)
+				context.LineTo(at, across+SLIDER_TICK_SIZE)
+			} else {
+				context.MoveTo(across, h-at)
+				context.LineTo(across+SLIDER_TICK_SIZE, h-at)
+			}
+		}
+		context.Stroke()
+	}
+
+	r := slider.getThumbRect()
+	context.SetFillStyle(SLIDER_THUMB_COLOR)
+	context.FillRect(float64(r.X), float64(r.Y), float64(r.W), float64(r.H))
+	context.SetLineWidth(1)
+	if slider.editing || slider.drag.dragging {
+		context.SetStrokeStyle(SLIDER_FILL_COLOR)
+	} else {
+		context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	}
+	context.BeginPath()
+	context.Rect(float64(r.X)+0.5, float64(r.Y)+0.5, float64(r.W)-1, float64(r.H)-1)
+	context.Stroke()
+
+	return
+}
+
+// roundToStep drops the digits finer than the step grid, hiding the float
+// errors of the step arithmetic.
+func (slider *Slider) roundToStep(value float64) float64 {
+	scale := math.Pow(10, float64(slider.getStepDecimals()))
+
+	return math.Round(value*scale) / scale
+}
+
+// getStepDecimals returns the number of decimals of the step grid, the most
+// of the step and the minimum it starts from.
+func (slider *Slider) getStepDecimals() int {
+	if slider.step <= 0 {
+		return 0
+	}
+
+	return int(math.Max(float64(countDecimals(slider.step)), float64(countDecimals(slider.min))))
+}
+
+// countDecimals returns the number of decimals of x, at most 6.
+func countDecimals(x float64) int {
+	str := strconv.FormatFloat(x, 'f', -1, 64)
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		return int(math.Min(6, float64(len(str)-i-1)))
+	}
+
+	return 0
+}
@@ -0,0 +1,112 @@
+package gwk
+
+import (
+	"fmt"
+	"github.com/Luncher/gwk/pkg/structs"
+	"testing"
+)
+
+func TestSliderSetValue(t *testing.T) {
+	cases := []struct {
+		name     string
+		min, max float64
+		step     float64
+		value    float64
+		want     float64
+	}{
+		{"integer step", 0, 100, 1, 41.6, 42},
+		{"no step", 0, 1, 0, 0.123456789, 0.123456789},
+		{"clamped below", 0, 10, 1, -3, 0},
+		{"clamped above", 0, 10, 1, 12, 10},
+		{"fractional step", 0, 1, 0.1, 0.33, 0.3},
+		{"fractional step float error", 0, 1, 0.1, 0.7, 0.7},
+		{"fractional step half way", 0, 1, 0.25, 0.38, 0.5},
+		{"non-zero min", 3, 20, 5, 9, 8},
+		{"non-zero min at max", 3, 20, 5, 20, 18},
+		{"negative min", -1, 1, 0.5, -0.3, -0.5},
+		{"min off the step decimals", 0.05, 1, 0.1, 0.16, 0.15},
+		{"min off the step decimals at max", 0.05, 1, 0.1, 1, 0.95},
+		{"fractional min and step", 0.5, 2, 0.25, 1.3, 1.25},
+		{"step wider than range", 2, 3, 5, 2.9, 2},
+	}
+	for _, c := range cases {
+		slider := NewSlider(nil, 0, 0, 100, 20).SetRange(c.min, c.max).SetStep(c.step)
+		slider.SetValue(c.value, false)
+		if value := slider.GetValue(); value != c.want {
+			t.Errorf("%s: SetValue(%v) gave %v, want %v", c.name, c.value, value, c.want)
+		}
+	}
+}
+
+func TestSliderChangedHandler(t *testing.T) {
+	slider := NewSlider(nil, 0, 0, 100, 20).SetRange(0, 1).SetStep(0.1)
+	var values []float64
+	slider.SetChangedHandler(func(value interface{}) {
+		values = append(values, value.(float64))
+	})
+
+	slider.SetValue(0.31, true)
+	slider.SetValue(0.29, true)
+	slider.SetValue(0.5, false)
+	if len(values) != 1 || values[0] != 0.3 {
+		t.Errorf("notified %v, want one change to 0.3", values)
+	}
+}
+
+// TestThumbDragReleasesGrab drags the thumb of a Slider and of a ScrollBar
+// and checks that clicks reach another window afterwards.
+func TestThumbDragReleasesGrab(t *testing.T) {
+	var slider *Slider
+	var view *ListView
+	var button *Button
+	source := make(StringListSource, 100)
+	for i := range source {
+		source[i] = fmt.Sprintf("row %d", i)
+	}
+	k := newScene(400, 240, func(win *Window) {
+		slider = NewSlider(win.Widget, 10, 10, 200, 20)
+		view = NewListView(win.Widget, 10, 40, 200, 190).SetDataSource(source)
+		other := NewWindow(win.manager, 250, 0, 150, 240)
+		button = NewButton(other.Widget, 10, 10, 100, 30)
+	})
+	clicks := 0
+	button.SetClickedHandler(func(*Widget, *structs.Point) {
+		clicks++
+	})
+
+	thumbs := []struct {
+		name  string
+		drag  func()
+		moved func() bool
+	}{
+		{"slider", func() {
+			rect := slider.getThumbRect()
+			x, y := 10+rect.X+rect.W/2, 10+rect.Y+rect.H/2
+			k.drag(x, y, x+100, y)
+		}, func() bool {
+			return slider.GetValue() > 0
+		}},
+		{"scroll bar", func() {
+			bar := view.vScrollBar
+			x, y := 10+bar.rect.X+bar.rect.W/2, 40+bar.draggerRect.Y+bar.draggerRect.H/2
+			k.drag(x, y, x, y+50)
+		}, func() bool {
+			return view.GetScrollPositionV() > 0
+		}},
+	}
+	for _, thumb := range thumbs {
+		thumb.drag()
+		if !thumb.moved() {
+			t.Fatalf("%s: dragging the thumb did not move it", thumb.name)
+		}
+		if len(k.m.grabWindows) != 0 {
+			t.Fatalf("%s: %d windows still grab the input after the drag", thumb.name, len(k.m.grabWindows))
+		}
+
+		n := clicks
+		k.click(300, 25)
+		if clicks != n+1 {
+			t.Fatalf("%s: the click after the drag did not reach the other window", thumb.name)
+		}
+	}
+}
@@ -0,0 +1,702 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+)
+
+const (
+	TAB_CONTROL_BUTTON_HEIGHT = 28
+	TAB_CONTROL_DRAG_DISTANCE = 5
+	TAB_BUTTON_PADDING        = 12
+	TAB_BUTTON_MIN_WIDTH      = 60
+	TAB_BUTTON_MAX_WIDTH      = 200
+	TAB_BUTTON_CLOSE_SIZE     = 14
+	TAB_BUTTON_SCROLL_WIDTH   = 20
+	TAB_BUTTON_COLOR          = "#E4E4E4"
+	TAB_BUTTON_CURRENT_COLOR  = "#FFFFFF"
+	TAB_BUTTON_HOVER_COLOR    = "#C8C8C8"
+)
+
+type TabChangedHandler func(index int)
+
+// TabCloseHandler is called when the user closes tab, and keeps it open by
+// returning false.
+type TabCloseHandler func(tab *TabButton) bool
+
+// TabButton is the button of a tab in the strip of a TabControl. It owns the
+// page shown below the strip while the tab is current.
+type TabButton struct {
+	*Widget
+	control  *TabControl
+	page     *Widget
+	closable bool
+}
+
+// GetPage returns the widget the content of the tab should be added to.
+func (tab *TabButton) GetPage() *Widget {
+	return tab.page
+}
+
+func (tab *TabButton) GetTabControl() *TabControl {
+	return tab.control
+}
+
+// GetIndex returns the position of the tab in the strip, or -1 once removed.
+func (tab *TabButton) GetIndex() int {
+	return tab.control.indexOf(tab)
+}
+
+func (tab *TabButton) GetTitle() string {
+	return tab.GetText()
+}
+
+func (tab *TabButton) SetTitle(title string) *TabButton {
+	tab.SetText(title, false)
+	tab.control.group.layout()
+
+	return tab
+}
+
+// SetClosable shows a close button on the tab.
+func (tab *TabButton) SetClosable(closable bool) *TabButton {
+	tab.closable = closable
+	tab.control.group.layout()
+
+	return tab
+}
+
+func (tab *TabButton) IsClosable() bool {
+	return tab.closable
+}
+
+func (tab *TabButton) isCurrent() bool {
+	return tab.control.GetCurrentTab() == tab
+}
+
+// getCloseRect returns the close button in the coordinates of the tab.
+func (tab *TabButton) getCloseRect() *structs.Rect {
+	x := tab.rect.W - TAB_BUTTON_PADDING/2 - TAB_BUTTON_CLOSE_SIZE
+	y := (tab.rect.H - TAB_BUTTON_CLOSE_SIZE) / 2
+
+	return structs.NewRect(x, y, TAB_BUTTON_CLOSE_SIZE, TAB_BUTTON_CLOSE_SIZE)
+}
+
+func (tab *TabButton) paintBackground(context canvas.Canvas2D) {
+	style := tab.getStyle("")
+	w := float64(tab.rect.W)
+	h := float64(tab.rect.H)
+
+	if tab.isCurrent() {
+		context.SetFillStyle(TAB_BUTTON_CURRENT_COLOR)
+	} else {
+		context.SetFillStyle(TAB_BUTTON_COLOR)
+	}
+
+	context.BeginPath()
+	context.MoveTo(0.5, h)
+	context.LineTo(0.5, 3.5)
+	context.LineTo(3.5, 0.5)
+	context.LineTo(w-3.5, 0.5)
+	context.LineTo(w-0.5, 3.5)
+	context.LineTo(w-0.5, h)
+	context.Fill()
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.Stroke()
+
+	return
+}
+
+func (tab *TabButton) paintSelf(context canvas.Canvas2D) {
+	style := tab.getStyle("")
+	w := float64(tab.rect.W)
+	h := float64(tab.rect.H)
+
+	right := w - TAB_BUTTON_PADDING
+	if tab.closable {
+		r := tab.getCloseRect()
+		right = float64(r.X) - 4
+		group := tab.control.group
+		if group.hoverClose == tab {
+			context.SetFillStyle(TAB_BUTTON_HOVER_COLOR)
+			context.FillRect(float64(r.X), float64(r.Y), float64(r.W), float64(r.H))
+		}
+
+		d := 4.0
+		cx := float64(r.X) + float64(r.W)/2
+		cy := float64(r.Y) + float64(r.H)/2
+		context.SetLineWidth(2)
+		context.SetStrokeStyle(style.TextColor)
+		context.BeginPath()
+		context.MoveTo(cx-d, cy-d)
+		context.LineTo(cx+d, cy+d)
+		context.MoveTo(cx+d, cy-d)
+		context.LineTo(cx-d, cy+d)
+		context.Stroke()
+	}
+
+	context.SetFont(style.Font)
+	context.SetFillStyle(style.TextColor)
+	context.SetTextAlign("left")
+	context.SetTextBaseline("middle")
+	context.FillText(tab.GetText(), TAB_BUTTON_PADDING, h/2, right-TAB_BUTTON_PADDING)
+
+	return
+}
+
+// TabButtonGroup is the strip of tab buttons of a TabControl. When the tabs
+// do not fit, arrows at its right end scroll them, as does the wheel.
+type TabButtonGroup struct {
+	*Widget
+	control    *TabControl
+	scrollX    int
+	overflow   bool
+	pressed    *TabButton
+	closing    *TabButton
+	hoverClose *TabButton
+	dragging   bool
+	dragStart  structs.Point
+}
+
+func (group *TabButtonGroup) getStripWidth() int {
+	if group.overflow {
+		return group.rect.W - 2*TAB_BUTTON_SCROLL_WIDTH
+	}
+
+	return group.rect.W
+}
+
+func (group *TabButtonGroup) getTotalWidth() int {
+	width := 0
+	for _, tab := range group.control.tabs {
+		width += tab.rect.W
+	}
+
+	return width
+}
+
+// layout sizes the tabs to their titles and places them along the strip,
+// scrolled by scrollX.
+func (group *TabButtonGroup) layout() {
+	context := group.getCanvas2D()
+	context.Save()
+	context.SetFont(group.getStyle("").Font)
+	for _, tab := range group.control.tabs {
+		w := context.MeasureText(tab.GetText()).Width + 2*TAB_BUTTON_PADDING
+		if tab.closable {
+			w += TAB_BUTTON_CLOSE_SIZE + 4
+		}
+		w = math.Max(TAB_BUTTON_MIN_WIDTH, math.Min(TAB_BUTTON_MAX_WIDTH, math.Ceil(w)))
+		tab.Resize(int(w), TAB_CONTROL_BUTTON_HEIGHT)
+	}
+	context.Restore()
+
+	group.overflow = group.getTotalWidth() > group.rect.W
+	group.scrollTo(group.scrollX)
+
+	return
+}
+
+func (group *TabButtonGroup) scrollTo(scrollX int) {
+	maxX := int(math.Max(0, float64(group.getTotalWidth()-group.getStripWidth())))
+	group.scrollX = int(math.Max(0, math.Min(float64(maxX), float64(scrollX))))
+
+	x := -group.scrollX
+	for _, tab := range group.control.tabs {
+		tab.Move(x, 0)
+		x += tab.rect.W
+	}
+	group.PostRedraw()
+
+	return
+}
+
+// ensureVisible scrolls the least needed to show tab.
+func (group *TabButtonGroup) ensureVisible(tab *TabButton) {
+	left := tab.rect.X + group.scrollX
+	right := left + tab.rect.W
+	width := group.getStripWidth()
+
+	if left < group.scrollX {
+		group.scrollTo(left)
+	} else if right > group.scrollX+width {
+		group.scrollTo(right - width)
+	}
+
+	return
+}
+
+func (group *TabButtonGroup) tabAt(p *structs.Point) *TabButton {
+	if p.X < 0 || p.X >= group.getStripWidth() {
+		return nil
+	}
+
+	for _, tab := range group.control.tabs {
+		if isPointInRect(p, tab.rect) {
+			return tab
+		}
+	}
+
+	return nil
+}
+
+func (group *TabButtonGroup) isInClose(tab *TabButton, p *structs.Point) bool {
+	if tab == nil || !tab.closable {
+		return false
+	}
+
+	return isPointInRect(&structs.Point{X: p.X - tab.rect.X, Y: p.Y - tab.rect.Y}, tab.getCloseRect())
+}
+
+func (group *TabButtonGroup) paintBackground(context canvas.Canvas2D) {
+	style := group.getStyle("")
+	w := float64(group.rect.W)
+	h := float64(group.rect.H)
+
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.MoveTo(0, h-0.5)
+	context.LineTo(w, h-0.5)
+	context.Stroke()
+
+	return
+}
+
+// paintChildren clips the tabs to the strip, paints the current one last so
+// that it covers the bottom line, and paints the scroll arrows.
+func (group *TabButtonGroup) paintChildren(context canvas.Canvas2D) {
+	control := group.control
+	current := control.GetCurrentTab()
+
+	context.Save()
+	context.BeginPath()
+	context.Rect(0, 0, float64(group.getStripWidth()), float64(group.rect.H))
+	context.Clip()
+	for _, tab := range control.tabs {
+		if tab != current {
+			tab.draw(context)
+		}
+	}
+	if current != nil {
+		current.draw(context)
+		context.SetStrokeStyle(TAB_BUTTON_CURRENT_COLOR)
+		context.SetLineWidth(1)
+		context.BeginPath()
+		context.MoveTo(float64(current.rect.X)+1, float64(group.rect.H)-0.5)
+		context.LineTo(float64(current.rect.X+current.rect.W)-1, float64(group.rect.H)-0.5)
+		context.Stroke()
+	}
+	context.Restore()
+
+	if group.overflow {
+		style := group.getStyle("")
+		x := float64(group.getStripWidth())
+		y := float64(group.rect.H) / 2
+		maxX := group.getTotalWidth() - group.getStripWidth()
+		for i, enabled := range []bool{group.scrollX > 0, group.scrollX < maxX} {
+			cx := x + float64(i*TAB_BUTTON_SCROLL_WIDTH+TAB_BUTTON_SCROLL_WIDTH/2)
+			d := 6.0
+			if i == 0 {
+				d = -d
+			}
+
+			context.SetFillStyle(style.TextColor)
+			if !enabled {
+				context.SetFillStyle(MENU_LINE_COLOR)
+			}
+			context.BeginPath()
+			context.MoveTo(cx-d/2, y-5)
+			context.LineTo(cx+d/2, y)
+			context.LineTo(cx-d/2, y+5)
+			context.ClosePath()
+			context.Fill()
+		}
+	}
+
+	return
+}
+
+func (group *TabButtonGroup) onPointerDown(point *structs.Point) {
+	control := group.control
+	if !control.enable {
+		return
+	}
+
+	if window := control.GetWindow(); window != nil {
+		window.SetFocus(control.Widget)
+	}
+
+	p := group.translatePoint(point)
+	if group.overflow && p.X >= group.getStripWidth() {
+		if p.X < group.getStripWidth()+TAB_BUTTON_SCROLL_WIDTH {
+			group.scrollTo(group.scrollX - group.getStripWidth()/2)
+		} else {
+			group.scrollTo(group.scrollX + group.getStripWidth()/2)
+		}
+		return
+	}
+
+	tab := group.tabAt(p)
+	if tab == nil {
+		return
+	}
+
+	if group.isInClose(tab, p) {
+		group.closing = tab
+		return
+	}
+
+	group.pressed = tab
+	group.dragging = false
+	group.dragStart = *p
+	control.SetCurrent(tab.GetIndex())
+
+	return
+}
+
+func (group *TabButtonGroup) onPointerMove(point *structs.Point) {
+	control := group.control
+	p := group.translatePoint(point)
+
+	// The release went elsewhere when the button is up.
+	if (group.pressed != nil || group.closing != nil) && !group.isPointerDown() {
+		group.endDrag()
+	}
+
+	if group.pressed != nil {
+		if !group.dragging && math.Abs(float64(p.X-group.dragStart.X)) > TAB_CONTROL_DRAG_DISTANCE {
+			group.dragging = true
+		}
+
+		if group.dragging {
+			index := 0
+			for _, tab := range control.tabs {
+				if tab != group.pressed && p.X > tab.rect.X+tab.rect.W/2 {
+					index++
+				}
+			}
+			control.MoveTab(group.pressed, index)
+		}
+		return
+	}
+
+	hoverClose := group.tabAt(p)
+	if !group.isInClose(hoverClose, p) {
+		hoverClose = nil
+	}
+
+	if hoverClose != group.hoverClose {
+		group.hoverClose = hoverClose
+		group.PostRedraw()
+	}
+
+	return
+}
+
+func (group *TabButtonGroup) onPointerUp(point *structs.Point) {
+	p := group.translatePoint(point)
+	if closing := group.closing; closing != nil && group.isInClose(group.tabAt(p), p) && group.tabAt(p) == closing {
+		group.control.CloseTab(closing)
+	}
+	group.endDrag()
+
+	return
+}
+
+// endDrag forgets the tab being pressed, dragged or closed.
+func (group *TabButtonGroup) endDrag() {
+	group.closing = nil
+	group.pressed = nil
+	group.dragging = false
+
+	return
+}
+
+func (group *TabButtonGroup) onWheel(delta float64) bool {
+	if !group.overflow {
+		return false
+	}
+	group.scrollTo(group.scrollX + int(delta))
+
+	return true
+}
+
+// TabControl shows one of several pages, chosen with the tab buttons of the
+// strip above them. Tabs can be reordered by dragging their buttons, and
+// Ctrl+Tab and Ctrl+Shift+Tab switch to the next and previous tab from
+// anywhere inside the control.
+type TabControl struct {
+	*Widget
+	group        *TabButtonGroup
+	tabs         []*TabButton
+	current      int
+	onTabChanged TabChangedHandler
+	onTabClose   TabCloseHandler
+}
+
+func NewTabControl(parent *Widget, x, y, w, h float32) *TabControl {
+	control := &TabControl{
+		Widget:  NewWidget(TYPE_TAB_CONTROL, parent, x, y, w, h),
+		current: -1,
+	}
+	control.I = control
+
+	control.group = &TabButtonGroup{
+		Widget:  NewWidget(TYPE_TAB_BUTTON_GROUP, control.Widget, 0, 0, float32(control.rect.W), TAB_CONTROL_BUTTON_HEIGHT),
+		control: control,
+	}
+	control.group.I = control.group
+
+	return control
+}
+
+// AddTab appends a tab and returns its button. The first tab added becomes
+// the current one.
+func (control *TabControl) AddTab(title string) *TabButton {
+	rect := control.rect
+	tab := &TabButton{
+		Widget:  NewWidget(TYPE_TAB_BUTTON, control.group.Widget, 0, 0, 0, 0),
+		control: control,
+		page:    NewWidget(TYPE_VIEW_BASE, control.Widget, 0, TAB_CONTROL_BUTTON_HEIGHT, float32(rect.W), float32(rect.H-TAB_CONTROL_BUTTON_HEIGHT)),
+	}
+	tab.SetText(title, false)
+	tab.I = tab
+	tab.page.SetVisible(false)
+
+	control.tabs = append(control.tabs, tab)
+	control.group.layout()
+	if control.current < 0 {
+		control.SetCurrent(0)
+	}
+
+	return tab
+}
+
+// RemoveTab removes tab and its page. When tab was the current one, the tab
+// that takes its place in the strip becomes current.
+func (control *TabControl) RemoveTab(tab *TabButton) *TabControl {
+	index := control.indexOf(tab)
+	if index < 0 {
+		return control
+	}
+
+	control.tabs = append(control.tabs[:index], control.tabs[index+1:]...)
+	control.takeFocusFrom(tab.page)
+	tab.Remove()
+	tab.page.Remove()
+	group := control.group
+	if group.pressed == tab {
+		group.pressed = nil
+	}
+	if group.closing == tab {
+		group.closing = nil
+	}
+	if group.hoverClose == tab {
+		group.hoverClose = nil
+	}
+	group.layout()
+
+	switch {
+	case index < control.current:
+		control.current--
+	case index == control.current:
+		control.current = -1
+		control.SetCurrent(int(math.Min(float64(index), float64(len(control.tabs)-1))))
+		if len(control.tabs) == 0 && control.onTabChanged != nil {
+			control.onTabChanged(-1)
+		}
+	}
+	control.PostRedraw()
+
+	return control
+}
+
+// CloseTab removes tab unless the close handler keeps it open.
+func (control *TabControl) CloseTab(tab *TabButton) *TabControl {
+	if control.onTabClose == nil || control.onTabClose(tab) {
+		control.RemoveTab(tab)
+	}
+
+	return control
+}
+
+// MoveTab moves tab to position index of the strip.
+func (control *TabControl) MoveTab(tab *TabButton, index int) *TabControl {
+	from := control.indexOf(tab)
+	if from < 0 {
+		return control
+	}
+
+	index = int(math.Max(0, math.Min(float64(len(control.tabs)-1), float64(index))))
+	if index == from {
+		return control
+	}
+
+	current := control.GetCurrentTab()
+	tabs := append(control.tabs[:from:from], control.tabs[from+1:]...)
+	control.tabs = append(tabs[:index], append([]*TabButton{tab}, tabs[index:]...)...)
+	control.current = control.indexOf(current)
+	control.group.scrollTo(control.group.scrollX)
+
+	return control
+}
+
+func (control *TabControl) GetTabs() []*TabButton {
+	return append([]*TabButton(nil), control.tabs...)
+}
+
+func (control *TabControl) GetTabCount() int {
+	return len(control.tabs)
+}
+
+func (control *TabControl) GetTab(index int) *TabButton {
+	if index < 0 || index >= len(control.tabs) {
+		return nil
+	}
+
+	return control.tabs[index]
+}
+
+// GetCurrent returns the index of the current tab, or -1 without tabs.
+func (control *TabControl) GetCurrent() int {
+	return control.current
+}
+
+func (control *TabControl) GetCurrentTab() *TabButton {
+	return control.GetTab(control.current)
+}
+
+// SetCurrent shows the page of tab index and calls the tab changed handler.
+func (control *TabControl) SetCurrent(index int) *TabControl {
+	if index < 0 || index >= len(control.tabs) || index == control.current {
+		return control
+	}
+
+	if old := control.GetCurrentTab(); old != nil {
+		old.page.SetVisible(false)
+		control.takeFocusFrom(old.page)
+	}
+
+	control.current = index
+	tab := control.tabs[index]
+	tab.page.SetVisible(true)
+	control.group.ensureVisible(tab)
+	control.PostRedraw()
+
+	if control.onTabChanged != nil {
+		control.onTabChanged(index)
+	}
+
+	return control
+}
+
+// SetTabChangedHandler sets the handler called with the index of the new
+// current tab, or -1 when the last tab is removed.
+func (control *TabControl) SetTabChangedHandler(onTabChanged TabChangedHandler) *TabControl {
+	control.onTabChanged = onTabChanged
+
+	return control
+}
+
+func (control *TabControl) SetTabCloseHandler(onTabClose TabCloseHandler) *TabControl {
+	control.onTabClose = onTabClose
+
+	return control
+}
+
+// Resize resizes the control and keeps the strip and pages fitted to it.
+func (control *TabControl) Resize(w, h int) *TabControl {
+	control.Widget.Resize(w, h)
+	control.group.Resize(w, TAB_CONTROL_BUTTON_HEIGHT)
+	for _, tab := range control.tabs {
+		tab.page.Resize(w, h-TAB_CONTROL_BUTTON_HEIGHT)
+	}
+	control.group.layout()
+
+	return control
+}
+
+func (control *TabControl) indexOf(tab *TabButton) int {
+	for i, iter := range control.tabs {
+		if iter == tab {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// takeFocusFrom moves the focus to the control when it is inside page, so
+// that keys do not go to a hidden widget.
+func (control *TabControl) takeFocusFrom(page *Widget) {
+	window := control.GetWindow()
+	if window == nil {
+		return
+	}
+
+	for w := window.GetFocus(); w != nil; w = w.parent {
+		if w == page {
+			window.SetFocus(control.Widget)
+			break
+		}
+	}
+
+	return
+}
+
+// switchTab makes the tab delta positions away current, wrapping around.
+func (control *TabControl) switchTab(delta int) {
+	n := len(control.tabs)
+	if n > 0 {
+		control.SetCurrent(((control.current+delta)%n + n) % n)
+	}
+
+	return
+}
+
+func (control *TabControl) onShortcut(code int) bool {
+	if !control.enable || !control.isCtrlDown() {
+		return false
+	}
+
+	switch code {
+	case keyevent.DOM_VK_TAB:
+		if control.isShiftDown() {
+			control.switchTab(-1)
+		} else {
+			control.switchTab(1)
+		}
+	case keyevent.DOM_VK_PAGE_UP:
+		control.switchTab(-1)
+	case keyevent.DOM_VK_PAGE_DOWN:
+		control.switchTab(1)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// onKeyDown moves between the tabs with the arrows while the strip has the
+// focus.
+func (control *TabControl) onKeyDown(code int) {
+	if control.enable {
+		switch code {
+		case keyevent.DOM_VK_LEFT:
+			control.SetCurrent(control.current - 1)
+		case keyevent.DOM_VK_RIGHT:
+			control.SetCurrent(control.current + 1)
+		case keyevent.DOM_VK_HOME:
+			control.SetCurrent(0)
+		case keyevent.DOM_VK_END:
+			control.SetCurrent(len(control.tabs) - 1)
+		}
+	}
+	control.Widget.onKeyDown(code)
+
+	return
+}
@@ -0,0 +1,221 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/event"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"reflect"
+	"testing"
+)
+
+// newTabControlScene returns a TabControl 400x150 with the closable tabs
+// "one", "two" and "three", beside a window covering 450,0 to 600,200.
+func newTabControlScene() (typist, *TabControl) {
+	var control *TabControl
+	k := newScene(600, 200, func(win *Window) {
+		control = NewTabControl(win.Widget, 0, 0, 400, 150)
+		for _, title := range []string{"one", "two", "three"} {
+			control.AddTab(title).SetClosable(true)
+		}
+		NewWindow(win.manager, 450, 0, 150, 200)
+	})
+
+	return k, control
+}
+
+// getTabCenter returns the center of the title of tab, left of its close
+// button.
+func getTabCenter(tab *TabButton) (x, y int) {
+	return tab.rect.X + TAB_BUTTON_PADDING + 4, TAB_CONTROL_BUTTON_HEIGHT / 2
+}
+
+// getTabClose returns the center of the close button of tab.
+func getTabClose(tab *TabButton) (x, y int) {
+	rect := tab.getCloseRect()
+
+	return tab.rect.X + rect.X + rect.W/2, rect.Y + rect.H/2
+}
+
+// getTabTitles returns the titles of the tabs in the order shown.
+func getTabTitles(control *TabControl) []string {
+	titles := []string{}
+	for _, tab := range control.GetTabs() {
+		titles = append(titles, tab.GetTitle())
+	}
+
+	return titles
+}
+
+func TestTabControlReorder(t *testing.T) {
+	k, control := newTabControlScene()
+	one := control.GetTab(0)
+	x, y := getTabCenter(one)
+	last := control.GetTab(2)
+
+	k.drag(x, y, last.rect.X+last.rect.W-4, y)
+	if titles := getTabTitles(control); !reflect.DeepEqual(titles, []string{"two", "three", "one"}) {
+		t.Fatalf("tabs %v after dragging one to the end", titles)
+	}
+	if control.GetCurrentTab() != one || control.GetCurrent() != 2 {
+		t.Fatalf("current tab %d after the drag, want the dragged tab", control.GetCurrent())
+	}
+
+	x, y = getTabCenter(one)
+	k.drag(x, y, x+TAB_CONTROL_DRAG_DISTANCE, y)
+	if titles := getTabTitles(control); !reflect.DeepEqual(titles, []string{"two", "three", "one"}) {
+		t.Fatalf("tabs %v after a short drag", titles)
+	}
+
+	x, y = getTabCenter(one)
+	k.drag(x, y, 2, y)
+	if titles := getTabTitles(control); !reflect.DeepEqual(titles, []string{"one", "two", "three"}) {
+		t.Fatalf("tabs %v after dragging one to the front", titles)
+	}
+}
+
+func TestTabControlClose(t *testing.T) {
+	k, control := newTabControlScene()
+	keep := true
+	control.SetTabCloseHandler(func(tab *TabButton) bool {
+		return !keep
+	})
+	changed := []int{}
+	control.SetTabChangedHandler(func(index int) {
+		changed = append(changed, index)
+
+		return
+	})
+
+	k.click(getTabClose(control.GetTab(1)))
+	if control.GetTabCount() != 3 {
+		t.Fatalf("the close handler did not keep the tab open")
+	}
+
+	keep = false
+	x, y := getTabClose(control.GetTab(1))
+	k.drag(x, y, x, y+TAB_CONTROL_BUTTON_HEIGHT)
+	if control.GetTabCount() != 3 {
+		t.Fatalf("releasing off the close button closed the tab")
+	}
+
+	k.click(getTabClose(control.GetTab(1)))
+	if titles := getTabTitles(control); !reflect.DeepEqual(titles, []string{"one", "three"}) {
+		t.Fatalf("tabs %v after closing two", titles)
+	}
+	if control.GetCurrent() != 0 || len(changed) != 0 {
+		t.Fatalf("closing another tab changed the current tab to %d", control.GetCurrent())
+	}
+
+	k.click(getTabClose(control.GetTab(0)))
+	if control.GetCurrentTab() == nil || control.GetCurrentTab().GetTitle() != "three" {
+		t.Fatalf("the tab taking the place of the current one did not become current")
+	}
+	if !reflect.DeepEqual(changed, []int{0}) {
+		t.Fatalf("tab changed handler called with %v, want [0]", changed)
+	}
+
+	k.click(getTabClose(control.GetTab(0)))
+	if control.GetTabCount() != 0 || control.GetCurrent() != -1 || !reflect.DeepEqual(changed, []int{0, -1}) {
+		t.Fatalf("closing the last tab: %d tabs, current %d, changes %v", control.GetTabCount(), control.GetCurrent(), changed)
+	}
+}
+
+func TestTabControlDragReleasedOutside(t *testing.T) {
+	for _, release := range []struct {
+		name string
+		x, y int
+	}{
+		{"over another window", 500, 100},
+		{"outside the canvas", 700, 100},
+	} {
+		k, control := newTabControlScene()
+		group := control.group
+		x, y := getTabCenter(control.GetTab(0))
+
+		k.pointer(event.EVENT_POINTER_DOWN, x, y)
+		k.pointer(event.EVENT_POINTER_MOVE, x+20, y)
+		k.pointer(event.EVENT_POINTER_MOVE, release.x, release.y)
+		k.pointer(event.EVENT_POINTER_UP, release.x, release.y)
+		titles := getTabTitles(control)
+		k.pointer(event.EVENT_POINTER_MOVE, 2, y)
+		if got := getTabTitles(control); !reflect.DeepEqual(got, titles) {
+			t.Fatalf("%s: hovering after the release moved the tabs to %v", release.name, got)
+		}
+
+		x, y = getTabClose(control.GetTab(1))
+		k.pointer(event.EVENT_POINTER_DOWN, x, y)
+		k.pointer(event.EVENT_POINTER_MOVE, release.x, release.y)
+		k.pointer(event.EVENT_POINTER_UP, release.x, release.y)
+		k.pointer(event.EVENT_POINTER_MOVE, x, y)
+		if group.pressed != nil || group.closing != nil || group.dragging {
+			t.Fatalf("%s: the strip still tracks a tab after the release", release.name)
+		}
+	}
+}
+
+func TestTabControlRemovePressedTab(t *testing.T) {
+	k, control := newTabControlScene()
+	two := control.GetTab(1)
+
+	x, y := getTabClose(two)
+	k.pointer(event.EVENT_POINTER_DOWN, x, y)
+	control.RemoveTab(two)
+	if control.group.closing != nil {
+		t.Fatalf("removing the tab being closed left it in the strip")
+	}
+
+	x, y = getTabCenter(control.GetTab(0))
+	k.pointer(event.EVENT_POINTER_DOWN, x, y)
+	control.RemoveTab(control.GetTab(0))
+	if control.group.pressed != nil {
+		t.Fatalf("removing the tab being pressed left it in the strip")
+	}
+}
+
+func TestTabControlSwitchTab(t *testing.T) {
+	k, control := newTabControlScene()
+	edit := NewEdit(control.GetTab(0).GetPage(), 10, 10, 100, 24)
+	k.click(50, TAB_CONTROL_BUTTON_HEIGHT+20)
+	if control.GetWindow().GetFocus() != edit.Widget {
+		t.Fatalf("clicking the edit did not focus it")
+	}
+
+	steps := []struct {
+		name    string
+		press   func()
+		current int
+	}{
+		{"Ctrl+Tab", func() {
+			k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_TAB)
+		}, 1},
+		{"Ctrl+Page Down", func() {
+			k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_PAGE_DOWN)
+		}, 2},
+		{"Ctrl+Tab on the last tab", func() {
+			k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_TAB)
+		}, 0},
+		{"Ctrl+Shift+Tab on the first tab", func() {
+			k.hold(keyevent.DOM_VK_CONTROL, func() {
+				k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_TAB)
+			})
+		}, 2},
+		{"Ctrl+Page Up", func() {
+			k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_PAGE_UP)
+		}, 1},
+	}
+	for _, step := range steps {
+		step.press()
+		if control.GetCurrent() != step.current {
+			t.Fatalf("%s: current tab %d, want %d", step.name, control.GetCurrent(), step.current)
+		}
+	}
+
+	// Leaving the page of the edit moved the focus out of the hidden page.
+	if window := control.GetWindow(); window.GetFocus() != control.Widget {
+		t.Fatalf("the focus stayed in a hidden page")
+	}
+
+	k.key(keyevent.DOM_VK_TAB)
+	if control.GetCurrent() != 1 {
+		t.Fatalf("Tab without Ctrl switched to tab %d", control.GetCurrent())
+	}
+}
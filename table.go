@@ -0,0 +1,1290 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+	"sort"
+	"strconv"
+)
+
+type TableSortOrder int
+
+const (
+	TABLE_SORT_NONE TableSortOrder = iota
+	TABLE_SORT_ASCENDING
+	TABLE_SORT_DESCENDING
+)
+
+const (
+	TABLE_ROW_HEIGHT       = 24
+	TABLE_HEADER_HEIGHT    = 26
+	TABLE_COLUMN_WIDTH     = 100
+	TABLE_COLUMN_MIN_WIDTH = 24
+	TABLE_CELL_PADDING     = 6
+	TABLE_RESIZE_MARGIN    = 4
+	TABLE_DRAG_DISTANCE    = 5
+	TABLE_SCROLL_STEP      = 20
+	TABLE_GRID_COLOR       = "#E0E0E0"
+	TABLE_HEADER_COLOR     = "#F0F0F0"
+	TABLE_CELL_COLOR       = "#FFFFFF"
+	TABLE_DROP_COLOR       = "#3399FF"
+)
+
+// TableDataSource supplies the cells of a Table. Rows and columns are those
+// of the source: the row before sorting, and the column index returned by
+// TableColumn.GetIndex whatever position the user moved the column to.
+type TableDataSource interface {
+	GetRowCount() int
+	GetCellText(row, column int) string
+}
+
+// TableEditableSource is implemented by sources whose cells can be edited in
+// place. SetCellText receives the text entered by the user.
+type TableEditableSource interface {
+	TableDataSource
+	SetCellText(row, column int, text string)
+}
+
+// StringTableSource is a TableEditableSource holding one []string per row.
+type StringTableSource [][]string
+
+func (source StringTableSource) GetRowCount() int {
+	return len(source)
+}
+
+func (source StringTableSource) GetCellText(row, column int) string {
+	if column < len(source[row]) {
+		return source[row][column]
+	}
+
+	return ""
+}
+
+func (source StringTableSource) SetCellText(row, column int, text string) {
+	for len(source[row]) <= column {
+		source[row] = append(source[row], "")
+	}
+	source[row][column] = text
+
+	return
+}
+
+// TableCellRenderer customizes cell after the table set its text and
+// alignment. row is the row of the data source. Cells are recycled as the
+// table scrolls, so a renderer changing the look of some cells should set it
+// on every cell.
+type TableCellRenderer func(cell *TableCell, row int)
+
+// TableLessFunc reports whether source row a sorts before source row b.
+type TableLessFunc func(a, b int) bool
+
+// TableColumn describes a column of a Table. Its setters return the column,
+// so that a column is defined by a chain of calls on Table.AddColumn.
+type TableColumn struct {
+	table    *Table
+	header   *tableHeader
+	index    int
+	width    int
+	align    string
+	sortable bool
+	editable bool
+	renderer TableCellRenderer
+	less     TableLessFunc
+}
+
+// GetIndex returns the column of the data source shown by column.
+func (column *TableColumn) GetIndex() int {
+	return column.index
+}
+
+func (column *TableColumn) GetTable() *Table {
+	return column.table
+}
+
+func (column *TableColumn) GetTitle() string {
+	return column.header.GetText()
+}
+
+func (column *TableColumn) SetTitle(title string) *TableColumn {
+	column.header.SetText(title, false)
+	column.table.PostRedraw()
+
+	return column
+}
+
+func (column *TableColumn) GetWidth() int {
+	return column.width
+}
+
+func (column *TableColumn) SetWidth(width int) *TableColumn {
+	column.width = int(math.Max(TABLE_COLUMN_MIN_WIDTH, float64(width)))
+	column.table.updateLayout()
+	column.table.PostRedraw()
+
+	return column
+}
+
+func (column *TableColumn) GetAlign() string {
+	return column.align
+}
+
+// SetAlign sets the horizontal alignment of the cells: "left", "center" or
+// "right".
+func (column *TableColumn) SetAlign(align string) *TableColumn {
+	column.align = align
+	column.table.Reload()
+
+	return column
+}
+
+// SetSortable controls whether clicking the header sorts the rows.
+func (column *TableColumn) SetSortable(sortable bool) *TableColumn {
+	column.sortable = sortable
+
+	return column
+}
+
+func (column *TableColumn) IsSortable() bool {
+	return column.sortable
+}
+
+// SetEditable allows editing the cells of the column in place, provided the
+// data source is a TableEditableSource.
+func (column *TableColumn) SetEditable(editable bool) *TableColumn {
+	column.editable = editable
+
+	return column
+}
+
+func (column *TableColumn) IsEditable() bool {
+	return column.editable
+}
+
+func (column *TableColumn) SetRenderer(renderer TableCellRenderer) *TableColumn {
+	column.renderer = renderer
+	column.table.Reload()
+
+	return column
+}
+
+// SetLessFunc replaces the default order of the column, which compares the
+// cell texts as numbers when both are numbers and as strings otherwise.
+func (column *TableColumn) SetLessFunc(less TableLessFunc) *TableColumn {
+	column.less = less
+
+	return column
+}
+
+func (column *TableColumn) lessRows(a, b int) bool {
+	if column.less != nil {
+		return column.less(a, b)
+	}
+
+	source := column.table.source
+	ta := source.GetCellText(a, column.index)
+	tb := source.GetCellText(b, column.index)
+	fa, errA := strconv.ParseFloat(ta, 64)
+	fb, errB := strconv.ParseFloat(tb, 64)
+	if errA == nil && errB == nil {
+		return fa < fb
+	}
+
+	return ta < tb
+}
+
+// tableHeader is the Label showing the title of a column, with an arrow when
+// the rows are sorted by it.
+type tableHeader struct {
+	*Label
+	column *TableColumn
+}
+
+func (header *tableHeader) paintBackground(context canvas.Canvas2D) {
+	style := header.getStyle("")
+	w := float64(header.rect.W)
+	h := float64(header.rect.H)
+
+	context.SetFillStyle(colorOr(style.FillColor, TABLE_HEADER_COLOR))
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.MoveTo(w-0.5, 0)
+	context.LineTo(w-0.5, h)
+	context.MoveTo(0, h-0.5)
+	context.LineTo(w, h-0.5)
+	context.Stroke()
+
+	return
+}
+
+func (header *tableHeader) paintSelf(context canvas.Canvas2D) {
+	header.Label.paintSelf(context)
+
+	table := header.column.table
+	if table.sortColumn != header.column || table.sortOrder == TABLE_SORT_NONE {
+		return
+	}
+
+	x := float64(header.rect.W - TABLE_CELL_PADDING - 4)
+	y := float64(header.rect.H) / 2
+	context.SetFillStyle(header.GetTextColor())
+	context.BeginPath()
+	if table.sortOrder == TABLE_SORT_ASCENDING {
+		context.MoveTo(x-4, y+2)
+		context.LineTo(x+4, y+2)
+		context.LineTo(x, y-3)
+	} else {
+		context.MoveTo(x-4, y-2)
+		context.LineTo(x+4, y-2)
+		context.LineTo(x, y+3)
+	}
+	context.ClosePath()
+	context.Fill()
+
+	return
+}
+
+// TableCell is the Label showing a cell of a Table. Cells are recycled as
+// the table scrolls, so they should not keep state of their own.
+type TableCell struct {
+	*Label
+	table  *Table
+	row    int
+	column *TableColumn
+}
+
+// GetRow returns the row of the table showing the cell, see
+// Table.GetSourceRow.
+func (cell *TableCell) GetRow() int {
+	return cell.row
+}
+
+func (cell *TableCell) GetColumn() *TableColumn {
+	return cell.column
+}
+
+func (cell *TableCell) GetTable() *Table {
+	return cell.table
+}
+
+func (cell *TableCell) paintBackground(context canvas.Canvas2D) {
+	style := cell.getStyle("")
+	w := float64(cell.rect.W)
+	h := float64(cell.rect.H)
+
+	if cell.selected {
+		context.SetFillStyle(colorOr(style.FillColor, LIST_ITEM_SELECTED_COLOR))
+	} else {
+		context.SetFillStyle(colorOr(style.FillColor, TABLE_CELL_COLOR))
+	}
+	context.FillRect(0, 0, w, h)
+
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(TABLE_GRID_COLOR)
+	context.BeginPath()
+	context.MoveTo(w-0.5, 0)
+	context.LineTo(w-0.5, h)
+	context.MoveTo(0, h-0.5)
+	context.LineTo(w, h-0.5)
+	context.Stroke()
+
+	return
+}
+
+// tableEditor is the Edit used to edit a cell in place. Enter commits the
+// text and Escape cancels.
+type tableEditor struct {
+	*Edit
+	table *Table
+}
+
+// paintBackground hides the cell under the editor.
+func (editor *tableEditor) paintBackground(context canvas.Canvas2D) {
+	w := float64(editor.rect.W)
+	h := float64(editor.rect.H)
+
+	context.SetFillStyle(TABLE_CELL_COLOR)
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(TABLE_DROP_COLOR)
+	context.BeginPath()
+	context.Rect(0.5, 0.5, w-1, h-1)
+	context.Stroke()
+
+	return
+}
+
+func (editor *tableEditor) onKeyDown(code int) {
+	switch code {
+	case keyevent.DOM_VK_RETURN:
+		editor.table.CommitEdit()
+	case keyevent.DOM_VK_ESCAPE:
+		editor.table.CancelEdit()
+	default:
+		editor.Edit.onKeyDown(code)
+	}
+
+	return
+}
+
+// tableContent is the scrolled child of a Table. It spans the header and all
+// rows, holds the cells of the visible rows and columns, and paints them in
+// layers so that the header and the frozen column stay in place.
+type tableContent struct {
+	*Widget
+	table *Table
+}
+
+type tableCellKey struct {
+	row    int
+	column *TableColumn
+}
+
+// Table shows the rows of a TableDataSource under a header row, scrolled in
+// both directions. Only the visible cells get a widget, so tables of any
+// length cost the same to lay out and paint.
+//
+// Clicking a header sorts by its column, dragging a header moves the column
+// and dragging the right edge of a header resizes it. With SetFrozenColumn
+// the first column stays in place when scrolling horizontally. Double
+// clicking an editable cell, or pressing F2, edits it in place.
+//
+// Rows are those shown, after sorting, and GetSourceRow maps them to the rows
+// of the data source. Selection follows ListView, and the changed handler
+// receives the sorted []int of selected rows.
+type Table struct {
+	*ScrollView
+	itemSelection
+	itemView
+	content      *tableContent
+	source       TableDataSource
+	columns      []*TableColumn
+	order        []int
+	rowHeight    int
+	frozen       bool
+	sortColumn   *TableColumn
+	sortOrder    TableSortOrder
+	editor       *tableEditor
+	editRow      int
+	editColumn   *TableColumn
+	editorTarget bool
+	resizing     *TableColumn
+	pressed      *TableColumn
+	dragging     bool
+	dragStart    structs.Point
+	dragWidth    int
+	dropIndex    int
+}
+
+func NewTable(parent *Widget, x, y, w, h float32) *Table {
+	table := &Table{
+		ScrollView:    NewScrollView(parent, x, y, w, h),
+		itemSelection: newItemSelection(),
+		rowHeight:     TABLE_ROW_HEIGHT,
+	}
+	table.itemView = newItemView(table.ScrollView, &table.itemSelection, table)
+	table.t = TYPE_TABLE
+	table.SetScrollType(SCROLL_TYPE_BOTH)
+	table.I = table
+
+	table.content = &tableContent{
+		Widget: NewWidget(TYPE_VIEW_BASE, table.Widget, 0, 0, 0, 0),
+		table:  table,
+	}
+	table.content.I = table.content
+	table.updateLayout()
+
+	return table
+}
+
+// AddColumn appends a column showing column index of the data source, the
+// index being the number of columns added before it. A width of 0 selects
+// TABLE_COLUMN_WIDTH.
+func (table *Table) AddColumn(title string, width int) *TableColumn {
+	if width <= 0 {
+		width = TABLE_COLUMN_WIDTH
+	}
+
+	column := &TableColumn{
+		table:    table,
+		index:    len(table.columns),
+		width:    int(math.Max(TABLE_COLUMN_MIN_WIDTH, float64(width))),
+		align:    "left",
+		sortable: true,
+	}
+
+	column.header = &tableHeader{
+		Label:  NewLabel(table.content.Widget, 0, 0, 0, 0),
+		column: column,
+	}
+	column.header.t = TYPE_TABLE_HEADER
+	column.header.SetBorder(TABLE_CELL_PADDING, 0, TABLE_CELL_PADDING+12, 0)
+	column.header.SetTextAlignH("left")
+	column.header.SetText(title, false)
+	column.header.I = column.header
+
+	table.columns = append(table.columns, column)
+	table.Reload()
+
+	return column
+}
+
+// GetColumns returns the columns in the order they are shown.
+func (table *Table) GetColumns() []*TableColumn {
+	return append([]*TableColumn(nil), table.columns...)
+}
+
+// GetColumn returns the column showing column index of the data source.
+func (table *Table) GetColumn(index int) *TableColumn {
+	for _, column := range table.columns {
+		if column.index == index {
+			return column
+		}
+	}
+
+	return nil
+}
+
+// MoveColumn shows column at position index.
+func (table *Table) MoveColumn(column *TableColumn, index int) *Table {
+	from := table.getColumnPosition(column)
+	if from < 0 {
+		return table
+	}
+
+	index = int(math.Max(0, math.Min(float64(len(table.columns)-1), float64(index))))
+	columns := append(table.columns[:from:from], table.columns[from+1:]...)
+	columns = append(columns[:index], append([]*TableColumn{column}, columns[index:]...)...)
+	table.columns = columns
+
+	return table.Reload()
+}
+
+// SetFrozenColumn keeps the first column in place when scrolling
+// horizontally.
+func (table *Table) SetFrozenColumn(frozen bool) *Table {
+	table.frozen = frozen
+	table.PostRedraw()
+
+	return table
+}
+
+func (table *Table) IsFrozenColumn() bool {
+	return table.frozen
+}
+
+func (table *Table) SetDataSource(source TableDataSource) *Table {
+	table.CancelEdit()
+	table.source = source
+	table.resetItems()
+	table.SetScrollPositionH(0)
+
+	return table.Reload()
+}
+
+func (table *Table) GetDataSource() TableDataSource {
+	return table.source
+}
+
+// Reload rereads the row count, sorts the rows again and renders the visible
+// cells again. Call it whenever the data behind the source changes.
+func (table *Table) Reload() *Table {
+	count := table.getCount()
+	table.order = make([]int, count)
+	for i := range table.order {
+		table.order[i] = i
+	}
+	table.sortRows()
+	table.reloadItems()
+
+	return table
+}
+
+func (table *Table) SetRowHeight(rowHeight int) *Table {
+	table.rowHeight = int(math.Max(1, float64(rowHeight)))
+
+	return table.Reload()
+}
+
+func (table *Table) GetRowHeight() int {
+	return table.rowHeight
+}
+
+// SortBy sorts the rows by column, or restores the order of the data source
+// with TABLE_SORT_NONE. The selection follows the rows.
+func (table *Table) SortBy(column *TableColumn, order TableSortOrder) *Table {
+	if column == nil {
+		order = TABLE_SORT_NONE
+	}
+
+	table.CommitEdit()
+	selected := make(map[int]bool, len(table.selected))
+	for row := range table.selected {
+		selected[table.order[row]] = true
+	}
+	current := table.GetSourceRow(table.current)
+	anchor := table.GetSourceRow(table.anchor)
+
+	table.sortColumn = column
+	table.sortOrder = order
+	for i := range table.order {
+		table.order[i] = i
+	}
+	table.sortRows()
+
+	rows := make([]int, len(table.order))
+	for row, sourceRow := range table.order {
+		rows[sourceRow] = row
+	}
+
+	table.selected = make(map[int]bool, len(selected))
+	for sourceRow := range selected {
+		table.selected[rows[sourceRow]] = true
+	}
+	if current >= 0 {
+		table.current = rows[current]
+	}
+	if anchor >= 0 {
+		table.anchor = rows[anchor]
+	}
+
+	table.dirty = true
+	table.PostRedraw()
+
+	return table
+}
+
+func (table *Table) GetSortColumn() *TableColumn {
+	return table.sortColumn
+}
+
+func (table *Table) GetSortOrder() TableSortOrder {
+	return table.sortOrder
+}
+
+// GetSourceRow returns the row of the data source shown at row, or -1.
+func (table *Table) GetSourceRow(row int) int {
+	if row < 0 || row >= len(table.order) {
+		return -1
+	}
+
+	return table.order[row]
+}
+
+func (table *Table) SetSelectionMode(mode ListSelectionMode) *Table {
+	if mode == LIST_SELECTION_RADIO {
+		mode = LIST_SELECTION_SINGLE
+	}
+	table.setMode(mode)
+	table.PostRedraw()
+
+	return table
+}
+
+// SetActivatedHandler sets the handler called when a row is double clicked,
+// outside of an editable cell, or Enter is pressed on it.
+func (table *Table) SetActivatedHandler(onActivated ListActivatedHandler) *Table {
+	table.onActivated = onActivated
+
+	return table
+}
+
+func (table *Table) SetCurrent(row int) *Table {
+	table.setCurrent(row)
+
+	return table
+}
+
+// SetSelected selects or deselects a row. Outside of
+// LIST_SELECTION_MULTIPLE mode selecting a row deselects the others.
+func (table *Table) SetSelected(row int, selected, notify bool) *Table {
+	table.selectItem(row, selected, notify)
+
+	return table
+}
+
+func (table *Table) SelectAll(notify bool) *Table {
+	table.selectAllItems(notify)
+
+	return table
+}
+
+func (table *Table) ClearSelection(notify bool) *Table {
+	table.clearSelection(notify)
+
+	return table
+}
+
+// EnsureVisible scrolls the least needed to show row below the header.
+func (table *Table) EnsureVisible(row int) *Table {
+	table.ensureVisible(row)
+
+	return table
+}
+
+// EnsureColumnVisible scrolls the least needed to show column beside the
+// frozen one.
+func (table *Table) EnsureColumnVisible(column *TableColumn) *Table {
+	if table.isFrozen(column) {
+		return table
+	}
+
+	left := table.getColumnX(column) - table.getFrozenWidth()
+	right := table.getColumnX(column) + column.width
+	width := table.getViewWidth()
+
+	position := table.GetScrollPositionH()
+	if left < int(position) {
+		table.SetScrollPositionH(float64(left))
+	} else if right > int(position)+width {
+		table.SetScrollPositionH(float64(right - width))
+	}
+
+	return table
+}
+
+// EditCell starts editing the cell of row in column, if the column is
+// editable and the data source a TableEditableSource.
+func (table *Table) EditCell(row int, column *TableColumn) *Table {
+	if _, ok := table.source.(TableEditableSource); !ok || column == nil || !column.editable {
+		return table
+	}
+
+	if row < 0 || row >= table.getCount() || !table.enable {
+		return table
+	}
+
+	table.CommitEdit()
+	table.EnsureVisible(row)
+	table.EnsureColumnVisible(column)
+
+	if table.editor == nil {
+		table.editor = &tableEditor{
+			Edit:  NewEdit(table.content.Widget, 0, 0, 0, 0),
+			table: table,
+		}
+		table.editor.I = table.editor
+	}
+
+	table.editRow = row
+	table.editColumn = column
+	editor := table.editor
+	editor.SetText(table.source.GetCellText(table.order[row], column.index), false)
+	editor.SelectAll()
+	editor.SetVisible(true)
+	table.placeEditor()
+
+	if window := table.GetWindow(); window != nil {
+		window.SetFocus(editor.Widget)
+	}
+	table.PostRedraw()
+
+	return table
+}
+
+func (table *Table) IsEditing() bool {
+	return table.editor != nil && table.editor.visible
+}
+
+// CommitEdit stores the text of the cell being edited in the data source.
+func (table *Table) CommitEdit() *Table {
+	if !table.IsEditing() {
+		return table
+	}
+
+	if source, ok := table.source.(TableEditableSource); ok && table.editRow < table.getCount() {
+		source.SetCellText(table.order[table.editRow], table.editColumn.index, table.editor.GetText())
+	}
+	table.dirty = true
+
+	return table.CancelEdit()
+}
+
+// CancelEdit stops editing without changing the data source.
+func (table *Table) CancelEdit() *Table {
+	if !table.IsEditing() {
+		return table
+	}
+
+	table.editor.SetVisible(false)
+	table.editorTarget = false
+	if window := table.GetWindow(); window != nil && window.GetFocus() == table.editor.Widget {
+		window.SetFocus(table.Widget)
+	}
+	table.PostRedraw()
+
+	return table
+}
+
+func (table *Table) getCount() int {
+	if table.source == nil {
+		return 0
+	}
+
+	return table.source.GetRowCount()
+}
+
+// getItemSpan returns the top and bottom of row, the header aside.
+func (table *Table) getItemSpan(row int) (top, bottom int) {
+	top = row * table.rowHeight
+
+	return top, top + table.rowHeight
+}
+
+// getPageHeight returns the height of the view below the header.
+func (table *Table) getPageHeight() int {
+	return table.getViewHeight() - TABLE_HEADER_HEIGHT
+}
+
+func (table *Table) getPageSize() int {
+	return int(math.Max(1, float64(table.getPageHeight()/table.rowHeight)))
+}
+
+func (table *Table) getColumnPosition(column *TableColumn) int {
+	for i, iter := range table.columns {
+		if iter == column {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (table *Table) isFrozen(column *TableColumn) bool {
+	return table.frozen && len(table.columns) > 0 && table.columns[0] == column
+}
+
+func (table *Table) getFrozenWidth() int {
+	if table.frozen && len(table.columns) > 0 {
+		return table.columns[0].width
+	}
+
+	return 0
+}
+
+// getColumnX returns the left of column in content coordinates, as if it
+// was not frozen.
+func (table *Table) getColumnX(column *TableColumn) int {
+	x := 0
+	for _, iter := range table.columns {
+		if iter == column {
+			break
+		}
+		x += iter.width
+	}
+
+	return x
+}
+
+// getCellX returns where column is painted, which follows the scrolling when
+// it is frozen.
+func (table *Table) getCellX(column *TableColumn) int {
+	if table.isFrozen(column) {
+		return int(table.getXOffset())
+	}
+
+	return table.getColumnX(column)
+}
+
+func (table *Table) getTotalWidth() int {
+	width := 0
+	for _, column := range table.columns {
+		width += column.width
+	}
+
+	return width
+}
+
+func (table *Table) getRowY(row int) int {
+	return TABLE_HEADER_HEIGHT + row*table.rowHeight
+}
+
+func (table *Table) isInHeader(p *structs.Point) bool {
+	return p.Y < int(table.getYOffset())+TABLE_HEADER_HEIGHT
+}
+
+// getColumnAt returns the column painted at x, in content coordinates.
+func (table *Table) getColumnAt(x int) *TableColumn {
+	if len(table.columns) > 0 && table.frozen {
+		left := int(table.getXOffset())
+		if x >= left && x < left+table.columns[0].width {
+			return table.columns[0]
+		}
+	}
+
+	left := 0
+	for _, column := range table.columns {
+		if x >= left && x < left+column.width && !table.isFrozen(column) {
+			return column
+		}
+		left += column.width
+	}
+
+	return nil
+}
+
+// getResizeColumnAt returns the column whose right edge is near x.
+func (table *Table) getResizeColumnAt(x int) *TableColumn {
+	var found *TableColumn
+	for _, column := range table.getVisibleColumns() {
+		right := table.getCellX(column) + column.width
+		if math.Abs(float64(x-right)) <= TABLE_RESIZE_MARGIN {
+			found = column
+		}
+	}
+
+	if found != nil && !table.isFrozen(found) && x < int(table.getXOffset())+table.getFrozenWidth()-TABLE_RESIZE_MARGIN {
+		return nil
+	}
+
+	return found
+}
+
+func (table *Table) getRowAt(y int) int {
+	row := int(math.Floor(float64(y-TABLE_HEADER_HEIGHT) / float64(table.rowHeight)))
+	if row < 0 || row >= table.getCount() {
+		return -1
+	}
+
+	return row
+}
+
+// getDropIndex returns the position a dragged column is moved to when
+// dropped at x.
+func (table *Table) getDropIndex(x int) int {
+	first := 0
+	if table.frozen {
+		first = 1
+	}
+
+	for i := first; i < len(table.columns); i++ {
+		column := table.columns[i]
+		if x < table.getColumnX(column)+column.width/2 {
+			return i
+		}
+	}
+
+	return len(table.columns)
+}
+
+// getVisibleRows returns the rows from first up to, excluding, last that
+// intersect the view below the header.
+func (table *Table) getVisibleRows() (first, last int) {
+	top := int(table.getYOffset())
+	first = top / table.rowHeight
+	last = int(math.Min(float64(table.getCount()), float64((top+table.getViewHeight()-TABLE_HEADER_HEIGHT)/table.rowHeight+1)))
+
+	return first, last
+}
+
+func (table *Table) getVisibleColumns() []*TableColumn {
+	left := int(table.getXOffset())
+	right := left + table.getViewWidth()
+
+	var columns []*TableColumn
+	x := 0
+	for _, column := range table.columns {
+		if table.isFrozen(column) || (x < right && x+column.width > left) {
+			columns = append(columns, column)
+		}
+		x += column.width
+	}
+
+	return columns
+}
+
+// updateLayout sizes the content child to the columns and rows and refreshes
+// the scroll bars, keeping the horizontal position the ScrollView resets.
+func (table *Table) updateLayout() {
+	size := int(table.scrollBarSize)
+	width := int(math.Max(float64(table.getTotalWidth()), float64(table.rect.W-size)))
+	height := int(math.Max(float64(table.getRowY(table.getCount())), float64(table.rect.H-size)))
+
+	position := table.GetScrollPositionH()
+	table.resizeContent(table.content.Widget, width, height)
+	table.SetScrollPositionH(position)
+
+	return
+}
+
+func (table *Table) relayout(context canvas.Canvas2D, force bool) {
+	if table.needRelayout || force {
+		table.updateLayout()
+	}
+
+	return
+}
+
+func (table *Table) sortRows() {
+	column := table.sortColumn
+	if column == nil || table.sortOrder == TABLE_SORT_NONE || table.source == nil {
+		return
+	}
+
+	descending := table.sortOrder == TABLE_SORT_DESCENDING
+	sort.SliceStable(table.order, func(i, j int) bool {
+		if descending {
+			return column.lessRows(table.order[j], table.order[i])
+		}
+		return column.lessRows(table.order[i], table.order[j])
+	})
+
+	return
+}
+
+// bindCells recycles the cells that scrolled out, binds cells to the ones
+// that scrolled in and places the editor.
+func (table *Table) bindCells() {
+	first, last := table.getVisibleRows()
+	columns := table.getVisibleColumns()
+	visible := make(map[*TableColumn]bool, len(columns))
+	for _, column := range columns {
+		visible[column] = true
+	}
+
+	table.recycleItems(func(key interface{}) bool {
+		cell := key.(tableCellKey)
+		return cell.row >= first && cell.row < last && visible[cell.column]
+	})
+
+	for row := first; row < last; row++ {
+		for _, column := range columns {
+			recycled, bound := table.bindItem(tableCellKey{row: row, column: column}, table.newCell)
+			cell := recycled.(*TableCell)
+			cell.row = row
+			cell.column = column
+
+			cell.Move(table.getCellX(column), table.getRowY(row))
+			cell.Resize(column.width, table.rowHeight)
+			if !bound || table.dirty {
+				cell.SetVisible(true)
+				cell.SetTextAlignH(column.align)
+				cell.SetText(table.source.GetCellText(table.order[row], column.index), false)
+				if column.renderer != nil {
+					column.renderer(cell, table.order[row])
+				}
+			}
+			cell.selected = table.selected[row]
+		}
+	}
+	table.dirty = false
+
+	if table.IsEditing() {
+		table.placeEditor()
+	}
+
+	return
+}
+
+// placeHeaders keeps the headers at the top of the view.
+func (table *Table) placeHeaders() {
+	top := int(table.getYOffset())
+	for _, column := range table.columns {
+		column.header.Move(table.getCellX(column), top)
+		column.header.Resize(column.width, TABLE_HEADER_HEIGHT)
+	}
+
+	return
+}
+
+func (table *Table) placeEditor() {
+	column := table.editColumn
+	table.editor.Move(table.getCellX(column), table.getRowY(table.editRow))
+	table.editor.Resize(column.width, table.rowHeight)
+
+	return
+}
+
+func (table *Table) newCell() viewItem {
+	cell := &TableCell{
+		Label: NewLabel(table.content.Widget, 0, 0, 0, 0),
+		table: table,
+	}
+	cell.t = TYPE_TABLE_CELL
+	cell.selectable = true
+	cell.SetBorder(TABLE_CELL_PADDING, 0, TABLE_CELL_PADDING, 0)
+	cell.I = cell
+
+	return cell
+}
+
+func (table *Table) isInEditor(point *structs.Point) bool {
+	return table.IsEditing() && isPointInRect(table.content.translatePoint(point), table.editor.rect)
+}
+
+func (table *Table) onContentPointerDown(point *structs.Point) {
+	if !table.enable {
+		return
+	}
+
+	if table.isInEditor(point) {
+		table.editorTarget = true
+		table.editor.I.onPointerDown(point)
+		return
+	}
+	table.CommitEdit()
+
+	if window := table.GetWindow(); window != nil {
+		window.SetFocus(table.Widget)
+	}
+
+	p := table.content.translatePoint(point)
+	if table.isInHeader(p) {
+		table.dragStart = *p
+		table.dragging = false
+		if column := table.getResizeColumnAt(p.X); column != nil {
+			table.resizing = column
+			table.dragWidth = column.width
+		} else {
+			table.pressed = table.getColumnAt(p.X)
+		}
+		return
+	}
+
+	row := table.getRowAt(p.Y)
+	if row < 0 {
+		return
+	}
+
+	table.pressItem(row)
+
+	return
+}
+
+func (table *Table) onContentPointerMove(point *structs.Point) {
+	if table.editorTarget {
+		table.editor.I.onPointerMove(point)
+		return
+	}
+
+	// The release went elsewhere when the button is up.
+	if (table.resizing != nil || table.pressed != nil) && !table.isPointerDown() {
+		table.endDrag()
+	}
+
+	p := table.content.translatePoint(point)
+	if table.resizing != nil {
+		table.resizing.SetWidth(table.dragWidth + p.X - table.dragStart.X)
+		return
+	}
+
+	if table.pressed != nil {
+		if !table.dragging && !table.isFrozen(table.pressed) &&
+			math.Abs(float64(p.X-table.dragStart.X)) > TABLE_DRAG_DISTANCE {
+			table.dragging = true
+		}
+
+		if table.dragging {
+			table.dropIndex = table.getDropIndex(p.X)
+			table.PostRedraw()
+		}
+		return
+	}
+
+	cursor := "default"
+	if table.isInHeader(p) && table.getResizeColumnAt(p.X) != nil {
+		cursor = "col-resize"
+	}
+
+	if cursor != table.content.cursor {
+		table.content.SetCursor(cursor).changeCursor()
+	}
+
+	return
+}
+
+func (table *Table) onContentPointerUp(point *structs.Point) {
+	if table.editorTarget {
+		table.editorTarget = false
+		table.editor.I.onPointerUp(point)
+		return
+	}
+
+	p := table.content.translatePoint(point)
+	column := table.pressed
+	dragging := table.dragging
+	table.endDrag()
+
+	if column == nil {
+		return
+	}
+
+	if dragging {
+		index := table.dropIndex
+		if index > table.getColumnPosition(column) {
+			index--
+		}
+		table.MoveColumn(column, index)
+	} else if column.sortable && table.isInHeader(p) && table.getColumnAt(p.X) == column {
+		order := TABLE_SORT_ASCENDING
+		if table.sortColumn == column && table.sortOrder == TABLE_SORT_ASCENDING {
+			order = TABLE_SORT_DESCENDING
+		}
+		table.SortBy(column, order)
+	}
+
+	return
+}
+
+// endDrag stops resizing or moving a column.
+func (table *Table) endDrag() {
+	if table.dragging {
+		table.PostRedraw()
+	}
+	table.resizing = nil
+	table.pressed = nil
+	table.dragging = false
+
+	return
+}
+
+func (table *Table) onContentDoubleClick(point *structs.Point) {
+	if table.isInEditor(point) {
+		table.editor.I.onDoubleClick(point)
+		return
+	}
+
+	p := table.content.translatePoint(point)
+	if table.isInHeader(p) {
+		return
+	}
+
+	row := table.getRowAt(p.Y)
+	column := table.getColumnAt(p.X)
+	if _, ok := table.source.(TableEditableSource); ok && column != nil && column.editable {
+		table.EditCell(row, column)
+	} else {
+		table.activate(row)
+	}
+
+	return
+}
+
+func (table *Table) onKeyDown(code int) {
+	if !table.enable {
+		return
+	}
+
+	switch code {
+	case keyevent.DOM_VK_LEFT:
+		table.SetScrollPositionH(table.GetScrollPositionH() - TABLE_SCROLL_STEP)
+	case keyevent.DOM_VK_RIGHT:
+		table.SetScrollPositionH(table.GetScrollPositionH() + TABLE_SCROLL_STEP)
+	case keyevent.DOM_VK_F2:
+		for _, column := range table.columns {
+			if column.editable {
+				table.EditCell(table.current, column)
+				break
+			}
+		}
+	default:
+		table.onItemKeyDown(code)
+	}
+
+	if table.keyDownHandler != nil {
+		table.keyDownHandler(code)
+	}
+
+	return
+}
+
+func (content *tableContent) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+// paintChildren paints the scrolling cells, then the frozen ones over them,
+// then the header over both.
+func (content *tableContent) paintChildren(context canvas.Canvas2D) {
+	table := content.table
+	left := table.getXOffset()
+	top := table.getYOffset()
+	width := float64(table.getViewWidth())
+	height := float64(table.getViewHeight())
+
+	context.Save()
+	context.BeginPath()
+	context.Rect(left, top, width, height)
+	context.Clip()
+
+	if table.source != nil {
+		table.bindCells()
+		content.paintLayer(context, false)
+		content.paintLayer(context, true)
+
+		if table.editing && table.current >= 0 && table.selectionMode != LIST_SELECTION_NONE {
+			y := float64(table.getRowY(table.current))
+			w := math.Min(width, float64(table.getTotalWidth())-left)
+			context.SetLineWidth(1)
+			context.SetStrokeStyle(MENU_LINE_COLOR)
+			context.BeginPath()
+			context.Rect(left+0.5, y+0.5, w-1, float64(table.rowHeight)-1)
+			context.Stroke()
+		}
+	}
+
+	table.placeHeaders()
+	context.SetFillStyle(TABLE_HEADER_COLOR)
+	context.FillRect(left, top, width, TABLE_HEADER_HEIGHT)
+	for _, column := range table.columns {
+		if !table.isFrozen(column) {
+			column.header.draw(context)
+		}
+	}
+	if table.frozen && len(table.columns) > 0 {
+		table.columns[0].header.draw(context)
+	}
+
+	if table.dragging {
+		x := float64(table.getTotalWidth())
+		if table.dropIndex < len(table.columns) {
+			x = float64(table.getCellX(table.columns[table.dropIndex]))
+		}
+		context.SetFillStyle(TABLE_DROP_COLOR)
+		context.FillRect(x-1, top, 2, height)
+	}
+	context.Restore()
+
+	return
+}
+
+func (content *tableContent) paintLayer(context canvas.Canvas2D, frozen bool) {
+	table := content.table
+	for _, item := range table.items {
+		if cell := item.(*TableCell); table.isFrozen(cell.column) == frozen {
+			cell.draw(context)
+		}
+	}
+
+	if table.IsEditing() && table.isFrozen(table.editColumn) == frozen {
+		table.editor.draw(context)
+	}
+
+	return
+}
+
+func (content *tableContent) onPointerDown(point *structs.Point) {
+	content.table.onContentPointerDown(point)
+
+	return
+}
+
+func (content *tableContent) onPointerMove(point *structs.Point) {
+	content.table.onContentPointerMove(point)
+
+	return
+}
+
+func (content *tableContent) onPointerUp(point *structs.Point) {
+	content.table.onContentPointerUp(point)
+
+	return
+}
+
+func (content *tableContent) onDoubleClick(point *structs.Point) {
+	content.table.onContentDoubleClick(point)
+
+	return
+}
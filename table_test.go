@@ -0,0 +1,235 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/event"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"reflect"
+	"testing"
+)
+
+// newTableScene returns a Table 400x200 with a sortable and editable "name"
+// column and a sortable "size" column, both 100 wide, beside a window
+// covering 450,0 to 600,300.
+func newTableScene() (typist, *Table, StringTableSource) {
+	var table *Table
+	source := StringTableSource{{"b", "2"}, {"a", "10"}, {"c", "1"}}
+	k := newScene(600, 300, func(win *Window) {
+		table = NewTable(win.Widget, 0, 0, 400, 200).SetDataSource(source)
+		table.AddColumn("name", 100).SetSortable(true).SetEditable(true)
+		table.AddColumn("size", 100).SetSortable(true)
+		NewWindow(win.manager, 450, 0, 150, 300)
+	})
+
+	return k, table, source
+}
+
+// getTableOrder returns the rows of the data source in the order shown.
+func getTableOrder(table *Table) []int {
+	rows := []int{}
+	for row := 0; row < table.getCount(); row++ {
+		rows = append(rows, table.GetSourceRow(row))
+	}
+
+	return rows
+}
+
+// getColumnTitles returns the titles of the columns in the order shown.
+func getColumnTitles(table *Table) []string {
+	titles := []string{}
+	for _, column := range table.GetColumns() {
+		titles = append(titles, column.GetTitle())
+	}
+
+	return titles
+}
+
+func TestTableSort(t *testing.T) {
+	k, table, _ := newTableScene()
+	name := table.GetColumn(0)
+	size := table.GetColumn(1)
+	y := TABLE_HEADER_HEIGHT / 2
+
+	// Select the row of "b" so that the selection is seen following it.
+	k.click(50, table.getRowY(0)+TABLE_ROW_HEIGHT/2)
+
+	steps := []struct {
+		x      int
+		column *TableColumn
+		order  TableSortOrder
+		rows   []int
+	}{
+		{50, name, TABLE_SORT_ASCENDING, []int{1, 0, 2}},
+		{50, name, TABLE_SORT_DESCENDING, []int{2, 0, 1}},
+		{50, name, TABLE_SORT_ASCENDING, []int{1, 0, 2}},
+		// Numbers sort by value, not as text.
+		{150, size, TABLE_SORT_ASCENDING, []int{2, 0, 1}},
+	}
+	for i, step := range steps {
+		k.click(step.x, y)
+		if table.GetSortColumn() != step.column || table.GetSortOrder() != step.order {
+			t.Fatalf("click %d: sorted by %v in order %d", i, table.GetSortColumn().GetTitle(), table.GetSortOrder())
+		}
+		if rows := getTableOrder(table); !reflect.DeepEqual(rows, step.rows) {
+			t.Fatalf("click %d: rows %v, want %v", i, rows, step.rows)
+		}
+		if selected := table.GetSelected(); len(selected) != 1 || table.GetSourceRow(selected[0]) != 0 {
+			t.Fatalf("click %d: selected %v, want the row of source row 0", i, selected)
+		}
+	}
+
+	table.SortBy(nil, TABLE_SORT_ASCENDING)
+	if table.GetSortOrder() != TABLE_SORT_NONE || !reflect.DeepEqual(getTableOrder(table), []int{0, 1, 2}) {
+		t.Fatalf("unsorted: order %d, rows %v", table.GetSortOrder(), getTableOrder(table))
+	}
+
+	size.SetSortable(false)
+	k.click(150, y)
+	if table.GetSortColumn() != nil {
+		t.Fatalf("clicking a column that is not sortable sorted by it")
+	}
+}
+
+func TestTableResizeColumn(t *testing.T) {
+	k, table, _ := newTableScene()
+	name := table.GetColumn(0)
+	y := TABLE_HEADER_HEIGHT / 2
+
+	k.drag(100, y, 160, y)
+	if name.GetWidth() != 160 {
+		t.Fatalf("width %d after dragging the edge, want 160", name.GetWidth())
+	}
+	if table.GetSortColumn() != nil {
+		t.Fatalf("resizing sorted by %s", table.GetSortColumn().GetTitle())
+	}
+
+	k.drag(160, y, 0, y)
+	if name.GetWidth() != TABLE_COLUMN_MIN_WIDTH {
+		t.Fatalf("width %d after shrinking, want %d", name.GetWidth(), TABLE_COLUMN_MIN_WIDTH)
+	}
+}
+
+func TestTableMoveColumn(t *testing.T) {
+	k, table, _ := newTableScene()
+	table.AddColumn("kind", 100)
+	y := TABLE_HEADER_HEIGHT / 2
+
+	k.drag(50, y, 260, y)
+	if titles := getColumnTitles(table); !reflect.DeepEqual(titles, []string{"size", "kind", "name"}) {
+		t.Fatalf("columns %v after moving name to the end", titles)
+	}
+	if table.GetSortColumn() != nil {
+		t.Fatalf("moving sorted by %s", table.GetSortColumn().GetTitle())
+	}
+
+	k.drag(250, y, 10, y)
+	if titles := getColumnTitles(table); !reflect.DeepEqual(titles, []string{"name", "size", "kind"}) {
+		t.Fatalf("columns %v after moving name to the front", titles)
+	}
+
+	// Short of TABLE_DRAG_DISTANCE the press is a click.
+	k.drag(50, y, 50+TABLE_DRAG_DISTANCE, y)
+	if titles := getColumnTitles(table); !reflect.DeepEqual(titles, []string{"name", "size", "kind"}) {
+		t.Fatalf("columns %v after a short drag", titles)
+	}
+	if table.GetSortColumn() != table.GetColumn(0) {
+		t.Fatalf("a short drag did not sort")
+	}
+}
+
+func TestTableDragReleasedOutside(t *testing.T) {
+	for _, release := range []struct {
+		name string
+		x, y int
+	}{
+		{"over another window", 500, 100},
+		{"outside the canvas", 700, 100},
+	} {
+		k, table, _ := newTableScene()
+		name := table.GetColumn(0)
+		y := TABLE_HEADER_HEIGHT / 2
+
+		k.pointer(event.EVENT_POINTER_DOWN, 100, y)
+		k.pointer(event.EVENT_POINTER_MOVE, 150, y)
+		k.pointer(event.EVENT_POINTER_MOVE, release.x, release.y)
+		k.pointer(event.EVENT_POINTER_UP, release.x, release.y)
+		width := name.GetWidth()
+		k.pointer(event.EVENT_POINTER_MOVE, 200, 100)
+		if name.GetWidth() != width {
+			t.Fatalf("%s: hovering after the release resized to %d", release.name, name.GetWidth())
+		}
+
+		k.pointer(event.EVENT_POINTER_DOWN, 50, y)
+		k.pointer(event.EVENT_POINTER_MOVE, 250, y)
+		k.pointer(event.EVENT_POINTER_MOVE, release.x, release.y)
+		k.pointer(event.EVENT_POINTER_UP, release.x, release.y)
+		k.pointer(event.EVENT_POINTER_MOVE, 200, 100)
+		if table.dragging {
+			t.Fatalf("%s: still moving the column after the release", release.name)
+		}
+		k.click(200, table.getRowY(0)+TABLE_ROW_HEIGHT/2)
+		if titles := getColumnTitles(table); !reflect.DeepEqual(titles, []string{"name", "size"}) {
+			t.Fatalf("%s: columns %v after clicking a row", release.name, titles)
+		}
+
+		name.SetWidth(100)
+		k.m.Snapshot()
+		k.drag(100, y, 120, y)
+		if name.GetWidth() != 120 {
+			t.Fatalf("%s: width %d after resizing again, want 120", release.name, name.GetWidth())
+		}
+	}
+}
+
+func TestTableEditCell(t *testing.T) {
+	k, table, source := newTableScene()
+	name := table.GetColumn(0)
+	size := table.GetColumn(1)
+	y := table.getRowY(1) + TABLE_ROW_HEIGHT/2
+
+	// The size column is not editable, so double clicking activates the row.
+	activated := -1
+	table.SetActivatedHandler(func(row int) {
+		activated = row
+
+		return
+	})
+	k.pointer(event.EVENT_DOUBLE_CLICK, 150, y)
+	if table.IsEditing() || activated != 1 {
+		t.Fatalf("double clicking a read-only cell: editing %v, activated %d", table.IsEditing(), activated)
+	}
+
+	k.pointer(event.EVENT_DOUBLE_CLICK, 50, y)
+	if !table.IsEditing() || table.editColumn != name || table.editRow != 1 {
+		t.Fatalf("double click did not edit the cell")
+	}
+	k.typeText("z")
+	k.key(keyevent.DOM_VK_RETURN)
+	if table.IsEditing() || source[1][0] != "z" {
+		t.Fatalf("Enter: editing %v, cell %q", table.IsEditing(), source[1][0])
+	}
+
+	k.click(50, table.getRowY(0)+TABLE_ROW_HEIGHT/2)
+	k.key(keyevent.DOM_VK_F2)
+	if !table.IsEditing() || table.editRow != 0 {
+		t.Fatalf("F2 did not edit the current row")
+	}
+	k.typeText("x")
+	k.key(keyevent.DOM_VK_ESCAPE)
+	if table.IsEditing() || source[0][0] != "b" {
+		t.Fatalf("Escape: editing %v, cell %q", table.IsEditing(), source[0][0])
+	}
+
+	// Sorting commits the edit and edits go to the source row.
+	table.SortBy(name, TABLE_SORT_DESCENDING)
+	table.EditCell(0, name)
+	k.typeText("y")
+	table.SortBy(size, TABLE_SORT_ASCENDING)
+	if table.IsEditing() || source[1][0] != "y" {
+		t.Fatalf("sorting while editing: editing %v, cells %v", table.IsEditing(), source)
+	}
+
+	table.EditCell(0, size)
+	if table.IsEditing() {
+		t.Fatalf("edited a column that is not editable")
+	}
+}
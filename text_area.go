@@ -0,0 +1,777 @@
+package gwk
+
+import (
+	"fmt"
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	TEXT_AREA_PADDING    = 4
+	TEXT_AREA_MAX_UNDO   = 100
+	TEXT_AREA_EDIT_TYPE  = "type"
+	TEXT_AREA_EDIT_OTHER = "other"
+)
+
+type textAreaLine struct {
+	start int
+	text  []rune
+}
+
+type textAreaSnapshot struct {
+	text   string
+	caret  int
+	anchor int
+}
+
+// textAreaContent is the scrolled child of a TextArea. It is sized to the
+// wrapped text so the ScrollView machinery handles scrolling, and forwards
+// painting and pointer input back to its TextArea.
+type textAreaContent struct {
+	*Widget
+	area *TextArea
+}
+
+// TextArea is a multi-line text editor. Lines are soft wrapped with
+// layoutText and scrolled vertically by the embedded ScrollView. Positions are
+// counted in runes of the whole text.
+type TextArea struct {
+	*ScrollView
+	content   *textAreaContent
+	lines     []textAreaLine
+	font      string
+	fontSize  int
+	tabSize   int
+	caret     int
+	anchor    int
+	desiredX  float64
+	selecting bool
+	lastEdit  string
+	undoStack []textAreaSnapshot
+	redoStack []textAreaSnapshot
+}
+
+func NewTextArea(parent *Widget, x, y, w, h float32) *TextArea {
+	area := &TextArea{
+		ScrollView: NewScrollView(parent, x, y, w, h),
+		fontSize:   14,
+		tabSize:    4,
+		desiredX:   -1,
+	}
+	area.t = TYPE_TEXT_AREA
+	area.cursor = "text"
+	area.SetScrollType(SCROLL_TYPE_V)
+	area.I = area
+
+	area.content = &textAreaContent{
+		Widget: NewWidget(TYPE_TEXT_AREA, area.Widget, 0, 0, 0, 0),
+		area:   area,
+	}
+	area.content.cursor = "text"
+	area.content.I = area.content
+	area.updateLayout()
+
+	return area
+}
+
+func (area *TextArea) SetText(str string, notify bool) *TextArea {
+	area.undoStack = nil
+	area.redoStack = nil
+	area.lastEdit = ""
+	area.setText(str, notify)
+	area.caret = len(area.getRunes())
+	area.anchor = area.caret
+	area.desiredX = -1
+
+	return area
+}
+
+func (area *TextArea) setText(str string, notify bool) {
+	area.text = str
+	if notify && area.onChanged != nil {
+		area.onChanged(area.text)
+	}
+	area.updateLayout()
+	area.PostRedraw()
+
+	return
+}
+
+func (area *TextArea) SetFontSize(fontSize int) *TextArea {
+	area.fontSize = fontSize
+	area.font = ""
+	area.updateLayout()
+
+	return area
+}
+
+func (area *TextArea) SetFont(font string) *TextArea {
+	area.font = font
+	area.updateLayout()
+
+	return area
+}
+
+// SetTabSize sets the number of spaces the tab key inserts.
+func (area *TextArea) SetTabSize(tabSize int) *TextArea {
+	area.tabSize = tabSize
+
+	return area
+}
+
+func (area *TextArea) GetCaret() int {
+	return area.caret
+}
+
+func (area *TextArea) SetCaret(caret int) *TextArea {
+	area.moveCaret(caret, false)
+
+	return area
+}
+
+// Select selects the runes in [start, end) and puts the caret at end.
+func (area *TextArea) Select(start, end int) *TextArea {
+	area.moveCaret(start, false)
+	area.moveCaret(end, true)
+
+	return area
+}
+
+func (area *TextArea) SelectAll() *TextArea {
+	return area.Select(0, len(area.getRunes()))
+}
+
+// GetSelection returns the selected range, start == end when nothing is
+// selected.
+func (area *TextArea) GetSelection() (start, end int) {
+	area.clampCaret()
+	if area.anchor < area.caret {
+		return area.anchor, area.caret
+	}
+
+	return area.caret, area.anchor
+}
+
+func (area *TextArea) GetSelectedText() string {
+	start, end := area.GetSelection()
+
+	return string(area.getRunes()[start:end])
+}
+
+// GetCaretLine returns the index of the wrapped line holding the caret.
+func (area *TextArea) GetCaretLine() int {
+	return area.getLineOf(area.caret)
+}
+
+func (area *TextArea) GetLineCount() int {
+	return len(area.lines)
+}
+
+func (area *TextArea) Copy() *TextArea {
+	if text := area.GetSelectedText(); len(text) > 0 {
+		GetWindowManagerInstance().SetClipboardText(text)
+	}
+
+	return area
+}
+
+func (area *TextArea) Cut() *TextArea {
+	if start, end := area.GetSelection(); start != end {
+		area.Copy()
+		area.insertText("", TEXT_AREA_EDIT_OTHER)
+	}
+
+	return area
+}
+
+func (area *TextArea) Paste() *TextArea {
+	text := strings.Replace(GetWindowManagerInstance().GetClipboardText(), "\r", "", -1)
+	if len(text) > 0 {
+		area.insertText(text, TEXT_AREA_EDIT_OTHER)
+	}
+
+	return area
+}
+
+func (area *TextArea) CanUndo() bool {
+	return len(area.undoStack) > 0
+}
+
+func (area *TextArea) CanRedo() bool {
+	return len(area.redoStack) > 0
+}
+
+// Undo reverts the last edit. Consecutive typed characters are undone
+// together.
+func (area *TextArea) Undo() *TextArea {
+	if n := len(area.undoStack); n > 0 {
+		area.redoStack = append(area.redoStack, area.snapshot())
+		area.restore(area.undoStack[n-1])
+		area.undoStack = area.undoStack[:n-1]
+	}
+
+	return area
+}
+
+func (area *TextArea) Redo() *TextArea {
+	if n := len(area.redoStack); n > 0 {
+		area.undoStack = append(area.undoStack, area.snapshot())
+		area.restore(area.redoStack[n-1])
+		area.redoStack = area.redoStack[:n-1]
+	}
+
+	return area
+}
+
+func (area *TextArea) snapshot() textAreaSnapshot {
+	return textAreaSnapshot{text: area.text, caret: area.caret, anchor: area.anchor}
+}
+
+func (area *TextArea) restore(snapshot textAreaSnapshot) {
+	area.lastEdit = ""
+	area.caret = snapshot.caret
+	area.anchor = snapshot.anchor
+	area.setText(snapshot.text, true)
+	area.ensureCaretVisible()
+
+	return
+}
+
+func (area *TextArea) pushUndo(kind string) {
+	if kind == TEXT_AREA_EDIT_TYPE && area.lastEdit == kind {
+		return
+	}
+
+	area.undoStack = append(area.undoStack, area.snapshot())
+	if len(area.undoStack) > TEXT_AREA_MAX_UNDO {
+		area.undoStack = area.undoStack[1:]
+	}
+	area.redoStack = nil
+	area.lastEdit = kind
+
+	return
+}
+
+func (area *TextArea) getRunes() []rune {
+	return []rune(area.text)
+}
+
+func (area *TextArea) getFont() string {
+	if len(area.font) > 0 {
+		return area.font
+	}
+
+	if area.fontSize > 0 {
+		return fmt.Sprintf("%dpx sans-serif", area.fontSize)
+	}
+
+	return area.getStyle("").Font
+}
+
+func (area *TextArea) getLineHeight() int {
+	return int(float64(area.fontSize) * 1.5)
+}
+
+func (area *TextArea) getTextWidth() int {
+	width := area.rect.W - 2*TEXT_AREA_PADDING
+	if area.vScrollBar.visible {
+		width -= int(area.scrollBarSize)
+	}
+
+	return width
+}
+
+func (area *TextArea) measure(context canvas.Canvas2D, runes []rune) float64 {
+	if len(runes) == 0 {
+		return 0
+	}
+
+	return context.MeasureText(string(runes)).Width
+}
+
+// updateLayout rewraps the text, resizes the content child to the text
+// height and refreshes the scroll bar.
+func (area *TextArea) updateLayout() {
+	context := area.getCanvas2D()
+	context.Save()
+	context.SetFont(area.getFont())
+	defer context.Restore()
+
+	width := area.getTextWidth()
+	area.lines = area.lines[:0]
+
+	start := 0
+	for _, paragraph := range strings.Split(area.text, "\n") {
+		runes := []rune(paragraph)
+		lines := layoutText(context, area.fontSize, paragraph, width, 0)
+		if len(lines) == 0 {
+			lines = []string{""}
+		}
+
+		offset := 0
+		for _, line := range lines {
+			n := len([]rune(line))
+			area.lines = append(area.lines, textAreaLine{start: start + offset, text: runes[offset : offset+n]})
+			offset += n
+		}
+		start += len(runes) + 1
+	}
+
+	height := len(area.lines)*area.getLineHeight() + 2*TEXT_AREA_PADDING
+	area.content.Resize(width+2*TEXT_AREA_PADDING, int(math.Max(float64(height), float64(area.rect.H))))
+	area.ScrollView.relayout(context, true)
+
+	return
+}
+
+func (area *TextArea) relayout(context canvas.Canvas2D, force bool) {
+	if area.needRelayout || force {
+		area.updateLayout()
+	}
+
+	return
+}
+
+func (area *TextArea) clampCaret() {
+	n := len(area.getRunes())
+	if area.caret > n {
+		area.caret = n
+	}
+
+	if area.anchor > n {
+		area.anchor = n
+	}
+
+	return
+}
+
+// getLineOf returns the line holding pos. A position on a soft wrap boundary
+// belongs to the following line.
+func (area *TextArea) getLineOf(pos int) int {
+	i := len(area.lines) - 1
+	for i > 0 && area.lines[i].start > pos {
+		i--
+	}
+
+	return i
+}
+
+// getLineEnd returns the last caret position of line i. On soft wrapped
+// lines that is before the last character, which starts the next line.
+func (area *TextArea) getLineEnd(i int) int {
+	line := area.lines[i]
+	end := line.start + len(line.text)
+	if i+1 < len(area.lines) && area.lines[i+1].start == end && end > line.start {
+		end--
+	}
+
+	return end
+}
+
+func (area *TextArea) getCaretX(context canvas.Canvas2D, pos int) float64 {
+	line := area.lines[area.getLineOf(pos)]
+
+	return area.measure(context, line.text[:pos-line.start])
+}
+
+// getPosAt returns the position nearest to x on line i.
+func (area *TextArea) getPosAt(context canvas.Canvas2D, i int, x float64) int {
+	if i < 0 {
+		i = 0
+	} else if i >= len(area.lines) {
+		i = len(area.lines) - 1
+	}
+
+	line := area.lines[i]
+	end := area.getLineEnd(i)
+	last := 0.0
+	for pos := line.start; pos < end; pos++ {
+		width := area.measure(context, line.text[:pos-line.start+1])
+		if x < (last+width)/2 {
+			return pos
+		}
+		last = width
+	}
+
+	return end
+}
+
+func (area *TextArea) getPosAtPoint(point *structs.Point) int {
+	p := area.content.translatePoint(point)
+	lineHeight := area.getLineHeight()
+	i := int(math.Floor(float64(p.Y-TEXT_AREA_PADDING) / float64(lineHeight)))
+
+	context := area.getCanvas2D()
+	context.Save()
+	context.SetFont(area.getFont())
+	defer context.Restore()
+
+	return area.getPosAt(context, i, float64(p.X-TEXT_AREA_PADDING))
+}
+
+func (area *TextArea) moveCaret(caret int, extend bool) {
+	n := len(area.getRunes())
+	if caret < 0 {
+		caret = 0
+	} else if caret > n {
+		caret = n
+	}
+
+	area.caret = caret
+	if !extend {
+		area.anchor = caret
+	}
+	area.desiredX = -1
+	area.lastEdit = ""
+	area.ensureCaretVisible()
+	area.PostRedraw()
+
+	return
+}
+
+// moveCaretByLine moves the caret delta lines up or down, keeping the
+// horizontal position it had when vertical movement started.
+func (area *TextArea) moveCaretByLine(delta int, extend bool) {
+	context := area.getCanvas2D()
+	context.Save()
+	context.SetFont(area.getFont())
+	defer context.Restore()
+
+	desiredX := area.desiredX
+	if desiredX < 0 {
+		desiredX = area.getCaretX(context, area.caret)
+	}
+
+	i := area.getLineOf(area.caret) + delta
+	if i < 0 {
+		area.moveCaret(0, extend)
+	} else if i >= len(area.lines) {
+		area.moveCaret(len(area.getRunes()), extend)
+	} else {
+		area.moveCaret(area.getPosAt(context, i, desiredX), extend)
+	}
+	area.desiredX = desiredX
+
+	return
+}
+
+func (area *TextArea) ensureCaretVisible() {
+	lineHeight := area.getLineHeight()
+	top := area.getLineOf(area.caret)*lineHeight + TEXT_AREA_PADDING
+	bottom := top + lineHeight
+	height := area.rect.H
+	if area.workArea != nil {
+		height = area.workArea.H
+	}
+
+	position := area.GetScrollPositionV()
+	if top < int(position) {
+		area.SetScrollPositionV(float64(top - TEXT_AREA_PADDING))
+	} else if bottom > int(position)+height {
+		area.SetScrollPositionV(float64(bottom + TEXT_AREA_PADDING - height))
+	}
+
+	return
+}
+
+// insertText replaces the selection with str and records the edit for
+// undo.
+func (area *TextArea) insertText(str string, kind string) {
+	runes := area.getRunes()
+	start, end := area.GetSelection()
+	insert := []rune(str)
+
+	if len(insert) == 0 && start == end {
+		return
+	}
+
+	area.pushUndo(kind)
+	text := string(runes[:start]) + string(insert) + string(runes[end:])
+	area.caret = start + len(insert)
+	area.anchor = area.caret
+	area.desiredX = -1
+	area.setText(text, true)
+	area.ensureCaretVisible()
+
+	return
+}
+
+func (area *TextArea) findWordStart(pos int) int {
+	runes := area.getRunes()
+	for pos > 0 && unicode.IsSpace(runes[pos-1]) {
+		pos--
+	}
+
+	for pos > 0 && !unicode.IsSpace(runes[pos-1]) {
+		pos--
+	}
+
+	return pos
+}
+
+func (area *TextArea) findWordEnd(pos int) int {
+	runes := area.getRunes()
+	for pos < len(runes) && unicode.IsSpace(runes[pos]) {
+		pos++
+	}
+
+	for pos < len(runes) && !unicode.IsSpace(runes[pos]) {
+		pos++
+	}
+
+	return pos
+}
+
+func (area *TextArea) getVisibleLineCount() int {
+	height := area.rect.H
+	if area.workArea != nil {
+		height = area.workArea.H
+	}
+
+	return int(math.Max(1, float64(height/area.getLineHeight())))
+}
+
+func (area *TextArea) onKeyDown(code int) {
+	if !area.enable {
+		return
+	}
+
+	shift := area.isShiftDown()
+	ctrl := area.isCtrlDown()
+	start, end := area.GetSelection()
+	line := area.getLineOf(area.caret)
+
+	switch code {
+	case keyevent.DOM_VK_LEFT:
+		if start != end && !shift {
+			area.moveCaret(start, false)
+		} else if ctrl {
+			area.moveCaret(area.findWordStart(area.caret), shift)
+		} else {
+			area.moveCaret(area.caret-1, shift)
+		}
+	case keyevent.DOM_VK_RIGHT:
+		if start != end && !shift {
+			area.moveCaret(end, false)
+		} else if ctrl {
+			area.moveCaret(area.findWordEnd(area.caret), shift)
+		} else {
+			area.moveCaret(area.caret+1, shift)
+		}
+	case keyevent.DOM_VK_UP:
+		area.moveCaretByLine(-1, shift)
+	case keyevent.DOM_VK_DOWN:
+		area.moveCaretByLine(1, shift)
+	case keyevent.DOM_VK_PAGE_UP:
+		area.moveCaretByLine(-area.getVisibleLineCount(), shift)
+	case keyevent.DOM_VK_PAGE_DOWN:
+		area.moveCaretByLine(area.getVisibleLineCount(), shift)
+	case keyevent.DOM_VK_HOME:
+		if ctrl {
+			area.moveCaret(0, shift)
+		} else {
+			area.moveCaret(area.lines[line].start, shift)
+		}
+	case keyevent.DOM_VK_END:
+		if ctrl {
+			area.moveCaret(len(area.getRunes()), shift)
+		} else {
+			area.moveCaret(area.getLineEnd(line), shift)
+		}
+	case keyevent.DOM_VK_RETURN:
+		area.insertText("\n", TEXT_AREA_EDIT_OTHER)
+	case keyevent.DOM_VK_TAB:
+		area.insertText(strings.Repeat(" ", area.tabSize), TEXT_AREA_EDIT_OTHER)
+	case keyevent.DOM_VK_BACK_SPACE:
+		if start == end && area.caret > 0 {
+			area.anchor = area.caret - 1
+		}
+		area.insertText("", TEXT_AREA_EDIT_OTHER)
+	case keyevent.DOM_VK_DELETE:
+		if start == end && area.caret < len(area.getRunes()) {
+			area.anchor = area.caret + 1
+		}
+		area.insertText("", TEXT_AREA_EDIT_OTHER)
+	case keyevent.DOM_VK_A:
+		if ctrl {
+			area.SelectAll()
+		}
+	case keyevent.DOM_VK_C:
+		if ctrl {
+			area.Copy()
+		}
+	case keyevent.DOM_VK_X:
+		if ctrl {
+			area.Cut()
+		}
+	case keyevent.DOM_VK_V:
+		if ctrl {
+			area.Paste()
+		}
+	case keyevent.DOM_VK_Z:
+		if ctrl && shift {
+			area.Redo()
+		} else if ctrl {
+			area.Undo()
+		}
+	case keyevent.DOM_VK_Y:
+		if ctrl {
+			area.Redo()
+		}
+	}
+
+	if area.keyDownHandler != nil {
+		area.keyDownHandler(code)
+	}
+
+	return
+}
+
+func (area *TextArea) onKeyPress(char rune) {
+	if !area.enable || area.isCtrlDown() || !unicode.IsPrint(char) {
+		return
+	}
+
+	area.insertText(string(char), TEXT_AREA_EDIT_TYPE)
+
+	return
+}
+
+func (area *TextArea) paintText(context canvas.Canvas2D) {
+	area.clampCaret()
+	lineHeight := area.getLineHeight()
+	yOffset := int(area.getYOffset())
+	height := area.rect.H
+	if area.workArea != nil {
+		height = area.workArea.H
+	}
+
+	context.Save()
+	context.BeginPath()
+	context.Rect(0, float64(yOffset), float64(area.content.rect.W), float64(height))
+	context.Clip()
+
+	context.SetFont(area.getFont())
+	context.SetTextAlign("left")
+	context.SetTextBaseline("middle")
+
+	first := int(math.Max(0, float64((yOffset-TEXT_AREA_PADDING)/lineHeight)))
+	last := int(math.Min(float64(len(area.lines)), float64(first+height/lineHeight+2)))
+	start, end := area.GetSelection()
+	textColor := area.getStyle("").TextColor
+
+	for i := first; i < last; i++ {
+		line := area.lines[i]
+		x := float64(TEXT_AREA_PADDING)
+		y := float64(TEXT_AREA_PADDING + i*lineHeight)
+		lineEnd := line.start + len(line.text)
+
+		if start < end && start <= lineEnd && end > line.start {
+			s := int(math.Max(float64(start), float64(line.start))) - line.start
+			e := int(math.Min(float64(end), float64(lineEnd))) - line.start
+			x0 := area.measure(context, line.text[:s])
+			x1 := area.measure(context, line.text[:e])
+			if end > lineEnd && i+1 < len(area.lines) && area.lines[i+1].start > lineEnd {
+				x1 += area.measure(context, []rune{' '})
+			}
+			context.SetFillStyle(EDIT_SELECTION_COLOR)
+			context.FillRect(x+x0, y, x1-x0, float64(lineHeight))
+		}
+
+		if len(line.text) > 0 {
+			context.SetFillStyle(textColor)
+			context.FillText(string(line.text), x, y+float64(lineHeight)/2, -1)
+		}
+	}
+
+	if area.editing {
+		i := area.getLineOf(area.caret)
+		x := math.Floor(float64(TEXT_AREA_PADDING) + area.getCaretX(context, area.caret))
+		y := float64(TEXT_AREA_PADDING + i*lineHeight + (lineHeight-area.fontSize-4)/2)
+		context.SetFillStyle(textColor)
+		context.FillRect(x, y, 1, float64(area.fontSize+4))
+	}
+	context.Restore()
+
+	return
+}
+
+func (area *TextArea) onContentPointerDown(point *structs.Point) {
+	if !area.enable {
+		return
+	}
+
+	if window := area.GetWindow(); window != nil {
+		window.SetFocus(area.Widget)
+	}
+
+	area.moveCaret(area.getPosAtPoint(point), area.isShiftDown())
+	area.selecting = true
+
+	return
+}
+
+func (area *TextArea) onContentPointerMove(point *structs.Point) {
+	if area.selecting && area.isPointerDown() {
+		area.moveCaret(area.getPosAtPoint(point), true)
+	}
+
+	return
+}
+
+func (area *TextArea) onContentDoubleClick(point *structs.Point) {
+	pos := area.getPosAtPoint(point)
+	runes := area.getRunes()
+	start, end := pos, pos
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start--
+	}
+
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+	area.Select(start, end)
+
+	return
+}
+
+func (content *textAreaContent) paintBackground(context canvas.Canvas2D) {
+	return
+}
+
+func (content *textAreaContent) paintSelf(context canvas.Canvas2D) {
+	content.area.paintText(context)
+
+	return
+}
+
+func (content *textAreaContent) onPointerDown(point *structs.Point) {
+	content.area.onContentPointerDown(point)
+	content.Widget.onPointerDown(point)
+
+	return
+}
+
+func (content *textAreaContent) onPointerMove(point *structs.Point) {
+	content.area.onContentPointerMove(point)
+
+	return
+}
+
+func (content *textAreaContent) onPointerUp(point *structs.Point) {
+	content.area.selecting = false
+	content.Widget.onPointerUp(point)
+
+	return
+}
+
+func (content *textAreaContent) onDoubleClick(point *structs.Point) {
+	content.area.onContentDoubleClick(point)
+	content.Widget.onDoubleClick(point)
+
+	return
+}
@@ -0,0 +1,166 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"math"
+	"testing"
+)
+
+// newTextAreaScene returns a focused TextArea 120 wide at 10,10 showing
+// text, with the caret at the end.
+func newTextAreaScene(text string) (typist, *TextArea) {
+	var area *TextArea
+	k := newScene(200, 160, func(win *Window) {
+		area = NewTextArea(win.Widget, 10, 10, 120, 140)
+	})
+	k.click(60, 140)
+	area.SetText(text, false)
+
+	return k, area
+}
+
+func TestTextAreaUndoTyping(t *testing.T) {
+	k, area := newTextAreaScene("")
+	k.typeText("abc")
+	k.key(keyevent.DOM_VK_LEFT)
+	k.typeText("XY")
+	k.key(keyevent.DOM_VK_RETURN)
+	if text := area.GetText(); text != "abXY\nc" {
+		t.Fatalf("typed %q", text)
+	}
+
+	undo := func() {
+		k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_Z)
+	}
+	redo := func() {
+		k.hold(keyevent.DOM_VK_SHIFT, undo)
+	}
+
+	steps := []struct {
+		name  string
+		press func()
+		text  string
+		caret int
+	}{
+		{"undo return", undo, "abXYc", 4},
+		{"undo typing after moving", undo, "abc", 2},
+		{"undo first typing", undo, "", 0},
+		{"undo nothing", undo, "", 0},
+		{"redo with ctrl+y", func() { k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_Y) }, "abc", 2},
+		{"redo with ctrl+shift+z", redo, "abXYc", 4},
+	}
+	for _, step := range steps {
+		step.press()
+		if text, caret := area.GetText(), area.GetCaret(); text != step.text || caret != step.caret {
+			t.Errorf("%s: %q caret %d, want %q caret %d", step.name, text, caret, step.text, step.caret)
+		}
+	}
+
+	k.typeText("!")
+	if area.CanRedo() {
+		t.Errorf("typing after undo kept the redo stack")
+	}
+}
+
+func TestTextAreaUndoPaste(t *testing.T) {
+	k, area := newTextAreaScene("hello world")
+	k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_LEFT, keyevent.DOM_VK_LEFT, keyevent.DOM_VK_LEFT,
+		keyevent.DOM_VK_LEFT, keyevent.DOM_VK_LEFT)
+	k.m.SetClipboardText("there\nagain")
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_V)
+	if text := area.GetText(); text != "hello there\nagain" {
+		t.Fatalf("pasted %q", text)
+	}
+
+	k.typeText("!")
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_Z)
+	if text := area.GetText(); text != "hello there\nagain" {
+		t.Errorf("undo after paste and typing: %q, want the pasted text", text)
+	}
+
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_Z)
+	if text, selected := area.GetText(), area.GetSelectedText(); text != "hello world" || selected != "world" {
+		t.Errorf("undo of the paste: %q with %q selected, want %q with %q", text, selected, "hello world", "world")
+	}
+	if area.CanUndo() {
+		t.Errorf("undo stack not empty after undoing every edit")
+	}
+}
+
+func TestTextAreaUpDownWrappedLines(t *testing.T) {
+	k, area := newTextAreaScene("aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa")
+	lines := area.GetLineCount()
+	if lines < 3 {
+		t.Fatalf("text wrapped to %d lines, want at least 3", lines)
+	}
+
+	context := k.m.getCanvas2D()
+	context.SetFont(area.getFont())
+	charWidth := area.measure(context, []rune("a"))
+
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_HOME)
+	k.key(keyevent.DOM_VK_RIGHT, keyevent.DOM_VK_RIGHT)
+	x := area.getCaretX(context, area.GetCaret())
+
+	for i := 1; i < lines; i++ {
+		k.key(keyevent.DOM_VK_DOWN)
+		if line := area.GetCaretLine(); line != i {
+			t.Fatalf("down %d: caret on line %d", i, line)
+		}
+		if caretX := area.getCaretX(context, area.GetCaret()); math.Abs(caretX-x) > charWidth/2 {
+			t.Errorf("down %d: caret at x %v, want near %v", i, caretX, x)
+		}
+	}
+
+	k.key(keyevent.DOM_VK_DOWN)
+	if caret := area.GetCaret(); caret != len(area.GetText()) {
+		t.Errorf("down from the last line: caret %d, want the end", caret)
+	}
+
+	for i := 0; i < lines; i++ {
+		k.key(keyevent.DOM_VK_UP)
+	}
+	if caret := area.GetCaret(); caret != 0 {
+		t.Errorf("up to the first line and beyond: caret %d, want 0", caret)
+	}
+
+	k.key(keyevent.DOM_VK_END)
+	if end := area.GetCaret(); end != area.lines[1].start-1 {
+		t.Errorf("end of a wrapped line: caret %d, want %d, before the wrapped space", end, area.lines[1].start-1)
+	}
+	if line := area.GetCaretLine(); line != 0 {
+		t.Errorf("end of a wrapped line: caret on line %d", line)
+	}
+}
+
+func TestTextAreaSelectionAcrossLines(t *testing.T) {
+	k, area := newTextAreaScene("one two three four five six seven\nlast")
+	if area.GetLineCount() < 3 {
+		t.Fatalf("text wrapped to %d lines, want at least 3", area.GetLineCount())
+	}
+
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_HOME)
+	k.key(keyevent.DOM_VK_RIGHT)
+	k.keyWith(keyevent.DOM_VK_SHIFT, keyevent.DOM_VK_DOWN, keyevent.DOM_VK_DOWN)
+
+	start, end := area.GetSelection()
+	if start != 1 || area.GetCaretLine() != 2 {
+		t.Fatalf("shift+down twice selected [%d, %d) ending on line %d", start, end, area.GetCaretLine())
+	}
+	if want := string([]rune(area.GetText())[start:end]); area.GetSelectedText() != want {
+		t.Errorf("selected %q, want %q", area.GetSelectedText(), want)
+	}
+
+	k.hold(keyevent.DOM_VK_SHIFT, func() {
+		k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_END)
+	})
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_C)
+	if clip := k.m.GetClipboardText(); clip != area.GetText()[1:] {
+		t.Errorf("copied %q, want the text after the first character", clip)
+	}
+
+	k.keyWith(keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_X)
+	if text := area.GetText(); text != "o" || area.GetLineCount() != 1 {
+		t.Errorf("cutting across lines left %q on %d lines", text, area.GetLineCount())
+	}
+}
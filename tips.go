@@ -0,0 +1,239 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/image"
+	"github.com/Luncher/gwk/pkg/structs"
+	"github.com/Luncher/gwk/pkg/theme"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	TIPS_DELAY       = 600 * time.Millisecond
+	TIPS_PADDING     = 6
+	TIPS_OFFSET      = 20
+	TIPS_MAX_WIDTH   = 320
+	TIPS_LINE_HEIGHT = 20
+	TIPS_FILL_COLOR  = "#FFFFE1"
+	TIPS_LINE_COLOR  = "#767676"
+	TIPS_TEXT_COLOR  = "#000000"
+)
+
+// TipsContent paints tips richer than the text of SetTips, like images or
+// several fonts.
+type TipsContent interface {
+	// GetTipsSize returns the size the content needs, padding excluded.
+	GetTipsSize(context canvas.Canvas2D) (w, h int)
+	// PaintTips paints the content with its top left corner at the origin.
+	PaintTips(context canvas.Canvas2D, w, h int)
+}
+
+// SetTipsContent shows content as the tips of the widget, in place of the
+// text of SetTips.
+func (w *Widget) SetTipsContent(content TipsContent) *Widget {
+	w.tipsContent = content
+
+	return w
+}
+
+func (w *Widget) GetTipsContent() TipsContent {
+	return w.tipsContent
+}
+
+func (w *Widget) hasTips() bool {
+	return len(w.tips) > 0 || w.tipsContent != nil
+}
+
+// tipsManager shows the tips of the widget under the pointer once the
+// pointer rested on it for the delay. Pressing a button or scrolling hides
+// them until the pointer moves to another widget.
+type tipsManager struct {
+	widget     *Widget
+	point      structs.Point
+	restTime   time.Time
+	delay      time.Duration
+	shown      bool
+	suppressed bool
+}
+
+// SetTipsDelay sets how long the pointer rests on a widget before its tips
+// show.
+func (manager *WindowManager) SetTipsDelay(delay time.Duration) *WindowManager {
+	manager.tips.delay = delay
+
+	return manager
+}
+
+func (manager *WindowManager) GetTipsDelay() time.Duration {
+	return manager.tips.delay
+}
+
+// GetTipsWidget returns the widget whose tips are shown, or nil.
+func (manager *WindowManager) GetTipsWidget() *Widget {
+	if tips := &manager.tips; tips.shown && !tips.suppressed {
+		return tips.widget
+	}
+
+	return nil
+}
+
+// findTipsWidget follows the widgets under the pointer down from the target
+// window and returns the deepest one with tips.
+func (manager *WindowManager) findTipsWidget() *Widget {
+	if manager.target == nil {
+		return nil
+	}
+
+	var found *Widget
+	for w := manager.target.Widget; w != nil; w = w.target {
+		if w != manager.target.Widget && w.state != STATE_OVER {
+			break
+		}
+		if w.visible && w.hasTips() {
+			found = w
+		}
+	}
+
+	return found
+}
+
+// updateTips restarts the delay when the pointer moves before the tips show,
+// and hides them when it leaves their widget.
+func (manager *WindowManager) updateTips() {
+	tips := &manager.tips
+	widget := manager.findTipsWidget()
+	if widget != tips.widget {
+		manager.setTipsWidget(widget)
+	}
+
+	if !tips.shown && tips.widget != nil {
+		tips.point = manager.lastPointerPoint
+		tips.restTime = time.Now()
+		manager.PostRedraw()
+	}
+
+	return
+}
+
+func (manager *WindowManager) setTipsWidget(widget *Widget) {
+	tips := &manager.tips
+	if tips.shown {
+		manager.PostRedraw()
+	}
+
+	tips.widget = widget
+	tips.shown = false
+	tips.suppressed = false
+
+	return
+}
+
+func (manager *WindowManager) hideTips() {
+	tips := &manager.tips
+	if tips.shown {
+		manager.PostRedraw()
+	}
+
+	tips.shown = false
+	tips.suppressed = tips.widget != nil
+
+	return
+}
+
+// drawTips waits, through the redraw loop, for the pointer to rest for the
+// delay and then paints the tips next to it.
+func (manager *WindowManager) drawTips(context canvas.Canvas2D) {
+	tips := &manager.tips
+	widget := tips.widget
+	if widget == nil || tips.suppressed {
+		return
+	}
+
+	if widget.parent == nil || !widget.visible || !widget.hasTips() {
+		manager.setTipsWidget(nil)
+		return
+	}
+
+	if !tips.shown {
+		if time.Since(tips.restTime) < tips.delay {
+			manager.keepAnimating()
+			return
+		}
+		tips.shown = true
+	}
+
+	hideTipsCanvas()
+	context.Save()
+	paintTips(context, widget, &tips.point, manager.w, manager.h)
+	context.Restore()
+
+	return
+}
+
+// paintTips paints the tips of widget under point, or above it when there is
+// no room below, keeping them inside a canvas of size w x h.
+func paintTips(context canvas.Canvas2D, widget *Widget, point *structs.Point, w, h int) {
+	widgetStyle := widget.getStyle(STATE_NORMAL)
+	tipsStyle := theme.Get(TYPE_TIPS, false).StateNormal
+
+	context.SetFont(tipsStyle.Font)
+
+	var lines []string
+	var contentW, contentH int
+	if content := widget.tipsContent; content != nil {
+		contentW, contentH = content.GetTipsSize(context)
+	} else {
+		lines = layoutText(context, 0, widget.tips, TIPS_MAX_WIDTH-2*TIPS_PADDING, 0)
+		for i, line := range lines {
+			lines[i] = strings.TrimSpace(line)
+			lineW := int(math.Ceil(context.MeasureText(lines[i]).Width))
+			contentW = int(math.Max(float64(contentW), float64(lineW)))
+		}
+		contentH = len(lines) * TIPS_LINE_HEIGHT
+	}
+
+	tipsW := contentW + 2*TIPS_PADDING
+	tipsH := contentH + 2*TIPS_PADDING
+	x := point.X
+	y := point.Y + TIPS_OFFSET
+	if y+tipsH > h {
+		y = point.Y - tipsH - 4
+	}
+	x = int(math.Max(0, math.Min(float64(x), float64(w-tipsW))))
+	y = int(math.Max(0, math.Min(float64(y), float64(h-tipsH))))
+
+	context.Translate(float64(x), float64(y))
+
+	bgImage := tipsStyle.BgImage
+	if widgetStyle.BgImageTips != nil {
+		bgImage = widgetStyle.BgImageTips
+	}
+	if bgImage != nil && bgImage.GetImage() != nil {
+		bgImage.Draw(context, image.DISPLAY_9PATCH, 0, 0, tipsW, tipsH)
+	} else {
+		context.SetFillStyle(colorOr(widgetStyle.TipsFillColor, colorOr(tipsStyle.FillColor, TIPS_FILL_COLOR)))
+		context.FillRect(0, 0, float64(tipsW), float64(tipsH))
+		context.SetLineWidth(1)
+		context.SetStrokeStyle(colorOr(widgetStyle.TipsLineColor, colorOr(tipsStyle.LineColor, TIPS_LINE_COLOR)))
+		context.BeginPath()
+		context.Rect(0.5, 0.5, float64(tipsW)-1, float64(tipsH)-1)
+		context.Stroke()
+	}
+
+	context.Translate(TIPS_PADDING, TIPS_PADDING)
+	if content := widget.tipsContent; content != nil {
+		content.PaintTips(context, contentW, contentH)
+		return
+	}
+
+	context.SetFillStyle(colorOr(widgetStyle.TipsTextColor, colorOr(tipsStyle.TextColor, TIPS_TEXT_COLOR)))
+	context.SetTextAlign("left")
+	context.SetTextBaseline("middle")
+	for i, line := range lines {
+		context.FillText(line, 0, float64(i*TIPS_LINE_HEIGHT+TIPS_LINE_HEIGHT/2), -1)
+	}
+
+	return
+}
@@ -0,0 +1,182 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/event"
+	"github.com/Luncher/gwk/pkg/structs"
+	"image"
+	"testing"
+	"time"
+)
+
+// boxTips is a TipsContent filling w x h with magenta.
+type boxTips struct {
+	w, h int
+}
+
+func (content boxTips) GetTipsSize(context canvas.Canvas2D) (w, h int) {
+	return content.w, content.h
+}
+
+func (content boxTips) PaintTips(context canvas.Canvas2D, w, h int) {
+	context.SetFillStyle("#FF00FF")
+	context.FillRect(0, 0, float64(w), float64(h))
+
+	return
+}
+
+// restTips lets the delay of the tips under the pointer run out and paints.
+func restTips(k typist) *image.RGBA {
+	k.m.tips.restTime = time.Now().Add(-k.m.GetTipsDelay())
+
+	return k.m.Snapshot()
+}
+
+// findMagenta returns the bounds of the magenta pixels of frame.
+func findMagenta(frame *image.RGBA) image.Rectangle {
+	found := image.Rectangle{}
+	bounds := frame.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if c := frame.RGBAAt(x, y); c.R == 255 && c.G == 0 && c.B == 255 {
+				found = found.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	return found
+}
+
+// newTipsScene returns the buttons "save" and "load" with tips, side by side
+// at 10,10 and 100,10, and a button without tips at 190,10.
+func newTipsScene() (typist, *Button, *Button) {
+	var save, load *Button
+	k := newScene(300, 200, func(win *Window) {
+		save = NewButton(win.Widget, 10, 10, 80, 30)
+		save.SetTips("Save the file")
+		load = NewButton(win.Widget, 100, 10, 80, 30)
+		load.SetTips("Load a file")
+		NewButton(win.Widget, 190, 10, 80, 30)
+	})
+
+	return k, save, load
+}
+
+func TestTipsDelay(t *testing.T) {
+	k, save, load := newTipsScene()
+	k.m.SetTipsDelay(time.Hour)
+
+	k.pointer(event.EVENT_POINTER_MOVE, 30, 20)
+	k.m.Snapshot()
+	if k.m.GetTipsWidget() != nil {
+		t.Fatalf("tips shown before the delay")
+	}
+
+	// Moving restarts the delay.
+	k.m.tips.restTime = time.Now().Add(-time.Hour)
+	k.pointer(event.EVENT_POINTER_MOVE, 40, 20)
+	k.m.Snapshot()
+	if k.m.GetTipsWidget() != nil {
+		t.Fatalf("tips shown although the pointer moved")
+	}
+
+	restTips(k)
+	if k.m.GetTipsWidget() != save.Widget {
+		t.Fatalf("tips of %v shown after the delay, want those of save", k.m.GetTipsWidget())
+	}
+
+	// Once shown the tips follow moves on their widget.
+	k.pointer(event.EVENT_POINTER_MOVE, 50, 25)
+	k.m.Snapshot()
+	if k.m.GetTipsWidget() != save.Widget {
+		t.Fatalf("tips hidden by a move on their widget")
+	}
+
+	k.pointer(event.EVENT_POINTER_MOVE, 120, 20)
+	k.m.Snapshot()
+	if k.m.GetTipsWidget() != nil {
+		t.Fatalf("tips of the new widget shown before the delay")
+	}
+	restTips(k)
+	if k.m.GetTipsWidget() != load.Widget {
+		t.Fatalf("tips of %v shown, want those of load", k.m.GetTipsWidget())
+	}
+
+	k.pointer(event.EVENT_POINTER_MOVE, 210, 20)
+	restTips(k)
+	if k.m.GetTipsWidget() != nil {
+		t.Fatalf("tips shown over a widget without tips")
+	}
+}
+
+func TestTipsHidden(t *testing.T) {
+	hides := []struct {
+		name string
+		hide func(k typist)
+	}{
+		{"press", func(k typist) {
+			k.click(40, 20)
+		}},
+		{"wheel", func(k typist) {
+			k.m.Inject(event.NewWheelEvent(10))
+		}},
+	}
+	for _, hide := range hides {
+		k, save, load := newTipsScene()
+		k.pointer(event.EVENT_POINTER_MOVE, 30, 20)
+		restTips(k)
+
+		hide.hide(k)
+		if k.m.GetTipsWidget() != nil {
+			t.Fatalf("%s: tips still shown", hide.name)
+		}
+
+		// They stay hidden while the pointer stays on the widget.
+		k.pointer(event.EVENT_POINTER_MOVE, 50, 25)
+		restTips(k)
+		if k.m.GetTipsWidget() != nil {
+			t.Fatalf("%s: tips shown again on the same widget", hide.name)
+		}
+
+		// Another widget, and coming back, shows them again.
+		k.pointer(event.EVENT_POINTER_MOVE, 120, 20)
+		restTips(k)
+		if k.m.GetTipsWidget() != load.Widget {
+			t.Fatalf("%s: tips of another widget not shown", hide.name)
+		}
+		k.pointer(event.EVENT_POINTER_MOVE, 30, 20)
+		restTips(k)
+		if k.m.GetTipsWidget() != save.Widget {
+			t.Fatalf("%s: tips not shown again on coming back", hide.name)
+		}
+	}
+}
+
+func TestTipsPlacement(t *testing.T) {
+	const w, h = 300, 200
+	content := boxTips{60, 40}
+	tipsW := content.w + 2*TIPS_PADDING
+	tipsH := content.h + 2*TIPS_PADDING
+
+	cases := []struct {
+		name  string
+		point structs.Point
+		x, y  int
+	}{
+		{"below the pointer", structs.Point{X: 50, Y: 25}, 50, 25 + TIPS_OFFSET},
+		{"above near the bottom", structs.Point{X: 50, Y: 190}, 50, 190 - tipsH - 4},
+		{"inside near the right", structs.Point{X: 290, Y: 25}, w - tipsW, 25 + TIPS_OFFSET},
+		{"in the corner", structs.Point{X: 290, Y: 190}, w - tipsW, 190 - tipsH - 4},
+	}
+	for _, c := range cases {
+		k := newScene(w, h, func(win *Window) {
+			NewButton(win.Widget, 0, 0, w, h).SetTipsContent(content)
+		})
+		k.pointer(event.EVENT_POINTER_MOVE, c.point.X, c.point.Y)
+		box := findMagenta(restTips(k))
+		want := image.Rect(c.x+TIPS_PADDING, c.y+TIPS_PADDING, c.x+TIPS_PADDING+content.w, c.y+TIPS_PADDING+content.h)
+		if box != want {
+			t.Errorf("%s: content painted at %v, want %v", c.name, box, want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/structs"
+)
+
+const (
+	TOOLBAR_PADDING         = 2
+	TOOLBAR_SPACING         = 2
+	TOOLBAR_SEPARATOR_WIDTH = 9
+	TOOLBAR_CHEVRON_WIDTH   = 16
+	TOOLBAR_FILL_COLOR      = "#F0F0F0"
+)
+
+// toolbarChevron is the button at the right end of a Toolbar too narrow for
+// its buttons. It opens a menu of the buttons that did not fit.
+type toolbarChevron struct {
+	*IconButton
+	toolbar *Toolbar
+}
+
+func (chevron *toolbarChevron) onPointerUp(point *structs.Point) {
+	if chevron.enable && chevron.isClicked() {
+		chevron.toolbar.openOverflowMenu()
+	}
+	chevron.Widget.onPointerUp(point)
+
+	return
+}
+
+func (chevron *toolbarChevron) paintSelf(context canvas.Canvas2D) {
+	style := chevron.getStyle("")
+	cx := float64(chevron.rect.W) / 2
+	cy := float64(chevron.rect.H) / 2
+
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(style.TextColor)
+	context.BeginPath()
+	for _, x := range []float64{cx - 3, cx + 1} {
+		context.MoveTo(x, cy-3)
+		context.LineTo(x+3, cy)
+		context.LineTo(x, cy+3)
+	}
+	context.Stroke()
+
+	return
+}
+
+// Toolbar lays out icon buttons and separators from left to right, each
+// button as wide as the toolbar is high. The buttons that do not fit are
+// hidden behind a chevron at the right end, which lists them in a menu.
+type Toolbar struct {
+	*Widget
+	buttons  []*IconButton
+	overflow []*IconButton
+	chevron  *toolbarChevron
+	menu     *Menu
+}
+
+func NewToolbar(parent *Widget, x, y, w, h float32) *Toolbar {
+	toolbar := &Toolbar{
+		Widget: NewWidget(TYPE_TOOLBAR, parent, x, y, w, h),
+	}
+	toolbar.I = toolbar
+
+	toolbar.chevron = &toolbarChevron{
+		IconButton: NewIconButton(toolbar.Widget, 0, 0, 0, 0),
+		toolbar:    toolbar,
+	}
+	toolbar.chevron.I = toolbar.chevron
+	toolbar.chevron.SetVisible(false)
+
+	return toolbar
+}
+
+// AddButton appends a button showing icon, with text as its tips and as its
+// label in the overflow menu.
+func (toolbar *Toolbar) AddButton(icon, text string, onClicked ClickedHandler) *IconButton {
+	button := NewIconButton(toolbar.Widget, 0, 0, 0, 0)
+	button.SetIcon(icon)
+	button.SetText(text, false)
+	button.SetTips(text)
+	button.SetClickedHandler(onClicked)
+	toolbar.addButton(button)
+
+	return button
+}
+
+// AddToggleButton appends a button staying pressed while it is checked.
+func (toolbar *Toolbar) AddToggleButton(icon, text string, checked bool, onClicked ClickedHandler) *IconButton {
+	return toolbar.AddButton(icon, text, onClicked).SetToggle(true).SetChecked(checked, false)
+}
+
+func (toolbar *Toolbar) AddSeparator() *IconButton {
+	button := NewIconButton(toolbar.Widget, 0, 0, 0, 0)
+	button.separator = true
+	toolbar.addButton(button)
+
+	return button
+}
+
+// RemoveButton removes button, and takes it out of its group.
+func (toolbar *Toolbar) RemoveButton(button *IconButton) *Toolbar {
+	for i, iter := range toolbar.buttons {
+		if iter == button {
+			toolbar.buttons = append(toolbar.buttons[:i], toolbar.buttons[i+1:]...)
+			if button.group != nil {
+				button.group.Remove(button)
+			}
+			button.Remove()
+			toolbar.layout()
+			toolbar.PostRedraw()
+			break
+		}
+	}
+
+	return toolbar
+}
+
+func (toolbar *Toolbar) GetButtons() []*IconButton {
+	return toolbar.buttons
+}
+
+// GetOverflowButtons returns the buttons hidden behind the chevron.
+func (toolbar *Toolbar) GetOverflowButtons() []*IconButton {
+	return toolbar.overflow
+}
+
+// Resize resizes the toolbar and moves the buttons that no longer fit behind
+// the chevron.
+func (toolbar *Toolbar) Resize(w, h int) *Toolbar {
+	toolbar.Widget.Resize(w, h)
+	toolbar.layout()
+
+	return toolbar
+}
+
+// addButton moves button, already a child of the toolbar, before the chevron.
+func (toolbar *Toolbar) addButton(button *IconButton) {
+	children := toolbar.children
+	n := len(children)
+	copy(children[n-2:], []*Widget{button.Widget, toolbar.chevron.Widget})
+
+	toolbar.buttons = append(toolbar.buttons, button)
+	toolbar.layout()
+
+	return
+}
+
+// getButtonWidth returns the width of button, without spacing.
+func (toolbar *Toolbar) getButtonWidth(button *IconButton) int {
+	if button.separator {
+		return TOOLBAR_SEPARATOR_WIDTH
+	}
+
+	return toolbar.rect.H - 2*TOOLBAR_PADDING
+}
+
+// layout places the buttons from the left and hides those past the chevron
+// when they do not all fit, with the separators left at the end.
+func (toolbar *Toolbar) layout() {
+	size := toolbar.rect.H - 2*TOOLBAR_PADDING
+	right := toolbar.rect.W - TOOLBAR_PADDING
+
+	total := 0
+	for _, button := range toolbar.buttons {
+		total += toolbar.getButtonWidth(button) + TOOLBAR_SPACING
+	}
+	if total-TOOLBAR_SPACING > right-TOOLBAR_PADDING {
+		right -= TOOLBAR_CHEVRON_WIDTH + TOOLBAR_SPACING
+	}
+
+	toolbar.overflow = nil
+	x := TOOLBAR_PADDING
+	for _, button := range toolbar.buttons {
+		w := toolbar.getButtonWidth(button)
+		fits := len(toolbar.overflow) == 0 && x+w <= right
+		button.SetVisible(fits)
+		if fits {
+			button.Move(x, TOOLBAR_PADDING)
+			button.Resize(w, size)
+			x += w + TOOLBAR_SPACING
+		} else {
+			toolbar.overflow = append(toolbar.overflow, button)
+		}
+	}
+
+	for i := len(toolbar.buttons) - len(toolbar.overflow) - 1; i >= 0; i-- {
+		if button := toolbar.buttons[i]; button.separator {
+			button.SetVisible(false)
+		} else {
+			break
+		}
+	}
+
+	chevron := toolbar.chevron
+	chevron.SetVisible(len(toolbar.overflow) > 0)
+	chevron.Move(toolbar.rect.W-TOOLBAR_PADDING-TOOLBAR_CHEVRON_WIDTH, TOOLBAR_PADDING)
+	chevron.Resize(TOOLBAR_CHEVRON_WIDTH, size)
+
+	return
+}
+
+// openOverflowMenu lists the hidden buttons in a menu below the chevron.
+// Choosing an entry does what clicking the button does.
+func (toolbar *Toolbar) openOverflowMenu() {
+	if toolbar.menu != nil && toolbar.menu.IsShown() {
+		toolbar.menu.Dismiss()
+	}
+
+	menu := NewMenu()
+	for _, button := range toolbar.overflow {
+		button := button
+		if button.separator {
+			if n := len(menu.items); n > 0 && !menu.items[n-1].separator {
+				menu.AddSeparator()
+			}
+			continue
+		}
+
+		onClicked := func(*Widget, *structs.Point) {
+			button.activate()
+		}
+
+		var item *MenuItem
+		if button.toggle {
+			item = menu.AddCheckItem(button.GetText(), button.checked, onClicked)
+		} else {
+			item = menu.AddItem(button.GetText(), onClicked)
+		}
+		item.SetEnable(button.enable)
+	}
+
+	if n := len(menu.items); n > 0 && menu.items[n-1].separator {
+		menu.items[n-1].SetVisible(false)
+	}
+
+	if len(menu.items) > 0 {
+		p := toolbar.chevron.GetAbsPosition()
+		menu.Popup(p.X, p.Y+toolbar.chevron.rect.H)
+		toolbar.menu = menu
+	}
+
+	return
+}
+
+func (toolbar *Toolbar) relayout(context canvas.Canvas2D, force bool) {
+	toolbar.layout()
+	toolbar.Widget.relayout(context, force)
+
+	return
+}
+
+func (toolbar *Toolbar) paintBackground(context canvas.Canvas2D) {
+	style := toolbar.getStyle("")
+	w := float64(toolbar.rect.W)
+	h := float64(toolbar.rect.H)
+
+	context.SetFillStyle(colorOr(style.FillColor, TOOLBAR_FILL_COLOR))
+	context.FillRect(0, 0, w, h)
+	context.SetLineWidth(1)
+	context.SetStrokeStyle(colorOr(style.LineColor, MENU_LINE_COLOR))
+	context.BeginPath()
+	context.MoveTo(0, h-0.5)
+	context.LineTo(w, h-0.5)
+	context.Stroke()
+
+	return
+}
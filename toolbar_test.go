@@ -0,0 +1,193 @@
+package gwk
+
+import (
+	"fmt"
+	"github.com/Luncher/gwk/pkg/structs"
+	"reflect"
+	"testing"
+)
+
+// newToolbarScene returns a Toolbar 200x32, room for six buttons, filled by
+// build. clicked gets the text of the buttons clicked.
+func newToolbarScene(build func(toolbar *Toolbar, clicked *[]string)) (typist, *Toolbar, *[]string) {
+	var toolbar *Toolbar
+	clicked := &[]string{}
+	k := newScene(300, 200, func(win *Window) {
+		toolbar = NewToolbar(win.Widget, 0, 0, 200, 32)
+		build(toolbar, clicked)
+	})
+
+	return k, toolbar, clicked
+}
+
+// addToolbarButtons appends the buttons text0 up to textn-1.
+func addToolbarButtons(toolbar *Toolbar, clicked *[]string, text string, n int) {
+	for i := 0; i < n; i++ {
+		text := fmt.Sprintf("%s%d", text, i)
+		toolbar.AddButton("", text, func(*Widget, *structs.Point) {
+			*clicked = append(*clicked, text)
+		})
+	}
+
+	return
+}
+
+// getButtonTexts returns the texts of buttons.
+func getButtonTexts(buttons []*IconButton) []string {
+	texts := []string{}
+	for _, button := range buttons {
+		texts = append(texts, button.GetText())
+	}
+
+	return texts
+}
+
+// clickMenuItem clicks item of a shown menu.
+func clickMenuItem(k typist, item *MenuItem) {
+	p := item.GetAbsPosition()
+	k.click(p.X+item.rect.W/2, p.Y+item.rect.H/2)
+
+	return
+}
+
+func TestToolbarOverflow(t *testing.T) {
+	k, toolbar, clicked := newToolbarScene(func(toolbar *Toolbar, clicked *[]string) {
+		addToolbarButtons(toolbar, clicked, "b", 6)
+	})
+	if len(toolbar.GetOverflowButtons()) != 0 || toolbar.chevron.visible {
+		t.Fatalf("six buttons overflowed a toolbar with room for them")
+	}
+
+	addToolbarButtons(toolbar, clicked, "c", 2)
+	if texts := getButtonTexts(toolbar.GetOverflowButtons()); !reflect.DeepEqual(texts, []string{"c0", "c1"}) {
+		t.Fatalf("overflow %v, want the buttons past the chevron", texts)
+	}
+	for i, button := range toolbar.GetButtons() {
+		if button.visible != (i < 6) {
+			t.Fatalf("button %d visible %v", i, button.visible)
+		}
+	}
+	if !toolbar.chevron.visible {
+		t.Fatalf("no chevron for the overflow")
+	}
+
+	// The chevron lists the hidden buttons, and choosing one clicks it.
+	k.m.Snapshot()
+	k.click(190, 16)
+	if toolbar.menu == nil || !toolbar.menu.IsShown() {
+		t.Fatalf("clicking the chevron did not open the overflow menu")
+	}
+	items := toolbar.menu.GetItems()
+	texts := []string{}
+	for _, item := range items {
+		texts = append(texts, item.GetText())
+	}
+	if !reflect.DeepEqual(texts, []string{"c0", "c1"}) {
+		t.Fatalf("overflow menu lists %v", texts)
+	}
+	k.m.Snapshot()
+	clickMenuItem(k, items[1])
+	if !reflect.DeepEqual(*clicked, []string{"c1"}) || toolbar.menu.IsShown() {
+		t.Fatalf("choosing c1 clicked %v, menu shown %v", *clicked, toolbar.menu.IsShown())
+	}
+
+	toolbar.Resize(300, 32)
+	if len(toolbar.GetOverflowButtons()) != 0 || toolbar.chevron.visible {
+		t.Fatalf("overflow %v once the toolbar is wide enough", getButtonTexts(toolbar.GetOverflowButtons()))
+	}
+}
+
+func TestToolbarOverflowSeparators(t *testing.T) {
+	k, toolbar, _ := newToolbarScene(func(toolbar *Toolbar, clicked *[]string) {
+		addToolbarButtons(toolbar, clicked, "b", 5)
+		toolbar.AddSeparator()
+		addToolbarButtons(toolbar, clicked, "c", 1)
+		toolbar.AddSeparator()
+		toolbar.AddToggleButton("", "bold", false, nil)
+	})
+
+	// The separator left at the end of the visible buttons is hidden.
+	buttons := toolbar.GetButtons()
+	if buttons[5].visible || !buttons[4].visible {
+		t.Fatalf("trailing separator visible %v", buttons[5].visible)
+	}
+
+	k.click(190, 16)
+	items := toolbar.menu.GetItems()
+	if len(items) != 3 || !items[1].IsSeparator() || !items[2].IsCheckable() {
+		t.Fatalf("overflow menu has %d items, want c0, a separator and bold", len(items))
+	}
+
+	// Choosing a toggle button from the menu checks it.
+	k.m.Snapshot()
+	clickMenuItem(k, items[2])
+	if !buttons[8].IsChecked() {
+		t.Fatalf("choosing bold from the menu did not check it")
+	}
+	k.click(190, 16)
+	if !toolbar.menu.GetItems()[2].IsChecked() {
+		t.Fatalf("the menu does not show bold checked")
+	}
+}
+
+func TestButtonGroup(t *testing.T) {
+	var group *ButtonGroup
+	k, toolbar, _ := newToolbarScene(func(toolbar *Toolbar, clicked *[]string) {
+		addToolbarButtons(toolbar, clicked, "b", 3)
+		group = NewButtonGroup(toolbar.GetButtons()...)
+	})
+	buttons := toolbar.GetButtons()
+	changed := []string{}
+	group.SetChangedHandler(func(value interface{}) {
+		changed = append(changed, value.(*IconButton).GetText())
+	})
+	clickButton := func(i int) {
+		k.click(buttons[i].rect.X+buttons[i].rect.W/2, buttons[i].rect.Y+buttons[i].rect.H/2)
+	}
+
+	steps := []struct {
+		click   int
+		checked int
+		changed []string
+	}{
+		{0, 0, []string{"b0"}},
+		{2, 2, []string{"b0", "b2"}},
+		// Clicking the checked button leaves it checked.
+		{2, 2, []string{"b0", "b2"}},
+		{1, 1, []string{"b0", "b2", "b1"}},
+	}
+	for i, step := range steps {
+		clickButton(step.click)
+		if group.GetCheckedIndex() != step.checked || !reflect.DeepEqual(changed, step.changed) {
+			t.Fatalf("click %d: checked %d, changes %v", i, group.GetCheckedIndex(), changed)
+		}
+		for j, button := range buttons {
+			if button.IsChecked() != (j == step.checked) {
+				t.Fatalf("click %d: button %d checked %v", i, j, button.IsChecked())
+			}
+		}
+	}
+
+	group.SetCheckedIndex(0, false)
+	if group.GetChecked() != buttons[0] || buttons[1].IsChecked() || len(changed) != 3 {
+		t.Fatalf("SetCheckedIndex: checked %d, changes %v", group.GetCheckedIndex(), changed)
+	}
+
+	// Adding a checked button unchecks the others.
+	extra := toolbar.AddToggleButton("", "extra", true, nil)
+	group.Add(extra)
+	if group.GetChecked() != extra || buttons[0].IsChecked() {
+		t.Fatalf("adding a checked button left %d checked", group.GetCheckedIndex())
+	}
+
+	// A button taken out of the group toggles on its own.
+	group.Remove(buttons[2])
+	clickButton(2)
+	if !buttons[2].IsChecked() || !extra.IsChecked() {
+		t.Fatalf("a removed button still unchecks the group")
+	}
+	clickButton(2)
+	if buttons[2].IsChecked() {
+		t.Fatalf("a removed button did not uncheck on a second click")
+	}
+}
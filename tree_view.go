@@ -0,0 +1,652 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/canvas"
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"github.com/Luncher/gwk/pkg/structs"
+	"math"
+)
+
+type TreeDropPosition int
+
+const (
+	TREE_DROP_NONE TreeDropPosition = iota
+	TREE_DROP_BEFORE
+	TREE_DROP_AFTER
+	TREE_DROP_INTO
+)
+
+const (
+	TREE_VIEW_INDENT        = 16
+	TREE_VIEW_EXPANDER_SIZE = 16
+	TREE_VIEW_DRAG_DISTANCE = 5
+)
+
+type TreeLoadHandler func(node *TreeNode)
+type TreeActivatedHandler func(node *TreeNode)
+type TreeDropHandler func(node, target *TreeNode, position TreeDropPosition) bool
+
+// TreeNode is a node of a TreeView. Nodes can be built before they are added
+// to a tree; changes to nodes in a tree show up on the next paint.
+type TreeNode struct {
+	tree     *TreeView
+	parent   *TreeNode
+	children []*TreeNode
+	text     string
+	userData interface{}
+	expanded bool
+	lazy     bool
+}
+
+func NewTreeNode(text string) *TreeNode {
+	return &TreeNode{text: text}
+}
+
+func (node *TreeNode) GetText() string {
+	return node.text
+}
+
+func (node *TreeNode) SetText(text string) *TreeNode {
+	node.text = text
+	node.invalidate()
+
+	return node
+}
+
+func (node *TreeNode) GetUserData() interface{} {
+	return node.userData
+}
+
+func (node *TreeNode) SetUserData(userData interface{}) *TreeNode {
+	node.userData = userData
+
+	return node
+}
+
+func (node *TreeNode) GetTree() *TreeView {
+	return node.tree
+}
+
+// GetParent returns the parent node. Top level nodes return the hidden root
+// of their tree.
+func (node *TreeNode) GetParent() *TreeNode {
+	return node.parent
+}
+
+func (node *TreeNode) GetChildren() []*TreeNode {
+	return node.children
+}
+
+// GetDepth returns 0 for top level nodes, 1 for their children and so on.
+func (node *TreeNode) GetDepth() int {
+	depth := -1
+	for iter := node.parent; iter != nil; iter = iter.parent {
+		depth++
+	}
+
+	return depth
+}
+
+// Add creates a child node with text and returns it.
+func (node *TreeNode) Add(text string) *TreeNode {
+	child := NewTreeNode(text)
+	node.AppendChild(child)
+
+	return child
+}
+
+func (node *TreeNode) AppendChild(child *TreeNode) *TreeNode {
+	return node.InsertChild(len(node.children), child)
+}
+
+// InsertChild moves child to position index among the children of node.
+func (node *TreeNode) InsertChild(index int, child *TreeNode) *TreeNode {
+	child.Remove()
+	index = int(math.Max(0, math.Min(float64(index), float64(len(node.children)))))
+
+	node.children = append(node.children, nil)
+	copy(node.children[index+1:], node.children[index:])
+	node.children[index] = child
+	child.parent = node
+	child.setTree(node.tree)
+	node.invalidate()
+
+	return node
+}
+
+// Remove detaches the node, with its children, from its parent.
+func (node *TreeNode) Remove() *TreeNode {
+	parent := node.parent
+	if parent == nil {
+		return node
+	}
+
+	if i := parent.indexOf(node); i >= 0 {
+		parent.children = append(parent.children[:i], parent.children[i+1:]...)
+	}
+	node.parent = nil
+	parent.invalidate()
+	node.setTree(nil)
+
+	return node
+}
+
+func (node *TreeNode) RemoveChildren() *TreeNode {
+	for _, child := range node.children {
+		child.parent = nil
+		child.setTree(nil)
+	}
+	node.children = nil
+	node.invalidate()
+
+	return node
+}
+
+// SetLazy marks the node as having children that are not loaded yet. The
+// load handler of the tree is called the first time the node is expanded.
+func (node *TreeNode) SetLazy(lazy bool) *TreeNode {
+	node.lazy = lazy
+	node.invalidate()
+
+	return node
+}
+
+func (node *TreeNode) IsLazy() bool {
+	return node.lazy
+}
+
+// HasChildren tells whether the node has children or may load some.
+func (node *TreeNode) HasChildren() bool {
+	return len(node.children) > 0 || node.lazy
+}
+
+func (node *TreeNode) IsExpanded() bool {
+	return node.expanded
+}
+
+func (node *TreeNode) Expand() *TreeNode {
+	if node.expanded || !node.HasChildren() {
+		return node
+	}
+
+	node.expanded = true
+	if node.lazy {
+		node.lazy = false
+		if node.tree != nil && node.tree.onLoad != nil {
+			node.tree.onLoad(node)
+		}
+	}
+	node.invalidate()
+
+	return node
+}
+
+func (node *TreeNode) Collapse() *TreeNode {
+	if node.expanded {
+		node.expanded = false
+		node.invalidate()
+	}
+
+	return node
+}
+
+func (node *TreeNode) Toggle() *TreeNode {
+	if node.expanded {
+		return node.Collapse()
+	}
+
+	return node.Expand()
+}
+
+// IsAncestorOf tells whether node is other or contains it.
+func (node *TreeNode) IsAncestorOf(other *TreeNode) bool {
+	for iter := other; iter != nil; iter = iter.parent {
+		if iter == node {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (node *TreeNode) indexOf(child *TreeNode) int {
+	for i, iter := range node.children {
+		if iter == child {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (node *TreeNode) setTree(tree *TreeView) {
+	node.tree = tree
+	for _, child := range node.children {
+		child.setTree(tree)
+	}
+
+	return
+}
+
+func (node *TreeNode) invalidate() {
+	if node.tree != nil {
+		node.tree.invalidate()
+	}
+
+	return
+}
+
+// TreeView shows a hierarchy of TreeNodes. The expanded part of the tree is
+// flattened into the rows of the embedded ListView, so it scrolls and
+// virtualizes like a list and shares its selection and keyboard handling.
+// Left and Right collapse and expand; with SetDragEnable nodes can be dragged
+// before, after or into other nodes. The changed handler receives the
+// selected *TreeNode.
+type TreeView struct {
+	*ListView
+	root         *TreeNode
+	rows         []*TreeNode
+	rowsDirty    bool
+	dragEnable   bool
+	dragNode     *TreeNode
+	dragging     bool
+	dragStart    structs.Point
+	dropNode     *TreeNode
+	dropPosition TreeDropPosition
+	onLoad       TreeLoadHandler
+	onDrop       TreeDropHandler
+}
+
+func NewTreeView(parent *Widget, x, y, w, h float32) *TreeView {
+	tree := &TreeView{
+		ListView: NewListView(parent, x, y, w, h),
+	}
+	tree.t = TYPE_TREE_VIEW
+	tree.root = &TreeNode{tree: tree, expanded: true}
+	tree.owner = tree
+	tree.I = tree
+	tree.SetDataSource(tree)
+
+	return tree
+}
+
+// GetRoot returns the hidden root node; its children are the top level
+// nodes.
+func (tree *TreeView) GetRoot() *TreeNode {
+	return tree.root
+}
+
+// SetLoadHandler sets the handler that fills lazy nodes. It may add the
+// children right away or later, when they are available.
+func (tree *TreeView) SetLoadHandler(onLoad TreeLoadHandler) *TreeView {
+	tree.onLoad = onLoad
+
+	return tree
+}
+
+func (tree *TreeView) SetChangedHandler(onChanged func(node *TreeNode)) *TreeView {
+	tree.ListView.SetChangedHandler(func(interface{}) {
+		onChanged(tree.GetSelectedNode())
+	})
+
+	return tree
+}
+
+// SetActivatedHandler sets the handler called when a node is double clicked
+// or Enter is pressed on it.
+func (tree *TreeView) SetActivatedHandler(onActivated TreeActivatedHandler) *TreeView {
+	tree.ListView.SetActivatedHandler(func(index int) {
+		onActivated(tree.GetNodeAt(index))
+	})
+
+	return tree
+}
+
+// SetDragEnable lets the user reorder nodes by dragging them.
+func (tree *TreeView) SetDragEnable(dragEnable bool) *TreeView {
+	tree.dragEnable = dragEnable
+
+	return tree
+}
+
+// SetDropHandler sets the handler asked before a dragged node is moved. It
+// can refuse the move by returning false.
+func (tree *TreeView) SetDropHandler(onDrop TreeDropHandler) *TreeView {
+	tree.onDrop = onDrop
+
+	return tree
+}
+
+// GetNodeAt returns the node shown in row index, or nil.
+func (tree *TreeView) GetNodeAt(index int) *TreeNode {
+	tree.ensureRows()
+	if index < 0 || index >= len(tree.rows) {
+		return nil
+	}
+
+	return tree.rows[index]
+}
+
+// GetRowOf returns the row showing node, or -1 if it is collapsed away.
+func (tree *TreeView) GetRowOf(node *TreeNode) int {
+	tree.ensureRows()
+	for i, iter := range tree.rows {
+		if iter == node {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (tree *TreeView) GetSelectedNode() *TreeNode {
+	return tree.GetNodeAt(tree.GetSelectedIndex())
+}
+
+func (tree *TreeView) GetSelectedNodes() []*TreeNode {
+	var nodes []*TreeNode
+	for _, index := range tree.GetSelected() {
+		nodes = append(nodes, tree.GetNodeAt(index))
+	}
+
+	return nodes
+}
+
+// SelectNode expands the ancestors of node, selects it and scrolls it into
+// view.
+func (tree *TreeView) SelectNode(node *TreeNode, notify bool) *TreeView {
+	for iter := node.parent; iter != nil; iter = iter.parent {
+		iter.Expand()
+	}
+
+	if index := tree.GetRowOf(node); index >= 0 {
+		tree.SetCurrent(index)
+		tree.SetSelected(index, true, notify)
+	}
+
+	return tree
+}
+
+// GetCount and RenderItem make the tree the data source of its ListView.
+func (tree *TreeView) GetCount() int {
+	tree.ensureRows()
+
+	return len(tree.rows)
+}
+
+func (tree *TreeView) RenderItem(item *ListItem, index int) {
+	item.SetText(tree.rows[index].text, false)
+
+	return
+}
+
+func (tree *TreeView) invalidate() {
+	tree.rowsDirty = true
+	tree.PostRedraw()
+
+	return
+}
+
+// ensureRows flattens the expanded nodes again after a change, keeping the
+// selection and the current node on the same nodes.
+func (tree *TreeView) ensureRows() {
+	if !tree.rowsDirty {
+		return
+	}
+	tree.rowsDirty = false
+
+	var selected []*TreeNode
+	for index := range tree.selected {
+		if index < len(tree.rows) {
+			selected = append(selected, tree.rows[index])
+		}
+	}
+	current := tree.nodeAt(tree.current)
+	anchor := tree.nodeAt(tree.anchor)
+
+	tree.rows = tree.rows[:0]
+	tree.flatten(tree.root)
+
+	rowOf := make(map[*TreeNode]int, len(tree.rows))
+	for i, node := range tree.rows {
+		rowOf[node] = i
+	}
+
+	tree.selected = make(map[int]bool)
+	for _, node := range selected {
+		if i, ok := rowOf[node]; ok {
+			tree.selected[i] = true
+		}
+	}
+	tree.current = tree.visibleRowOf(current, rowOf)
+	tree.anchor = tree.visibleRowOf(anchor, rowOf)
+
+	tree.dirty = true
+	tree.updateLayout()
+
+	return
+}
+
+func (tree *TreeView) flatten(node *TreeNode) {
+	for _, child := range node.children {
+		tree.rows = append(tree.rows, child)
+		if child.expanded {
+			tree.flatten(child)
+		}
+	}
+
+	return
+}
+
+func (tree *TreeView) nodeAt(index int) *TreeNode {
+	if index < 0 || index >= len(tree.rows) {
+		return nil
+	}
+
+	return tree.rows[index]
+}
+
+// visibleRowOf returns the row of node or of its closest shown ancestor.
+func (tree *TreeView) visibleRowOf(node *TreeNode, rowOf map[*TreeNode]int) int {
+	for iter := node; iter != nil; iter = iter.parent {
+		if i, ok := rowOf[iter]; ok {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (tree *TreeView) getItemType() string {
+	return TYPE_TREE_ITEM
+}
+
+func (tree *TreeView) getExpanderX(node *TreeNode) int {
+	return LIST_ITEM_PADDING + node.GetDepth()*TREE_VIEW_INDENT
+}
+
+func (tree *TreeView) paintItem(item *ListItem, context canvas.Canvas2D) {
+	node := tree.rows[item.index]
+	style := item.getStyle("")
+	w := float64(item.rect.W)
+	h := float64(item.rect.H)
+	item.paintHighlight(context, style)
+
+	x := float64(tree.getExpanderX(node))
+	context.SetFillStyle(style.TextColor)
+	if node.HasChildren() {
+		cx := x + TREE_VIEW_EXPANDER_SIZE/2
+		context.BeginPath()
+		if node.expanded {
+			context.MoveTo(cx-5, h/2-3)
+			context.LineTo(cx+5, h/2-3)
+			context.LineTo(cx, h/2+3)
+		} else {
+			context.MoveTo(cx-3, h/2-5)
+			context.LineTo(cx+3, h/2)
+			context.LineTo(cx-3, h/2+5)
+		}
+		context.ClosePath()
+		context.Fill()
+	}
+	x += TREE_VIEW_EXPANDER_SIZE
+
+	context.SetFont(style.Font)
+	context.SetTextAlign("left")
+	context.SetTextBaseline("middle")
+	context.FillText(node.text, x, h/2, w-x-LIST_ITEM_PADDING)
+
+	if tree.dragging && tree.dropNode == node {
+		context.SetLineWidth(2)
+		context.SetStrokeStyle(style.TextColor)
+		context.BeginPath()
+		switch tree.dropPosition {
+		case TREE_DROP_BEFORE:
+			context.MoveTo(x, 1)
+			context.LineTo(w, 1)
+		case TREE_DROP_AFTER:
+			context.MoveTo(x, h-1)
+			context.LineTo(w, h-1)
+		case TREE_DROP_INTO:
+			context.Rect(1, 1, w-2, h-2)
+		}
+		context.Stroke()
+	}
+
+	return
+}
+
+func (tree *TreeView) isOnExpander(node *TreeNode, point *structs.Point) bool {
+	p := tree.content.translatePoint(point)
+	x := tree.getExpanderX(node)
+
+	return node.HasChildren() && p.X >= x && p.X < x+TREE_VIEW_EXPANDER_SIZE
+}
+
+func (tree *TreeView) onContentPointerDown(point *structs.Point) {
+	tree.dragging = false
+	tree.dragNode = nil
+
+	index := tree.getIndexAtPoint(point)
+	node := tree.GetNodeAt(index)
+	if node != nil && tree.enable && tree.isOnExpander(node, point) {
+		node.Toggle()
+		return
+	}
+
+	tree.ListView.onContentPointerDown(point)
+	if node != nil && tree.dragEnable {
+		tree.dragNode = node
+		tree.dragStart = structs.Point{X: point.X, Y: point.Y}
+	}
+
+	return
+}
+
+func (tree *TreeView) onContentPointerMove(point *structs.Point) {
+	if tree.dragNode == nil || !tree.isPointerDown() {
+		return
+	}
+
+	dx := math.Abs(float64(point.X - tree.dragStart.X))
+	dy := math.Abs(float64(point.Y - tree.dragStart.Y))
+	if !tree.dragging && dx < TREE_VIEW_DRAG_DISTANCE && dy < TREE_VIEW_DRAG_DISTANCE {
+		return
+	}
+	tree.dragging = true
+
+	tree.dropNode = nil
+	tree.dropPosition = TREE_DROP_NONE
+	index := tree.getIndexAtPoint(point)
+	if target := tree.GetNodeAt(index); target != nil && !tree.dragNode.IsAncestorOf(target) {
+		y := tree.content.translatePoint(point).Y - index*tree.itemHeight
+		switch {
+		case y < tree.itemHeight/4:
+			tree.dropPosition = TREE_DROP_BEFORE
+		case y >= tree.itemHeight*3/4:
+			tree.dropPosition = TREE_DROP_AFTER
+		default:
+			tree.dropPosition = TREE_DROP_INTO
+		}
+		tree.dropNode = target
+	}
+	tree.PostRedraw()
+
+	return
+}
+
+func (tree *TreeView) onContentPointerUp(point *structs.Point) {
+	node := tree.dragNode
+	target := tree.dropNode
+	position := tree.dropPosition
+	dragging := tree.dragging
+
+	tree.dragNode = nil
+	tree.dropNode = nil
+	tree.dragging = false
+	if !dragging || target == nil {
+		return
+	}
+
+	if tree.onDrop != nil && !tree.onDrop(node, target, position) {
+		tree.PostRedraw()
+		return
+	}
+
+	switch position {
+	case TREE_DROP_BEFORE:
+		node.Remove()
+		target.parent.InsertChild(target.parent.indexOf(target), node)
+	case TREE_DROP_AFTER:
+		node.Remove()
+		target.parent.InsertChild(target.parent.indexOf(target)+1, node)
+	case TREE_DROP_INTO:
+		target.AppendChild(node)
+		target.Expand()
+	}
+	tree.SelectNode(node, true)
+
+	return
+}
+
+func (tree *TreeView) onContentDoubleClick(point *structs.Point) {
+	index := tree.getIndexAtPoint(point)
+	if node := tree.GetNodeAt(index); node != nil && !tree.isOnExpander(node, point) {
+		node.Toggle()
+		tree.activate(index)
+	}
+
+	return
+}
+
+func (tree *TreeView) onKeyDown(code int) {
+	node := tree.GetNodeAt(tree.current)
+	if node == nil || !tree.enable {
+		tree.ListView.onKeyDown(code)
+		return
+	}
+
+	switch code {
+	case keyevent.DOM_VK_LEFT:
+		if node.expanded {
+			node.Collapse()
+		} else if node.parent != tree.root {
+			tree.moveCurrent(tree.GetRowOf(node.parent), false, false)
+		}
+	case keyevent.DOM_VK_RIGHT:
+		if !node.expanded {
+			node.Expand()
+		} else if len(node.children) > 0 {
+			tree.moveCurrent(tree.current+1, false, false)
+		}
+	default:
+		tree.ListView.onKeyDown(code)
+		return
+	}
+
+	if tree.keyDownHandler != nil {
+		tree.keyDownHandler(code)
+	}
+
+	return
+}
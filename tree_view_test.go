@@ -0,0 +1,113 @@
+package gwk
+
+import (
+	"github.com/Luncher/gwk/pkg/keyevent"
+	"reflect"
+	"testing"
+)
+
+// newTreeViewScene returns an empty TreeView 200x240.
+func newTreeViewScene() (typist, *TreeView) {
+	var tree *TreeView
+	k := newScene(200, 240, func(win *Window) {
+		tree = NewTreeView(win.Widget, 0, 0, 200, 240)
+	})
+
+	return k, tree
+}
+
+// getRowTexts returns the text of every row of tree.
+func getRowTexts(tree *TreeView) []string {
+	texts := []string{}
+	for i := 0; i < tree.GetCount(); i++ {
+		texts = append(texts, tree.GetNodeAt(i).GetText())
+	}
+
+	return texts
+}
+
+func TestTreeViewLazyLoad(t *testing.T) {
+	k, tree := newTreeViewScene()
+	folder := tree.GetRoot().Add("folder").SetLazy(true)
+	tree.GetRoot().Add("file")
+
+	loads := 0
+	var pending *TreeNode
+	tree.SetLoadHandler(func(node *TreeNode) {
+		loads++
+		node.Add("a")
+		pending = node
+	})
+	k.m.Snapshot()
+	if !folder.HasChildren() || len(folder.GetChildren()) != 0 {
+		t.Fatalf("lazy folder loaded %d children before expanding", len(folder.GetChildren()))
+	}
+
+	k.click(100, 12)
+	k.key(keyevent.DOM_VK_RIGHT)
+	if want := []string{"folder", "a", "file"}; loads != 1 || !reflect.DeepEqual(getRowTexts(tree), want) {
+		t.Errorf("expanding loaded %d times to rows %q, want once to %q", loads, getRowTexts(tree), want)
+	}
+
+	pending.Add("b")
+	k.m.Snapshot()
+	if want := []string{"folder", "a", "b", "file"}; !reflect.DeepEqual(getRowTexts(tree), want) {
+		t.Errorf("children added after the load showed rows %q, want %q", getRowTexts(tree), want)
+	}
+
+	k.key(keyevent.DOM_VK_LEFT)
+	if want := []string{"folder", "file"}; !reflect.DeepEqual(getRowTexts(tree), want) {
+		t.Errorf("collapsing showed rows %q, want %q", getRowTexts(tree), want)
+	}
+	k.key(keyevent.DOM_VK_RIGHT)
+	if loads != 1 || len(folder.GetChildren()) != 2 {
+		t.Errorf("expanding again loaded %d times with %d children", loads, len(folder.GetChildren()))
+	}
+}
+
+func TestTreeViewDragReorder(t *testing.T) {
+	row := func(index, offset int) int {
+		return index*LIST_VIEW_ITEM_HEIGHT + offset
+	}
+
+	cases := []struct {
+		name     string
+		from, to int
+		offset   int
+		refuse   bool
+		rows     []string
+		selected string
+	}{
+		{"before", 3, 0, 2, false, []string{"c", "a", "a1", "b"}, "c"},
+		{"after", 0, 3, 22, false, []string{"b", "c", "a", "a1"}, "a"},
+		{"into", 3, 0, 12, false, []string{"a", "a1", "c", "b"}, "c"},
+		{"into its own child", 0, 1, 12, false, []string{"a", "a1", "b", "c"}, "a"},
+		{"refused", 3, 0, 2, true, []string{"a", "a1", "b", "c"}, "c"},
+		{"short of the drag distance", 2, 2, 14, false, []string{"a", "a1", "b", "c"}, "b"},
+	}
+	for _, c := range cases {
+		k, tree := newTreeViewScene()
+		a := tree.GetRoot().Add("a")
+		a.Add("a1")
+		a.Expand()
+		tree.GetRoot().Add("b")
+		tree.GetRoot().Add("c")
+		tree.SetDragEnable(true)
+		tree.SetDropHandler(func(node, target *TreeNode, position TreeDropPosition) bool {
+			return !c.refuse
+		})
+		k.m.Snapshot()
+
+		k.drag(100, row(c.from, 12), 100, row(c.to, c.offset))
+		k.m.Snapshot()
+		if rows := getRowTexts(tree); !reflect.DeepEqual(rows, c.rows) {
+			t.Errorf("%s: rows %q, want %q", c.name, rows, c.rows)
+		}
+		if node := tree.GetSelectedNode(); node == nil || node.GetText() != c.selected {
+			t.Errorf("%s: selected %v, want %s", c.name, node, c.selected)
+		}
+		if tree.dragNode != nil || tree.dragging {
+			t.Errorf("%s: still dragging after the release", c.name)
+		}
+	}
+}
@@ -7,3 +7,12 @@ import (
 func isPointInRect(point *structs.Point, rect *structs.Rect) bool {
 	return point.X >= rect.X && point.Y >= rect.Y && point.X < (rect.X+rect.W) && point.Y < (rect.Y+rect.H)
 }
+
+// colorOr returns color, or fallback when the theme leaves color empty.
+func colorOr(color, fallback string) string {
+	if len(color) > 0 {
+		return color
+	}
+
+	return fallback
+}
@@ -2,6 +2,7 @@ package gwk
 
 import (
 	"fmt"
+	"github.com/Luncher/gwk/pkg/canvas"
 	"github.com/Luncher/gwk/pkg/image"
 	"github.com/Luncher/gwk/pkg/structs"
 	"github.com/Luncher/gwk/pkg/theme"
@@ -54,6 +55,9 @@ const (
 	TYPE_IMAGE_VIEW          = "image-view"
 	TYPE_TREE_VIEW           = "tree-view"
 	TYPE_TREE_ITEM           = "tree-item"
+	TYPE_TABLE               = "table"
+	TYPE_TABLE_HEADER        = "table-header"
+	TYPE_TABLE_CELL          = "table-cell"
 	TYPE_ACCORDION           = "accordion"
 	TYPE_ACCORDION_ITEM      = "accordion-item"
 	TYPE_ACCORDION_TITLE     = "accordion-title"
@@ -87,6 +91,7 @@ const (
 	TYPE_COMBOBOX_POPUP      = "combobox-popup"
 	TYPE_COMBOBOX_POPUP_ITEM = "combobox-popup-item"
 	TYPE_COLOR_EDIT          = "color-edit"
+	TYPE_COLOR_PICKER        = "color-picker"
 	TYPE_RANGE_EDIT          = "range-edit"
 	TYPE_FILENAME_EDIT       = "filename-edit"
 	TYPE_FILENAMES_EDIT      = "filenames-edit"
@@ -116,32 +121,33 @@ type PointerEventHandler interface {
 type KeyEventHandler interface {
 	onKeyDown(code int)
 	onKeyUp(code int)
+	onKeyPress(char rune)
 }
 
 type PaintEventHandler interface {
 	ensureImages()
-	draw(*dom.CanvasRenderingContext2D)
-	relayout(*dom.CanvasRenderingContext2D, bool)
-	beforePaint(*dom.CanvasRenderingContext2D)
-	paintBackground(*dom.CanvasRenderingContext2D)
-	paintSelf(*dom.CanvasRenderingContext2D)
-	paintChildren(*dom.CanvasRenderingContext2D)
-	drawInputTips(*dom.CanvasRenderingContext2D)
-	afterPaint(*dom.CanvasRenderingContext2D)
+	draw(canvas.Canvas2D)
+	relayout(canvas.Canvas2D, bool)
+	beforePaint(canvas.Canvas2D)
+	paintBackground(canvas.Canvas2D)
+	paintSelf(canvas.Canvas2D)
+	paintChildren(canvas.Canvas2D)
+	drawInputTips(canvas.Canvas2D)
+	afterPaint(canvas.Canvas2D)
 }
 
 type WidgetInterface interface {
 	KeyEventHandler
 	PaintEventHandler
 	PointerEventHandler
-	getX() int
-	getY() int
-	destroy()
-	getWindow() *Window
-	showAll(visible bool) *Widget
+	GetX() int
+	GetY() int
+	Destroy()
+	GetWindow() *Window
+	ShowAll(visible bool) *Widget
 	setState(string, bool) *Widget
-	getParent() *Widget
-	setVisible(visible bool) *Widget
+	GetParent() *Widget
+	SetVisible(visible bool) *Widget
 	// SetText(text string, notify bool) *Widget
 	findTargetWidgetEx(point *structs.Point, recursive bool) *Widget
 }
@@ -159,8 +165,8 @@ type DoubleClickedHandler func(*structs.Point)
 type LongPressHandler func(*structs.Point)
 type KeyDownHandler func(int)
 type KeyUpHandler func(int)
-type OnBeforePaintHandler func(*dom.CanvasRenderingContext2D)
-type OnAfterPaintHandler func(*dom.CanvasRenderingContext2D)
+type OnBeforePaintHandler func(canvas.Canvas2D)
+type OnAfterPaintHandler func(canvas.Canvas2D)
 type WheelHandler func(float64)
 type OnChangedHandler func(interface{})
 
@@ -177,6 +183,7 @@ type Widget struct {
 	text                 string
 	tag                  string
 	tips                 string
+	tipsContent          TipsContent
 	enable               bool
 	checkable            bool
 	children             []*Widget
@@ -203,6 +210,7 @@ type Widget struct {
 	checkEnable          CheckEnable
 	removedHandler       RemovedHandler
 	target               *Widget
+	window               *Window
 	onMoved              OnMovedHandler
 	stateChangedHandler  StateChangedHandler
 	onSized              OnResizedHandler
@@ -230,6 +238,7 @@ func NewWidget(t string, parent *Widget, x, y, w, h float32) *Widget {
 		imageDisplay: image.DISPLAY_9PATCH,
 		rect:         &structs.Rect{X: int(x), Y: int(y), W: int(w), H: int(h)},
 	}
+	widget.I = widget
 
 	widget.setState(STATE_NORMAL, false)
 
@@ -270,17 +279,17 @@ func (w *Widget) UseTheme(t string) *Widget {
 	return w
 }
 
-func (w *Widget) isSelected() bool {
+func (w *Widget) IsSelected() bool {
 	return w.selected
 }
 
-func (w *Widget) setSelected(value bool) *Widget {
+func (w *Widget) SetSelected(value bool) *Widget {
 	w.selected = value
 
 	return w
 }
 
-func (w *Widget) setSelectable(selectable bool) bool {
+func (w *Widget) SetSelectable(selectable bool) bool {
 	w.selectable = selectable
 
 	return true
@@ -309,8 +318,8 @@ func (w *Widget) getFrameRate() int {
 	return GetWindowManagerInstance().getFrameRate()
 }
 
-func (w *Widget) showFPS(maxFpsMode bool) {
-	GetWindowManagerInstance().showFPS(maxFpsMode)
+func (w *Widget) ShowFPS(maxFpsMode bool) {
+	GetWindowManagerInstance().ShowFPS(maxFpsMode)
 }
 
 func (w *Widget) isPointerDown() bool {
@@ -326,15 +335,19 @@ func (w *Widget) isAltDown() bool {
 	return GetWindowManagerInstance().isAltDown()
 }
 
+func (w *Widget) isShiftDown() bool {
+	return GetWindowManagerInstance().isShiftDown()
+}
+
 func (w *Widget) isCtrlDown() bool {
 	return GetWindowManagerInstance().isCtrlDown()
 }
 
-func (w *Widget) getApp() *Application {
-	return GetWindowManagerInstance().getApp()
+func (w *Widget) GetApp() *Application {
+	return GetWindowManagerInstance().GetApp()
 }
 
-func (w *Widget) getCanvas2D() *dom.CanvasRenderingContext2D {
+func (w *Widget) getCanvas2D() canvas.Canvas2D {
 	return GetWindowManagerInstance().getCanvas2D()
 }
 
@@ -347,41 +360,47 @@ func (w *Widget) getLastPointerPoint() *structs.Point {
 }
 
 func (w *Widget) getTopWindow() *Window {
-	return w.getWindow()
+	return w.GetWindow()
 }
 
-func (w *Widget) getWindow() *Window {
-	return w.parent.getWindow()
+// GetWindow returns the window the widget belongs to, or nil when the widget
+// is not attached to one.
+func (w *Widget) GetWindow() *Window {
+	if w.parent == nil {
+		return w.window
+	}
+
+	return w.parent.I.GetWindow()
 }
 
-func (w *Widget) getParent() *Widget {
+func (w *Widget) GetParent() *Widget {
 	return w.parent
 }
 
-func (w *Widget) getX() int {
+func (w *Widget) GetX() int {
 	return w.rect.X
 }
 
-func (w *Widget) getY() int {
+func (w *Widget) GetY() int {
 	return w.rect.Y
 }
 
-func (w *Widget) getWidth() int {
+func (w *Widget) GetWidth() int {
 	return w.rect.W
 }
 
-func (w *Widget) getHeight() int {
+func (w *Widget) GetHeight() int {
 	return w.rect.H
 }
 
 func (w *Widget) getPositionInView() *structs.Point {
-	x := w.getX()
-	y := w.getY()
+	x := w.GetX()
+	y := w.GetY()
 	point := &structs.Point{}
 
-	for iter := w.getParent(); iter != nil; iter = iter.getParent() {
-		x += iter.getX()
-		y += iter.getY()
+	for iter := w.GetParent(); iter != nil; iter = iter.GetParent() {
+		x += iter.GetX()
+		y += iter.GetY()
 		if iter.isScrollView {
 			x -= iter.xOffset
 			y -= iter.yOffset
@@ -394,13 +413,13 @@ func (w *Widget) getPositionInView() *structs.Point {
 	return point
 }
 
-func (w *Widget) getAbsPosition() *structs.Point {
+func (w *Widget) GetAbsPosition() *structs.Point {
 	x := w.rect.X
 	y := w.rect.Y
 
 	for parent := w.parent; parent != nil; parent = parent.parent {
-		x += parent.getX()
-		y += parent.getY()
+		x += parent.GetX()
+		y += parent.GetY()
 	}
 
 	return &structs.Point{X: x, Y: y}
@@ -424,25 +443,25 @@ func (w *Widget) getPositionInWindow() *structs.Point {
 }
 
 func (w *Widget) translatePoint(point *structs.Point) *structs.Point {
-	p := w.getAbsPosition()
+	p := w.GetAbsPosition()
 
 	return &structs.Point{X: point.X - p.X, Y: point.Y - p.Y}
 }
 
 func (w *Widget) postRedrawAll() {
-	GetWindowManagerInstance().postRedraw()
+	GetWindowManagerInstance().PostRedraw()
 
 	return
 }
 
 func (w *Widget) PostRedraw() {
-	GetWindowManagerInstance().postRedraw()
+	GetWindowManagerInstance().PostRedraw()
 
 	return
 }
 
 func (w *Widget) redraw(rect *structs.Rect) {
-	// p := w.getAbsPosition()
+	// p := w.GetAbsPosition()
 
 	// if rect == nil {
 	// 	rect = &structs.Rect{X: 0, Y: 0, W: w.rect.W, H: w.rect.H}
@@ -486,7 +505,7 @@ func (w *Widget) findTargetWidget(point *structs.Point) *Widget {
 	return w.findTargetWidgetEx(point, true)
 }
 
-func (w *Widget) setRemovedHandler(removeHandler RemovedHandler) *Widget {
+func (w *Widget) SetRemovedHandler(removeHandler RemovedHandler) *Widget {
 	w.removedHandler = removeHandler
 
 	return w
@@ -500,13 +519,13 @@ func (w *Widget) onRemoved() {
 	return
 }
 
-func (w *Widget) removeChild(child *Widget) *Widget {
-	child.remove()
+func (w *Widget) RemoveChild(child *Widget) *Widget {
+	child.Remove()
 
 	return w
 }
 
-func (w *Widget) remove() *Widget {
+func (w *Widget) Remove() *Widget {
 	parent := w.parent
 	if parent != nil {
 		for i, child := range parent.children {
@@ -520,6 +539,10 @@ func (w *Widget) remove() *Widget {
 			parent.target = nil
 		}
 
+		if window := w.GetWindow(); window != nil && window.focusWidget == w {
+			window.SetFocus(nil)
+		}
+
 		w.parent = nil
 		w.onRemoved()
 		parent.setNeedRelayout(true)
@@ -532,12 +555,12 @@ func (w *Widget) cleanUp() {
 
 }
 
-func (w *Widget) destroy() {
+func (w *Widget) Destroy() {
 	if len(w.children) > 0 {
 		w.destroyChildren()
 	}
 
-	w.remove()
+	w.Remove()
 	w.cleanUp()
 
 	return
@@ -545,7 +568,7 @@ func (w *Widget) destroy() {
 
 func (w *Widget) destroyChildren() {
 	for _, child := range w.children {
-		child.destroy()
+		child.Destroy()
 	}
 	w.target = nil
 	w.children = w.children[:0]
@@ -554,7 +577,7 @@ func (w *Widget) destroyChildren() {
 	return
 }
 
-func (w *Widget) forEachChild(onVisit WidgetVisit) {
+func (w *Widget) ForEachChild(onVisit WidgetVisit) {
 	for _, child := range w.children {
 		onVisit(child)
 	}
@@ -562,8 +585,8 @@ func (w *Widget) forEachChild(onVisit WidgetVisit) {
 	return
 }
 
-func (w *Widget) setTextOf(name, text string, notify bool) *Widget {
-	child := w.lookup(name, true)
+func (w *Widget) SetTextOf(name, text string, notify bool) *Widget {
+	child := w.Lookup(name, true)
 
 	if child != nil {
 		child.SetText(text, notify)
@@ -574,11 +597,11 @@ func (w *Widget) setTextOf(name, text string, notify bool) *Widget {
 	return child
 }
 
-func (w *Widget) setVisibleOf(name string, value bool) *Widget {
-	child := w.lookup(name, true)
+func (w *Widget) SetVisibleOf(name string, value bool) *Widget {
+	child := w.Lookup(name, true)
 
 	if child != nil {
-		child.setVisible(value)
+		child.SetVisible(value)
 	} else {
 		fmt.Printf("not found %s", name)
 	}
@@ -597,33 +620,33 @@ func (w *Widget) GetText() string {
 	return w.text
 }
 
-func (w *Widget) setTips(tips string) *Widget {
+func (w *Widget) SetTips(tips string) *Widget {
 	w.tips = tips
 
 	return w
 }
 
-func (w *Widget) getTips() string {
+func (w *Widget) GetTips() string {
 	return w.tips
 }
 
-func (w *Widget) setInputTips(tips string) *Widget {
+func (w *Widget) SetInputTips(tips string) *Widget {
 	w.inputTips = tips
 
 	return w
 }
 
-func (w *Widget) getInputTips() string {
+func (w *Widget) GetInputTips() string {
 	return w.inputTips
 }
 
-func (widget *Widget) drawInputTips(context *dom.CanvasRenderingContext2D) {
+func (widget *Widget) drawInputTips(context canvas.Canvas2D) {
 	h := widget.rect.H
 	w := widget.rect.W
 	y := widget.rect.H >> 1
 	x := widget.leftMargin
 	text := widget.GetText()
-	inputTips := widget.getInputTips()
+	inputTips := widget.GetInputTips()
 
 	if len(text) > 0 || len(inputTips) == 0 || widget.t != TYPE_EDIT || widget.editing {
 		return
@@ -631,15 +654,15 @@ func (widget *Widget) drawInputTips(context *dom.CanvasRenderingContext2D) {
 
 	style := widget.getStyle("")
 	context.Save()
-	context.Font = style.Font
-	context.FillStyle = "#E0E0E0"
+	context.SetFont(style.Font)
+	context.SetFillStyle("#E0E0E0")
 
 	context.BeginPath()
 	context.Rect(0, 0, float64(w-x), float64(h))
 	context.Clip()
 
-	context.TextAlign = "left"
-	context.TextBaseline = "middle"
+	context.SetTextAlign("left")
+	context.SetTextBaseline("middle")
 	context.FillText(inputTips, float64(x), float64(y), -1)
 
 	context.Restore()
@@ -647,78 +670,66 @@ func (widget *Widget) drawInputTips(context *dom.CanvasRenderingContext2D) {
 	return
 }
 
-func (w *Widget) drawTips(context *dom.CanvasRenderingContext2D) {
-	tips := w.getTips()
-	if len(tips) > 0 {
-		style := w.getStyle("")
-		x := w.rect.W >> 1
-		y := w.rect.H >> 1
-		font := style.Font
-		textColor := style.TextColor
-
-		if len(font) > 0 && len(textColor) > 0 {
-			context.TextAlign = "center"
-			context.TextBaseline = "middle"
-			context.Font = font
-			context.FillStyle = textColor
-			context.FillText(tips, float64(x), float64(y), -1)
-		}
-	}
-
-	return
-}
-
-func (w *Widget) setID(id string) *Widget {
+func (w *Widget) SetID(id string) *Widget {
 	w.id = id
 
 	return w
 }
 
-func (w *Widget) getID() string {
+func (w *Widget) GetID() string {
 	return w.id
 }
 
-func (w *Widget) setName(name string) *Widget {
+func (w *Widget) SetName(name string) *Widget {
 	w.name = name
 
 	return w
 }
 
-func (w *Widget) getName() string {
+func (w *Widget) GetName() string {
 	return w.name
 }
 
-func (w *Widget) setTag(tag string) *Widget {
+func (w *Widget) SetTag(tag string) *Widget {
 	w.tag = tag
 
 	return w
 }
 
-func (w *Widget) getTag() string {
+func (w *Widget) GetTag() string {
 	return w.tag
 }
 
-func (w *Widget) setUserDate(data interface{}) *Widget {
+func (w *Widget) SetUserData(data interface{}) *Widget {
 	w.userData = data
 
 	return w
 }
 
-func (w *Widget) getUserData() interface{} {
+func (w *Widget) GetUserData() interface{} {
 	return w.userData
 }
 
-func (w *Widget) setEnable(enable bool) *Widget {
+func (w *Widget) SetEnable(enable bool) *Widget {
 	w.enable = enable
 
 	return w
 }
 
+func (w *Widget) IsEnable() bool {
+	return w.enable
+}
+
+// SetCheckEnable installs a callback that is polled before every paint to
+// decide whether the widget is enabled.
+func (w *Widget) SetCheckEnable(checkEnable CheckEnable) *Widget {
+	w.checkEnable = checkEnable
+
+	return w
+}
+
 func (w *Widget) changeCursor() *Widget {
-	canvas := w.getCanvas()
-	if canvas.Style().GetPropertyValue("cursor") != w.cursor {
-		canvas.Style().SetProperty("cursor", w.cursor, "")
-	}
+	GetWindowManagerInstance().applyCursor(w.cursor)
 
 	return w
 }
@@ -739,9 +750,6 @@ func (w *Widget) setState(state string, recursive bool) *Widget {
 	if w.state != state {
 		w.state = state
 		w.onStateChanged(state)
-		if state == STATE_OVER {
-			GetWindowManagerInstance().setTipsWidget(w)
-		}
 	}
 
 	if recursive && w.target != nil {
@@ -751,7 +759,7 @@ func (w *Widget) setState(state string, recursive bool) *Widget {
 	return w
 }
 
-func (w *Widget) move(x, y int) *Widget {
+func (w *Widget) Move(x, y int) *Widget {
 	w.rect.X = x
 	w.rect.Y = y
 	if w.onMoved != nil {
@@ -777,14 +785,14 @@ func (w *Widget) MoveToCenter(moveX, moveY bool) *Widget {
 	return w
 }
 
-func (w *Widget) moveToBottom(border int) *Widget {
+func (w *Widget) MoveToBottom(border int) *Widget {
 	ph := w.parent.rect.H
 	w.rect.Y = ph - w.rect.H - border
 
 	return w
 }
 
-func (w *Widget) moveDelta(dx, dy int) *Widget {
+func (w *Widget) MoveDelta(dx, dy int) *Widget {
 	w.rect.X = w.rect.X + dx
 	w.rect.Y = w.rect.Y + dy
 	if w.onMoved != nil {
@@ -794,7 +802,7 @@ func (w *Widget) moveDelta(dx, dy int) *Widget {
 	return w
 }
 
-func (widget *Widget) resize(w, h int) *Widget {
+func (widget *Widget) Resize(w, h int) *Widget {
 	widget.rect.W = w
 	widget.rect.H = h
 	if widget.onSized != nil {
@@ -805,36 +813,86 @@ func (widget *Widget) resize(w, h int) *Widget {
 	return widget
 }
 
-func (w *Widget) setStateChangedHandler(stateChangedHandler StateChangedHandler) *Widget {
+func (w *Widget) SetStateChangedHandler(stateChangedHandler StateChangedHandler) *Widget {
 	w.stateChangedHandler = stateChangedHandler
 
 	return w
 }
 
-func (w *Widget) setContextMenuHandler(contextMenuHandler ContextMenuHandler) *Widget {
+func (w *Widget) SetContextMenuHandler(contextMenuHandler ContextMenuHandler) *Widget {
 	w.contextMenuHandler = contextMenuHandler
 
 	return w
 }
 
-func (w *Widget) setClickedHandler(clickedHandler ClickedHandler) *Widget {
+func (w *Widget) SetClickedHandler(clickedHandler ClickedHandler) *Widget {
 	w.clickedHandler = clickedHandler
 
 	return w
 }
 
-func (w *Widget) setKeyDownHandler(keyDownHandler KeyDownHandler) *Widget {
+func (w *Widget) SetKeyDownHandler(keyDownHandler KeyDownHandler) *Widget {
 	w.keyDownHandler = keyDownHandler
 
 	return w
 }
 
-func (w *Widget) setKeyUpHandler(keyUpHandler KeyUpHandler) *Widget {
+func (w *Widget) SetKeyUpHandler(keyUpHandler KeyUpHandler) *Widget {
 	w.keyUpHandler = keyUpHandler
 
 	return w
 }
 
+func (w *Widget) SetDoubleClickedHandler(doubleClickedHandler DoubleClickedHandler) *Widget {
+	w.doubleClickedHandler = doubleClickedHandler
+
+	return w
+}
+
+func (w *Widget) SetLongPressHandler(longPressHandler LongPressHandler) *Widget {
+	w.longPressHandler = longPressHandler
+
+	return w
+}
+
+func (w *Widget) SetWheelHandler(wheelHandler WheelHandler) *Widget {
+	w.wheelHandler = wheelHandler
+
+	return w
+}
+
+func (w *Widget) SetMovedHandler(onMoved OnMovedHandler) *Widget {
+	w.onMoved = onMoved
+
+	return w
+}
+
+func (w *Widget) SetSizedHandler(onSized OnResizedHandler) *Widget {
+	w.onSized = onSized
+
+	return w
+}
+
+// SetChangedHandler is called with the new value whenever a widget that
+// holds a value (text, check state, position...) changes it with notify set.
+func (w *Widget) SetChangedHandler(onChanged OnChangedHandler) *Widget {
+	w.onChanged = onChanged
+
+	return w
+}
+
+func (w *Widget) SetBeforePaintHandler(onBeforePaint OnBeforePaintHandler) *Widget {
+	w.onBeforePaint = onBeforePaint
+
+	return w
+}
+
+func (w *Widget) SetAfterPaintHandler(onAfterPaint OnAfterPaintHandler) *Widget {
+	w.onAfterPaint = onAfterPaint
+
+	return w
+}
+
 func (w *Widget) onClicked(point *structs.Point) bool {
 	if w.clickedHandler != nil {
 		w.clickedHandler(w, point)
@@ -845,7 +903,7 @@ func (w *Widget) onClicked(point *structs.Point) bool {
 	return w.clickedHandler != nil
 }
 
-func (w *Widget) lookup(id string, recursive bool) *Widget {
+func (w *Widget) Lookup(id string, recursive bool) *Widget {
 	for _, child := range w.children {
 		if child.id == id {
 			return child
@@ -854,7 +912,7 @@ func (w *Widget) lookup(id string, recursive bool) *Widget {
 
 	if recursive {
 		for _, child := range w.children {
-			ret := child.lookup(id, recursive)
+			ret := child.Lookup(id, recursive)
 			if ret != nil {
 				return ret
 			}
@@ -864,11 +922,11 @@ func (w *Widget) lookup(id string, recursive bool) *Widget {
 	return nil
 }
 
-func (w *Widget) onRelayout(context *dom.CanvasRenderingContext2D, force bool) {
+func (w *Widget) onRelayout(context canvas.Canvas2D, force bool) {
 
 }
 
-func (w *Widget) relayout(context *dom.CanvasRenderingContext2D, force bool) {
+func (w *Widget) relayout(context canvas.Canvas2D, force bool) {
 	if !w.needRelayout || !force || len(w.children) == 0 {
 		return
 	}
@@ -879,7 +937,20 @@ func (w *Widget) relayout(context *dom.CanvasRenderingContext2D, force bool) {
 	return
 }
 
-func (w *Widget) setLineWidth(lineWidth int) *Widget {
+// relayoutAll lays out the widget and its descendants again, whether or not
+// they asked for it.
+func (w *Widget) relayoutAll(context canvas.Canvas2D) {
+	w.needRelayout = true
+	w.I.relayout(context, true)
+
+	for _, child := range w.children {
+		child.relayoutAll(context)
+	}
+
+	return
+}
+
+func (w *Widget) SetLineWidth(lineWidth int) *Widget {
 	w.lineWidth = lineWidth
 
 	return w
@@ -892,7 +963,7 @@ func (w *Widget) getLineWidth(style *theme.ThemeStyle) int {
 	return 0
 }
 
-func (w *Widget) setRoundRadius(roundRadius int) *Widget {
+func (w *Widget) SetRoundRadius(roundRadius int) *Widget {
 	w.roundRadius = roundRadius
 
 	return w
@@ -917,7 +988,7 @@ func (w *Widget) getStyle(_state string) *theme.ThemeStyle {
 	}
 
 	if !w.enable {
-		if w.selectable && w.isSelected() {
+		if w.selectable && w.IsSelected() {
 			style = w.theme.StateSelected
 		} else {
 			style = w.theme.StateDisable
@@ -941,19 +1012,19 @@ func (w *Widget) getStyle(_state string) *theme.ThemeStyle {
 	return style
 }
 
-func (w *Widget) setImageDisplay(imageDisplay image.Display) *Widget {
+func (w *Widget) SetImageDisplay(imageDisplay image.Display) *Widget {
 	w.imageDisplay = imageDisplay
 
 	return w
 }
 
-func (w *Widget) setBorderStyle(borderStyle int) *Widget {
+func (w *Widget) SetBorderStyle(borderStyle int) *Widget {
 	w.borderStyle = borderStyle
 
 	return w
 }
 
-func (w *Widget) paintBackground(context *dom.CanvasRenderingContext2D) {
+func (w *Widget) paintBackground(context canvas.Canvas2D) {
 	style := w.getStyle("")
 	if style != nil {
 		if style.BgImage != nil {
@@ -964,7 +1035,7 @@ func (w *Widget) paintBackground(context *dom.CanvasRenderingContext2D) {
 	}
 }
 
-func (w *Widget) paintBackgroundImage(context *dom.CanvasRenderingContext2D, style *theme.ThemeStyle) {
+func (w *Widget) paintBackgroundImage(context canvas.Canvas2D, style *theme.ThemeStyle) {
 	dst := w.rect
 	bgImage := style.BgImage
 	imageDisplay := w.imageDisplay
@@ -982,35 +1053,35 @@ func (w *Widget) paintBackgroundImage(context *dom.CanvasRenderingContext2D, sty
 	return
 }
 
-func (widget *Widget) paintLeftBorder(context *dom.CanvasRenderingContext2D, w, h int) {
+func (widget *Widget) paintLeftBorder(context canvas.Canvas2D, w, h int) {
 	context.BeginPath()
 	context.MoveTo(0, 0)
 	context.LineTo(0, float64(h))
 	context.Stroke()
 }
 
-func (widget *Widget) paintRightBorder(context *dom.CanvasRenderingContext2D, w, h int) {
+func (widget *Widget) paintRightBorder(context canvas.Canvas2D, w, h int) {
 	context.BeginPath()
 	context.MoveTo(float64(w), 0)
 	context.LineTo(float64(w), float64(h))
 	context.Stroke()
 }
 
-func (widget *Widget) paintTopBorder(context *dom.CanvasRenderingContext2D, w, h int) {
+func (widget *Widget) paintTopBorder(context canvas.Canvas2D, w, h int) {
 	context.BeginPath()
 	context.MoveTo(0, 0)
 	context.LineTo(float64(w), 0)
 	context.Stroke()
 }
 
-func (widget *Widget) paintBottomBorder(context *dom.CanvasRenderingContext2D, w, h int) {
+func (widget *Widget) paintBottomBorder(context canvas.Canvas2D, w, h int) {
 	context.BeginPath()
 	context.MoveTo(0, float64(h))
 	context.LineTo(float64(w), float64(h))
 	context.Stroke()
 }
 
-func (w *Widget) paintBackgroundColor(context *dom.CanvasRenderingContext2D, style *theme.ThemeStyle) {
+func (w *Widget) paintBackgroundColor(context canvas.Canvas2D, style *theme.ThemeStyle) {
 	dst := w.rect
 	context.BeginPath()
 	if w.roundRadius != 0 {
@@ -1021,7 +1092,7 @@ func (w *Widget) paintBackgroundColor(context *dom.CanvasRenderingContext2D, sty
 	}
 
 	if style.FillColor != "" {
-		context.FillStyle = style.FillColor
+		context.SetFillStyle(style.FillColor)
 		context.Fill()
 	}
 
@@ -1031,10 +1102,10 @@ func (w *Widget) paintBackgroundColor(context *dom.CanvasRenderingContext2D, sty
 		return
 	}
 
-	width := w.getWidth()
-	height := w.getHeight()
-	context.LineWidth = lineWidth
-	context.StrokeStyle = style.LineColor
+	width := w.GetWidth()
+	height := w.GetHeight()
+	context.SetLineWidth(lineWidth)
+	context.SetStrokeStyle(style.LineColor)
 	if w.borderStyle == BORDER_STYLE_ALL {
 		context.Stroke()
 		context.BeginPath()
@@ -1061,32 +1132,32 @@ func (w *Widget) paintBackgroundColor(context *dom.CanvasRenderingContext2D, sty
 	return
 }
 
-func (w *Widget) paintSelf(context *dom.CanvasRenderingContext2D) {
+func (w *Widget) paintSelf(context canvas.Canvas2D) {
 	fmt.Printf("%s paintSelf\n", w.t)
 	return
 }
 
-func (w *Widget) beforePaint(context *dom.CanvasRenderingContext2D) {
+func (w *Widget) beforePaint(context canvas.Canvas2D) {
 	if w.onBeforePaint != nil {
 		w.onBeforePaint(context)
 	}
 	return
 }
 
-func (w *Widget) afterPaint(context *dom.CanvasRenderingContext2D) {
+func (w *Widget) afterPaint(context canvas.Canvas2D) {
 	if w.onAfterPaint != nil {
 		w.onAfterPaint(context)
 	}
 	return
 }
 
-func (w *Widget) setPaintFocusLater(paintFocusLater bool) *Widget {
+func (w *Widget) SetPaintFocusLater(paintFocusLater bool) *Widget {
 	w.paintFocusLater = paintFocusLater
 
 	return w
 }
 
-func (w *Widget) paintChildren(context *dom.CanvasRenderingContext2D) {
+func (w *Widget) paintChildren(context canvas.Canvas2D) {
 	fmt.Printf("%s paintChildren\n", w.t)
 	if w.paintFocusLater {
 		w.paintChildrenFocusLater(context)
@@ -1097,7 +1168,7 @@ func (w *Widget) paintChildren(context *dom.CanvasRenderingContext2D) {
 	return
 }
 
-func (w *Widget) paintChildrenDefault(context *dom.CanvasRenderingContext2D) {
+func (w *Widget) paintChildrenDefault(context canvas.Canvas2D) {
 	for _, child := range w.children {
 		child.I.draw(context)
 	}
@@ -1105,7 +1176,7 @@ func (w *Widget) paintChildrenDefault(context *dom.CanvasRenderingContext2D) {
 	return
 }
 
-func (w *Widget) paintChildrenFocusLater(context *dom.CanvasRenderingContext2D) {
+func (w *Widget) paintChildrenFocusLater(context canvas.Canvas2D) {
 	var focusChild *Widget
 	for _, child := range w.children {
 		if child.state == STATE_OVER || child.state == STATE_ACTIVE {
@@ -1126,14 +1197,14 @@ func (w *Widget) ensureImages() {
 	return
 }
 
-func (w *Widget) draw(context *dom.CanvasRenderingContext2D) {
+func (w *Widget) draw(context canvas.Canvas2D) {
 	if !w.visible {
 		return
 	}
 	fmt.Printf("draw: %s\n", w.t)
 
 	if w.checkEnable != nil {
-		w.setEnable(w.checkEnable())
+		w.SetEnable(w.checkEnable())
 	}
 
 	w.I.ensureImages()
@@ -1154,13 +1225,13 @@ func (w *Widget) draw(context *dom.CanvasRenderingContext2D) {
 	return
 }
 
-func (w *Widget) setVisible(visible bool) *Widget {
+func (w *Widget) SetVisible(visible bool) *Widget {
 	w.visible = visible
 
 	return w
 }
 
-func (w *Widget) isVisible() bool {
+func (w *Widget) IsVisible() bool {
 	return w.visible
 }
 
@@ -1168,7 +1239,7 @@ func (w *Widget) onShow(visible bool) bool {
 	return true
 }
 
-func (w *Widget) show(visible bool) *Widget {
+func (w *Widget) Show(visible bool) *Widget {
 	if visible != w.visible {
 		w.visible = visible
 		w.onShow(visible)
@@ -1177,10 +1248,10 @@ func (w *Widget) show(visible bool) *Widget {
 	return w
 }
 
-func (w *Widget) showAll(visible bool) *Widget {
-	w.show(visible)
+func (w *Widget) ShowAll(visible bool) *Widget {
+	w.Show(visible)
 	for _, child := range w.children {
-		child.showAll(visible)
+		child.ShowAll(visible)
 	}
 
 	if w.parent != nil {
@@ -1200,15 +1271,18 @@ func (w *Widget) selectAllChildren(selected bool) *Widget {
 	return w
 }
 
-func (w *Widget) closeWindow(retInfo interface{}) *Widget {
-	//TODO
-	// w.getWindow().close(retInfo)
+// CloseWindow closes the window containing the widget, passing retInfo on to
+// Window.Close.
+func (w *Widget) CloseWindow(retInfo interface{}) *Widget {
+	if window := w.GetWindow(); window != nil {
+		window.Close(retInfo)
+	}
 
 	return w
 }
 
 func (w *Widget) findTarget(point *structs.Point) *Widget {
-	p := w.getAbsPosition()
+	p := w.GetAbsPosition()
 	w.point.X = point.X - p.X
 	w.point.Y = point.Y - p.Y
 
@@ -1239,7 +1313,7 @@ func (w *Widget) onPointerDown(point *structs.Point) {
 
 	if target != nil {
 		target.setState(STATE_ACTIVE, false)
-		target.onPointerDown(point)
+		target.I.onPointerDown(point)
 	} else {
 		w.changeCursor()
 	}
@@ -1272,6 +1346,7 @@ func (w *Widget) onPointerMove(point *structs.Point) {
 		} else {
 			target.setState(STATE_OVER, false)
 		}
+		target.I.onPointerMove(point)
 	} else {
 		w.changeCursor()
 	}
@@ -1288,14 +1363,14 @@ func (w *Widget) onPointerUp(point *structs.Point) {
 	}
 
 	target := w.findTarget(point)
-	if target != nil && w.target != target {
+	if target != nil && w.target != nil && w.target != target {
 		w.target.setState(STATE_NORMAL, false)
-		w.target.onPointerUp(point)
+		w.target.I.onPointerUp(point)
 	}
 
 	if target != nil {
 		target.setState(STATE_OVER, false)
-		target.onPointerUp(point)
+		target.I.onPointerUp(point)
 	} else {
 		w.changeCursor()
 	}
@@ -1319,7 +1394,7 @@ func (w *Widget) onPointerUp(point *structs.Point) {
 
 func (w *Widget) onKeyDown(code int) {
 	if w.target != nil {
-		w.target.onKeyDown(code)
+		w.target.I.onKeyDown(code)
 	}
 
 	if w.keyDownHandler != nil {
@@ -1333,7 +1408,7 @@ func (w *Widget) onKeyDown(code int) {
 
 func (w *Widget) onKeyUp(code int) {
 	if w.target != nil {
-		w.target.onKeyUp(code)
+		w.target.I.onKeyUp(code)
 	}
 
 	if w.keyUpHandler != nil {
@@ -1345,9 +1420,17 @@ func (w *Widget) onKeyUp(code int) {
 	return
 }
 
+func (w *Widget) onKeyPress(char rune) {
+	if w.target != nil {
+		w.target.I.onKeyPress(char)
+	}
+
+	return
+}
+
 func (w *Widget) onWheel(delta float64) bool {
 	if w.target != nil {
-		return w.target.onWheel(delta)
+		return w.target.I.onWheel(delta)
 	}
 
 	if w.wheelHandler != nil {
@@ -1360,7 +1443,7 @@ func (w *Widget) onWheel(delta float64) bool {
 func (w *Widget) onDoubleClick(point *structs.Point) {
 	target := w.findTarget(point)
 	if target != nil {
-		target.onDoubleClick(point)
+		target.I.onDoubleClick(point)
 		w.target = target
 	}
 
@@ -1375,8 +1458,11 @@ func (w *Widget) onContextMenu(point *structs.Point) {
 	target := w.findTarget(point)
 
 	if target != nil {
-		target.onContextMenu(point)
+		target.I.onContextMenu(point)
 		w.target = target
+		if target.hasContextMenuAt(point) {
+			return
+		}
 	}
 
 	if w.state != STATE_DISABLE && w.contextMenuHandler != nil {
@@ -1386,11 +1472,23 @@ func (w *Widget) onContextMenu(point *structs.Point) {
 	return
 }
 
+// hasContextMenuAt tells whether the widget or one of its descendants under
+// point handles context menus, so that only the innermost handler fires.
+func (w *Widget) hasContextMenuAt(point *structs.Point) bool {
+	for iter := w; iter != nil; iter = iter.findTarget(point) {
+		if iter.state != STATE_DISABLE && iter.contextMenuHandler != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (w *Widget) onLongPress(point *structs.Point) {
 	target := w.findTarget(point)
 
 	if target != nil {
-		target.onLongPress(point)
+		target.I.onLongPress(point)
 		w.target = target
 	}
 
@@ -1401,7 +1499,7 @@ func (w *Widget) onLongPress(point *structs.Point) {
 	return
 }
 
-func (w *Widget) setCursor(cursor string) *Widget {
+func (w *Widget) SetCursor(cursor string) *Widget {
 	w.cursor = cursor
 
 	return w
@@ -2,18 +2,24 @@ package gwk
 
 import (
 	"fmt"
-	"github.com/Luncher/gwk/pkg/rt"
+	"github.com/Luncher/gwk/pkg/canvas"
 	"github.com/Luncher/gwk/pkg/structs"
 	"honnef.co/go/js/dom"
 	"math"
-	"time"
 )
 
-type WindowCloseHandler func()
+type WindowCloseHandler func(retInfo interface{})
+
+// shortcutHandler is implemented by containers that handle some keys, like
+// Ctrl+Tab in a TabControl, before the widget with the focus inside them.
+type shortcutHandler interface {
+	onShortcut(code int) bool
+}
 
 type Window struct {
 	*Widget
 	grabWidget   *Widget
+	focusWidget  *Widget
 	closeHandler WindowCloseHandler
 	manager      *WindowManager
 	downPosition structs.Position
@@ -22,10 +28,22 @@ type Window struct {
 }
 
 func NewWindow(manager *WindowManager, x, y, w, h float32) *Window {
+	window := newPopupWindow(manager, x, y, w, h)
+	window.manager.addWindow(window)
+
+	return window
+}
+
+// newPopupWindow creates a window of manager without adding it to the
+// windows of the manager, for the popups that are only added while they are
+// shown. Adding a window resets the pointer target, which would drop the
+// hover state of the window the popup is built from.
+func newPopupWindow(manager *WindowManager, x, y, w, h float32) *Window {
 	window := &Window{
 		Widget: NewWidget(TYPE_WINDOW, nil, x, y, w, h),
 	}
 	window.I = window
+	window.window = window
 
 	if manager != nil {
 		window.manager = manager
@@ -33,31 +51,65 @@ func NewWindow(manager *WindowManager, x, y, w, h float32) *Window {
 		window.manager = GetWindowManagerInstance()
 	}
 
-	time.AfterFunc(time.Millisecond*10, func() {
-		manager.addWindow(window)
-	})
-
 	return window
 }
 
-func (w *Window) getWindow() *Window {
+func (w *Window) GetWindow() *Window {
 	return w
 }
 
-func (window *Window) grab(widget *Widget) *Window {
+func (window *Window) SetCloseHandler(closeHandler WindowCloseHandler) *Window {
+	window.closeHandler = closeHandler
+
+	return window
+}
+
+// Grab sends all the pointer and key input of the window manager to widget
+// until Ungrab is called.
+func (window *Window) Grab(widget *Widget) *Window {
+	if window.grabWidget == nil {
+		window.manager.Grab(window)
+	}
 	window.grabWidget = widget
-	window.manager.grab(window)
 
 	return window
 }
 
-func (window *Window) ungrab() *Window {
-	window.grabWidget = nil
+func (window *Window) Ungrab() *Window {
+	if window.grabWidget != nil {
+		window.grabWidget = nil
+		window.manager.Ungrab(window)
+	}
+
 	return window
 }
 
-func (window *Window) moveToCenter() *Window {
-	width, height := rt.GetRTInstance().GetViewPort()
+// SetFocus makes widget receive the keyboard input of the window, or clears
+// the focus when widget is nil. The focused widget is marked as editing.
+func (window *Window) SetFocus(widget *Widget) *Window {
+	if window.focusWidget == widget {
+		return window
+	}
+
+	if window.focusWidget != nil {
+		window.focusWidget.editing = false
+	}
+
+	window.focusWidget = widget
+	if widget != nil {
+		widget.editing = true
+	}
+	window.PostRedraw()
+
+	return window
+}
+
+func (window *Window) GetFocus() *Widget {
+	return window.focusWidget
+}
+
+func (window *Window) MoveToCenter() *Window {
+	width, height := window.manager.getViewPort()
 	var sw = math.Min(float64(window.manager.w), float64(width))
 	var sh = math.Min(float64(window.manager.h), float64(height))
 
@@ -76,9 +128,10 @@ func (window *Window) onPointerDown(point *structs.Point) {
 	window.downPosition.Y = point.Y
 	window.lastPosition.X = point.X
 	window.lastPosition.Y = point.Y
+	window.SetFocus(nil)
 
 	if window.grabWidget != nil {
-		window.grabWidget.onPointerDown(point)
+		window.grabWidget.I.onPointerDown(point)
 	} else {
 		window.Widget.onPointerDown(point)
 	}
@@ -95,7 +148,7 @@ func (window *Window) onPointerMove(point *structs.Point) {
 
 	fmt.Printf("window onPointerMove \n")
 	if window.grabWidget != nil {
-		window.grabWidget.onPointerMove(point)
+		window.grabWidget.I.onPointerMove(point)
 	} else {
 		window.Widget.onPointerMove(point)
 	}
@@ -110,7 +163,7 @@ func (window *Window) onPointerUp(point *structs.Point) {
 	window.upPosition.Y = point.Y
 
 	if window.grabWidget != nil {
-		window.grabWidget.onPointerUp(point)
+		window.grabWidget.I.onPointerUp(point)
 	} else {
 		window.Widget.onPointerUp(point)
 	}
@@ -123,11 +176,13 @@ func (window *Window) onPointerUp(point *structs.Point) {
 
 func (window *Window) onDoubleClick(point *structs.Point) {
 	if window.grabWidget != nil {
-		window.grabWidget.onDoubleClick(point)
+		window.grabWidget.I.onDoubleClick(point)
 		window.target = window.grabWidget
 		if window.state != STATE_DISABLE && window.doubleClickedHandler != nil {
 			window.doubleClickedHandler(point)
 		}
+	} else {
+		window.Widget.onDoubleClick(point)
 	}
 }
 
@@ -140,7 +195,7 @@ func (window *Window) isClicked() bool {
 
 func (window *Window) onContextMenu(point *structs.Point) {
 	if window.grabWidget != nil {
-		window.grabWidget.onContextMenu(point)
+		window.grabWidget.I.onContextMenu(point)
 	} else {
 		window.Widget.onContextMenu(point)
 	}
@@ -148,9 +203,32 @@ func (window *Window) onContextMenu(point *structs.Point) {
 	return
 }
 
+// dispatchShortcut offers code to the focus widget and its ancestors, or to
+// the pointer targets without a focus widget, innermost first, and reports
+// whether one of them handled it.
+func (window *Window) dispatchShortcut(code int) bool {
+	w := window.focusWidget
+	if w == nil {
+		for w = window.Widget; w.target != nil; w = w.target {
+		}
+	}
+
+	for ; w != nil; w = w.parent {
+		if handler, ok := w.I.(shortcutHandler); ok && handler.onShortcut(code) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (window *Window) onKeyDown(code int) {
 	if window.grabWidget != nil {
-		window.grabWidget.onKeyDown(code)
+		window.grabWidget.I.onKeyDown(code)
+	} else if window.dispatchShortcut(code) {
+		return
+	} else if window.focusWidget != nil {
+		window.focusWidget.I.onKeyDown(code)
 	} else {
 		window.Widget.onKeyDown(code)
 	}
@@ -160,7 +238,9 @@ func (window *Window) onKeyDown(code int) {
 
 func (window *Window) onKeyUp(code int) {
 	if window.grabWidget != nil {
-		window.grabWidget.onKeyUp(code)
+		window.grabWidget.I.onKeyUp(code)
+	} else if window.focusWidget != nil {
+		window.focusWidget.I.onKeyUp(code)
 	} else {
 		window.Widget.onKeyUp(code)
 	}
@@ -168,29 +248,43 @@ func (window *Window) onKeyUp(code int) {
 	return
 }
 
-func (window *Window) beforePaint(ctx *dom.CanvasRenderingContext2D) {
+func (window *Window) onKeyPress(char rune) {
+	if window.grabWidget != nil {
+		window.grabWidget.I.onKeyPress(char)
+	} else if window.focusWidget != nil {
+		window.focusWidget.I.onKeyPress(char)
+	} else {
+		window.Widget.onKeyPress(char)
+	}
+
+	return
+}
+
+func (window *Window) beforePaint(ctx canvas.Canvas2D) {
 	ctx.BeginPath()
 	ctx.ClearRect(0, 0, float64(window.rect.W), float64(window.rect.H))
 	return
 }
 
-func (window *Window) show(visible bool) {
-	window.Widget.show(visible)
+func (window *Window) Show(visible bool) {
+	window.Widget.Show(visible)
 }
 
-func (window *Window) close(retInfo interface{}) {
+// Close removes the window from its manager and then passes retInfo to the
+// close handler.
+func (window *Window) Close(retInfo interface{}) {
+	window.manager.Ungrab(window)
+	window.manager.removeWindow(window)
+	window.Destroy()
+
 	if window.closeHandler != nil {
-		window.closeHandler()
+		window.closeHandler(retInfo)
 	}
 
-	window.manager.ungrab(window)
-	window.manager.removeWindow(window)
-	window.destroy()
-
 	return
 }
 
-func (window *Window) getCanvas2D() *dom.CanvasRenderingContext2D {
+func (window *Window) getCanvas2D() canvas.Canvas2D {
 	return GetWindowManagerInstance().getCanvas2D()
 }
 
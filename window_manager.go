@@ -2,7 +2,9 @@ package gwk
 
 import (
 	"fmt"
+	"github.com/Luncher/gwk/pkg/canvas"
 	"github.com/Luncher/gwk/pkg/event"
+	"github.com/Luncher/gwk/pkg/keyevent"
 	"github.com/Luncher/gwk/pkg/structs"
 	"honnef.co/go/js/dom"
 	"math"
@@ -37,33 +39,40 @@ type WindowManager struct {
 	yInputScale        float32
 	maxFpsMode         bool
 	shouldShowFPS      bool
-	tipsWidget         *Widget
+	tips               tipsManager
 	needRedraw         int
-	ctx                *dom.CanvasRenderingContext2D
+	backend            Backend
+	ctrlDown           bool
+	altDown            bool
+	shiftDown          bool
+	clipboard          string
+	state              map[string]string
+	urlOpener          func(url string)
 }
 
 var manager = &WindowManager{}
 
 func NewWindowManager(app *Application, canvas dom.HTMLCanvasElement, eventElement dom.HTMLCanvasElement) *WindowManager {
 	event.SetEventsConsumer(event.EventConsumer(manager), eventElement)
-	return manager.init(app, canvas)
+	manager.canvas = canvas
+	return manager.init(app, newDOMBackend(canvas))
 }
 
 func GetWindowManagerInstance() *WindowManager {
 	return manager
 }
 
-func (manager *WindowManager) init(app *Application, canvas dom.HTMLCanvasElement) *WindowManager {
+func (manager *WindowManager) init(app *Application, backend Backend) *WindowManager {
 	manager.app = app
-	manager.canvas = canvas
-	manager.w = canvas.Width
-	manager.h = canvas.Height
+	manager.backend = backend
+	manager.w, manager.h = backend.GetSize()
 	manager.enablePaint = true
+	manager.tips.delay = TIPS_DELAY
 
 	return manager
 }
 
-func (manager *WindowManager) getApp() *Application {
+func (manager *WindowManager) GetApp() *Application {
 	return manager.app
 }
 
@@ -90,23 +99,33 @@ func (manager *WindowManager) getCanvas() *dom.HTMLCanvasElement {
 	return &manager.canvas
 }
 
-func (manager *WindowManager) getWidget() int {
+func (manager *WindowManager) getViewPort() (int, int) {
+	return manager.backend.GetViewPort()
+}
+
+func (manager *WindowManager) applyCursor(cursor string) {
+	manager.backend.SetCursor(cursor)
+
+	return
+}
+
+func (manager *WindowManager) GetWidth() int {
 	return manager.w
 }
 
-func (manager *WindowManager) getHeight() int {
+func (manager *WindowManager) GetHeight() int {
 	return manager.h
 }
 
 func (manager *WindowManager) findTargetWin(point *structs.Point) *Window {
-	for _, window := range manager.grabWindows {
-		if window.visible {
+	for i := len(manager.grabWindows) - 1; i >= 0; i-- {
+		if window := manager.grabWindows[i]; window.visible {
 			return window
 		}
 	}
 
-	for _, window := range manager.windows {
-		if window.visible {
+	for i := len(manager.windows) - 1; i >= 0; i-- {
+		if window := manager.windows[i]; window.visible {
 			if isPointInRect(point, window.rect) {
 				return window
 			}
@@ -116,22 +135,22 @@ func (manager *WindowManager) findTargetWin(point *structs.Point) *Window {
 	return nil
 }
 
-func (manager *WindowManager) resize(w, h int) {
+func (manager *WindowManager) Resize(w, h int) {
 	manager.w = w
 	manager.h = h
 
-	manager.postRedraw()
+	manager.PostRedraw()
 
 	return
 }
 
-func (manager *WindowManager) grab(window *Window) {
+func (manager *WindowManager) Grab(window *Window) {
 	manager.grabWindows = append(manager.grabWindows, window)
 
 	return
 }
 
-func (manager *WindowManager) ungrab(window *Window) {
+func (manager *WindowManager) Ungrab(window *Window) {
 	for i, win := range manager.grabWindows {
 		if window == win {
 			manager.grabWindows = append(manager.grabWindows[:i], manager.grabWindows[i+1:]...)
@@ -147,7 +166,7 @@ func (manager *WindowManager) OnDoubleClick(point *structs.Point) {
 	manager.target = manager.findTargetWin(point)
 
 	if manager.target != nil {
-		manager.target.onDoubleClick(point)
+		manager.target.I.onDoubleClick(point)
 	} else {
 		fmt.Printf("Window Manager: no target for x=%d, y=%d", point.X, point.Y)
 	}
@@ -159,7 +178,7 @@ func (manager *WindowManager) onLongPress(point *structs.Point) {
 	manager.target = manager.findTargetWin(point)
 
 	if manager.target != nil {
-		manager.target.onLongPress(point)
+		manager.target.I.onLongPress(point)
 	} else {
 		fmt.Printf("Window Manager: no target for x=%d, y=%d", point.X, point.Y)
 	}
@@ -167,14 +186,14 @@ func (manager *WindowManager) onLongPress(point *structs.Point) {
 	return
 }
 
-func (manager *WindowManager) setInputOffset(xInputOffset, yInputOffset int) {
+func (manager *WindowManager) SetInputOffset(xInputOffset, yInputOffset int) {
 	manager.xInputOffset = xInputOffset
 	manager.yInputOffset = yInputOffset
 
 	return
 }
 
-func (manager *WindowManager) setInputScale(xInputScale, yInputScale float32) {
+func (manager *WindowManager) SetInputScale(xInputScale, yInputScale float32) {
 	manager.xInputScale = xInputScale
 	manager.yInputScale = yInputScale
 
@@ -215,6 +234,7 @@ func (manager *WindowManager) OnPointerDown(point *structs.Point) {
 		}
 	}
 
+	manager.hideTips()
 	manager.pointerDown = true
 	manager.pointerDownPoint.X = point.X
 	manager.pointerDownPoint.Y = point.Y
@@ -222,7 +242,7 @@ func (manager *WindowManager) OnPointerDown(point *structs.Point) {
 	manager.lastPointerPoint.Y = point.Y
 
 	if manager.target != nil {
-		manager.target.onPointerDown(point)
+		manager.target.I.onPointerDown(point)
 	} else {
 		fmt.Printf("Window Manager: no target for x=%d y=%d\n", point.X, point.Y)
 	}
@@ -238,12 +258,13 @@ func (manager *WindowManager) OnPointerMove(point *structs.Point) {
 	manager.lastPointerPoint.Y = point.Y
 
 	if manager.target != nil && manager.target != target {
-		manager.target.onPointerMove(point)
+		manager.target.I.onPointerMove(point)
 	}
 	manager.target = target
 	if manager.target != nil {
-		manager.target.onPointerMove(point)
+		manager.target.I.onPointerMove(point)
 	}
+	manager.updateTips()
 
 	return
 }
@@ -254,7 +275,7 @@ func (manager *WindowManager) OnPointerUp(point *structs.Point) {
 	manager.target = manager.findTargetWin(point)
 
 	if manager.target != nil {
-		manager.target.onPointerUp(point)
+		manager.target.I.onPointerUp(point)
 	} else {
 		fmt.Printf("Window Manager: no target for x=%d y=%d\n", point.X, point.Y)
 	}
@@ -279,28 +300,96 @@ func (manager *WindowManager) isClicked() bool {
 }
 
 func (manager *WindowManager) isCtrlDown() bool {
-	if manager.currentEvent != nil {
-		if keyEvent, ok := manager.currentEvent.(dom.KeyboardEvent); ok {
-			return keyEvent.CtrlKey
-		}
-	}
-	return false
+	return manager.ctrlDown
 }
 
 func (manager *WindowManager) isAltDown() bool {
-	if manager.currentEvent != nil {
-		if keyEvent, ok := manager.currentEvent.(dom.KeyboardEvent); ok {
-			return keyEvent.AltKey
+	return manager.altDown
+}
+
+func (manager *WindowManager) isShiftDown() bool {
+	return manager.shiftDown
+}
+
+func (manager *WindowManager) updateModifiers(code int, down bool) {
+	switch code {
+	case keyevent.DOM_VK_CONTROL, keyevent.DOM_VK_META:
+		manager.ctrlDown = down
+	case keyevent.DOM_VK_ALT:
+		manager.altDown = down
+	case keyevent.DOM_VK_SHIFT:
+		manager.shiftDown = down
+	}
+
+	return
+}
+
+// SetClipboardText stores text in the clipboard shared by the editors of this
+// window manager. The clipboard lives in the process and is not synchronized
+// with the system clipboard.
+func (manager *WindowManager) SetClipboardText(text string) *WindowManager {
+	manager.clipboard = text
+
+	return manager
+}
+
+func (manager *WindowManager) GetClipboardText() string {
+	return manager.clipboard
+}
+
+// SaveState keeps value under key, in the backend when it is a StateStore so
+// it outlives the page, and in the process otherwise.
+func (manager *WindowManager) SaveState(key, value string) *WindowManager {
+	if store, ok := manager.backend.(StateStore); ok {
+		store.SaveState(key, value)
+	} else {
+		if manager.state == nil {
+			manager.state = make(map[string]string)
 		}
+		manager.state[key] = value
 	}
-	return false
+
+	return manager
+}
+
+func (manager *WindowManager) LoadState(key string) (string, bool) {
+	if store, ok := manager.backend.(StateStore); ok {
+		return store.LoadState(key)
+	}
+
+	value, ok := manager.state[key]
+
+	return value, ok
+}
+
+// SetURLOpener replaces the backend in opening the URLs of links, or restores
+// it when opener is nil.
+func (manager *WindowManager) SetURLOpener(opener func(url string)) *WindowManager {
+	manager.urlOpener = opener
+
+	return manager
+}
+
+// OpenURL shows url to the user with the URL opener, or the backend when it is
+// a URLOpener.
+func (manager *WindowManager) OpenURL(url string) *WindowManager {
+	if manager.urlOpener != nil {
+		manager.urlOpener(url)
+	} else if opener, ok := manager.backend.(URLOpener); ok {
+		opener.OpenURL(url)
+	} else {
+		fmt.Printf("Window Manager: no URL opener for %s\n", url)
+	}
+
+	return manager
 }
 
 func (manager *WindowManager) OnContextMenu(point *structs.Point) {
+	manager.translatePoint(point)
 	manager.target = manager.findTargetWin(point)
 
 	if manager.target != nil {
-		manager.target.onContextMenu(point)
+		manager.target.I.onContextMenu(point)
 	} else {
 		fmt.Printf("Window Manager: no target for x=%d y=%d\n", point.X, point.Y)
 	}
@@ -309,39 +398,58 @@ func (manager *WindowManager) OnContextMenu(point *structs.Point) {
 }
 
 func (manager *WindowManager) OnKeyDown(code int) {
+	manager.updateModifiers(code, true)
 	if manager.target == nil {
 		manager.target = manager.findTargetWin(&structs.Point{X: 50, Y: 50})
 	}
 
 	if manager.target != nil {
-		manager.target.onKeyDown(code)
+		manager.target.I.onKeyDown(code)
 	}
 
 	return
 }
 
 func (manager *WindowManager) OnKeyUp(code int) {
+	manager.updateModifiers(code, false)
 	if manager.target != nil {
-		manager.target.onKeyUp(code)
+		manager.target.I.onKeyUp(code)
+	}
+
+	return
+}
+
+func (manager *WindowManager) OnKeyPress(char rune) {
+	if manager.target != nil {
+		manager.target.I.onKeyPress(char)
 	}
 
 	return
 }
 
 func (manager *WindowManager) OnWheel(delta float64) {
-	manager.postRedraw()
+	manager.hideTips()
+	manager.PostRedraw()
 
 	if manager.target == nil {
 		manager.target = manager.findTargetWin(&structs.Point{X: 50, Y: 50})
 	}
 
 	if manager.target != nil {
-		manager.target.onWheel(delta)
+		manager.target.I.onWheel(delta)
 	}
 
 	return
 }
 
+// Inject feeds a synthetic event through the same path as DOM input, so
+// scripted tests and replayed recordings exercise the real handlers.
+func (manager *WindowManager) Inject(e *event.Event) {
+	event.Dispatch(manager, e)
+
+	return
+}
+
 func (manager *WindowManager) dispatchPointerMoveOut() *WindowManager {
 	manager.OnPointerMove(&structs.Point{X: -1, Y: -1})
 	manager.target = nil
@@ -366,13 +474,13 @@ func (manager *WindowManager) addWindow(win *Window) {
 	manager.dispatchPointerMoveOut()
 	manager.target = win
 	manager.windows = append(manager.windows, win)
-	manager.postRedraw()
+	manager.PostRedraw()
 
 	return
 }
 
 func (manager *WindowManager) removeWindow(win *Window) {
-	manager.ungrab(win)
+	manager.Ungrab(win)
 
 	if manager.target == win {
 		manager.target = nil
@@ -384,7 +492,7 @@ func (manager *WindowManager) removeWindow(win *Window) {
 			break
 		}
 	}
-	manager.postRedraw()
+	manager.PostRedraw()
 
 	return
 }
@@ -401,13 +509,13 @@ func (manager *WindowManager) getFrameRate() int {
 	return int(fps)
 }
 
-func (manager *WindowManager) setMaxFPSMode(maxFpsMode bool) *WindowManager {
+func (manager *WindowManager) SetMaxFPSMode(maxFpsMode bool) *WindowManager {
 	manager.maxFpsMode = maxFpsMode
 
 	return manager
 }
 
-func (manager *WindowManager) showFPS(shouldShowFPS bool) *WindowManager {
+func (manager *WindowManager) ShowFPS(shouldShowFPS bool) *WindowManager {
 	manager.drawCount = 1
 	manager.startTime = time.Now()
 	manager.shouldShowFPS = shouldShowFPS
@@ -415,15 +523,15 @@ func (manager *WindowManager) showFPS(shouldShowFPS bool) *WindowManager {
 	return manager
 }
 
-func (manager *WindowManager) getPaintEnable() bool {
+func (manager *WindowManager) GetPaintEnable() bool {
 	return manager.enablePaint
 }
 
-func (manager *WindowManager) setPaintEnable(enablePaint bool) *WindowManager {
+func (manager *WindowManager) SetPaintEnable(enablePaint bool) *WindowManager {
 	manager.enablePaint = enablePaint
 
 	if manager.enablePaint {
-		manager.postRedraw()
+		manager.PostRedraw()
 	}
 
 	return manager
@@ -437,7 +545,7 @@ func (manager *WindowManager) onDrawFrame() {
 	return
 }
 
-func (manager *WindowManager) postRedraw() {
+func (manager *WindowManager) PostRedraw() {
 	if !manager.enablePaint {
 		return
 	}
@@ -445,48 +553,29 @@ func (manager *WindowManager) postRedraw() {
 	fmt.Printf("postRedraw \n")
 	manager.requestCount++
 	if manager.requestCount < 2 {
-		dom.GetWindow().RequestAnimationFrame(func(d time.Duration) {
-			fmt.Printf("RequestAnimationFrame %v\n", d)
-			manager.onDrawFrame()
-		})
+		manager.backend.RequestAnimationFrame(manager.onDrawFrame)
 	}
 
 	return
 }
 
-func (manager *WindowManager) setTipsWidget(widget *Widget) {
-	manager.tipsWidget = widget
-
-	return
-}
-
-func (manager *WindowManager) drawTips(context *dom.CanvasRenderingContext2D) {
-	tipsWidget := manager.tipsWidget
-	if tipsWidget == nil || tipsWidget.parent == nil {
-		return
-	}
-
-	hideTipsCanvas()
-	p := tipsWidget.getPositionInView()
-
-	context.Save()
-	context.Translate(float64(p.X), float64(p.Y))
-	context.BeginPath()
-	tipsWidget.drawTips(context)
-	context.Restore()
+// keepAnimating asks for another frame after the one being drawn, for the
+// widgets animating themselves while they paint.
+func (manager *WindowManager) keepAnimating() {
+	manager.needRedraw++
 
 	return
 }
 
-func (manager *WindowManager) beforeDrawWindows(context *dom.CanvasRenderingContext2D) {
+func (manager *WindowManager) beforeDrawWindows(context canvas.Canvas2D) {
 
 }
 
-func (manager *WindowManager) afterDrawWindows(context *dom.CanvasRenderingContext2D) {
+func (manager *WindowManager) afterDrawWindows(context canvas.Canvas2D) {
 
 }
 
-func (manager *WindowManager) drawWindows(context *dom.CanvasRenderingContext2D) {
+func (manager *WindowManager) drawWindows(context canvas.Canvas2D) {
 	fmt.Printf("drawWindows \n")
 
 	manager.beforeDrawWindows(context)
@@ -503,15 +592,11 @@ func (manager *WindowManager) checkNeedRedraw(timeStep float64) bool {
 	return true
 }
 
-func (manager *WindowManager) getCanvas2D() *dom.CanvasRenderingContext2D {
-	if manager.ctx == nil {
-		manager.ctx = manager.canvas.GetContext2d()
-	}
-
-	return manager.ctx
+func (manager *WindowManager) getCanvas2D() canvas.Canvas2D {
+	return manager.backend.GetCanvas2D()
 }
 
-func (manager *WindowManager) doDraw(ctx *dom.CanvasRenderingContext2D) {
+func (manager *WindowManager) doDraw(ctx canvas.Canvas2D) {
 	now := time.Now()
 	timeStep := now.Sub(manager.lastUpdateTime)
 	fmt.Printf("doDraw \n")
@@ -529,26 +614,37 @@ func (manager *WindowManager) doDraw(ctx *dom.CanvasRenderingContext2D) {
 		w, h := 100, 30
 		ctx.BeginPath()
 		ctx.Rect(0, 0, float64(w), float64(h))
-		ctx.FillStyle = "Black"
+		ctx.SetFillStyle("Black")
 		ctx.Fill()
 
 		ctx.Save()
-		ctx.TextAlign = "center"
-		ctx.TextBaseline = "middle"
-		ctx.Font = "20px Sans"
-		ctx.FillStyle = "White"
+		ctx.SetTextAlign("center")
+		ctx.SetTextBaseline("middle")
+		ctx.SetFont("20px Sans")
+		ctx.SetFillStyle("White")
 		ctx.FillText(strconv.Itoa(str), float64(w>>1), float64(h>>1), -1)
 		ctx.Restore()
 	}
 
 	if manager.maxFpsMode || manager.needRedraw > 0 {
-		manager.postRedraw()
+		manager.PostRedraw()
 	}
 	manager.lastUpdateTime = now
 
 	return
 }
 
+// Relayout lays out every widget of every window again, so the next frame
+// does not depend on which widgets asked for a layout pass.
+func (manager *WindowManager) Relayout() *WindowManager {
+	context := manager.getCanvas2D()
+	for _, window := range manager.windows {
+		window.relayoutAll(context)
+	}
+
+	return manager
+}
+
 func (manager *WindowManager) draw() {
 	ctx := manager.getCanvas2D()
 